@@ -3,9 +3,11 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/opentable/sous/graph"
 	sous "github.com/opentable/sous/lib"
@@ -61,6 +63,15 @@ func SuccessYAML(v interface{}) cmdr.Result {
 	return cmdr.SuccessData(b)
 }
 
+// confirm writes question to out, and returns true if the line read from in
+// answers yes.
+func confirm(in *bufio.Reader, out io.Writer, question string) bool {
+	fmt.Fprint(out, question)
+	answer, _ := in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 // Plumbing injects a command with the current psyringe,
 // then it Executes it, returning the result.
 func (cli *CLI) Plumbing(cmd cmdr.Executor, args []string) cmdr.Result {
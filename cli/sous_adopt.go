@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/yaml"
+)
+
+// SousAdopt is the description of the `sous adopt` command
+type SousAdopt struct {
+	*sous.State
+	graph.StateWriter
+	Adopter *singularity.Adopter
+	User    sous.User
+	graph.OutWriter
+	flags struct {
+		cluster, flavor string
+		confirm         bool
+	}
+}
+
+func init() { TopLevelCommands["adopt"] = &SousAdopt{} }
+
+const sousAdoptHelp = `Bring Singularity requests that Sous doesn't manage into the GDM
+
+usage: sous adopt [-cluster <name>] [-flavor <flavor>] [-confirm <request-id>...]
+
+Lists the requests in the clusters' Singularities whose IDs Sous didn't make,
+each with the manifest its current deploy would be recorded in the GDM as.
+The source ID of each is recovered from the labels of the image it deploys,
+so only images built by Sous can be adopted. Give request IDs to list only
+those requests.
+
+With -confirm, each request named is adopted: Sous creates the request it
+would have made for the deployment, deploys the same image to it, adds the
+deployment to the GDM, and then deletes the original request. From then on
+the deployment is managed like any other.
+`
+
+// Help prints the help
+func (*SousAdopt) Help() string { return sousAdoptHelp }
+
+// AddFlags adds the flags for sous adopt.
+func (sa *SousAdopt) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sa.flags.cluster, "cluster", "",
+		"only list requests deployed to this cluster")
+	fs.StringVar(&sa.flags.flavor, "flavor", "",
+		"the flavor of the manifests the requests are adopted into")
+	fs.BoolVar(&sa.flags.confirm, "confirm", false,
+		"adopt the requests named, rather than only listing them")
+}
+
+// RegisterOn adds flag options to the graph.
+func (*SousAdopt) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Execute defines the behavior of `sous adopt`
+func (sa *SousAdopt) Execute(args []string) cmdr.Result {
+	if sa.flags.confirm && len(args) == 0 {
+		return cmdr.UsageErrorf("-confirm needs the IDs of the requests to adopt")
+	}
+	clusters := sa.State.Defs.Clusters
+	if sa.flags.cluster != "" {
+		c, ok := clusters[sa.flags.cluster]
+		if !ok {
+			return cmdr.UsageErrorf("no cluster named %q in defs", sa.flags.cluster)
+		}
+		clusters = sous.Clusters{sa.flags.cluster: c}
+	}
+
+	all, err := sa.Adopter.Unmanaged(clusters)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	ads, err := selectAdoptions(all, args)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	for _, ad := range ads {
+		ad.Deployment.Flavor = sa.flags.flavor
+		if sa.flags.confirm {
+			if err := sa.adopt(ad); err != nil {
+				return EnsureErrorResult(err)
+			}
+			fmt.Fprintf(sa.OutWriter, "adopted %s as %s\n", ad.RequestID, ad.Deployment.ID())
+			continue
+		}
+		if err := sa.propose(ad); err != nil {
+			return EnsureErrorResult(err)
+		}
+	}
+	return cmdr.Success()
+}
+
+// selectAdoptions returns the adoptions of the requests named by ids, or all
+// of them if there are none.
+func selectAdoptions(all []*singularity.Adoption, ids []string) ([]*singularity.Adoption, error) {
+	if len(ids) == 0 {
+		return all, nil
+	}
+	byID := map[string]*singularity.Adoption{}
+	for _, ad := range all {
+		byID[ad.RequestID] = ad
+	}
+	var ads []*singularity.Adoption
+	for _, id := range ids {
+		ad, ok := byID[id]
+		if !ok {
+			return nil, errors.Errorf("%s is not an unmanaged request in any cluster", id)
+		}
+		ads = append(ads, ad)
+	}
+	return ads, nil
+}
+
+// propose writes the manifest ad would be recorded as.
+func (sa *SousAdopt) propose(ad *singularity.Adoption) error {
+	m, err := ad.Manifest(sa.State.Defs)
+	if err != nil {
+		fmt.Fprintf(sa.OutWriter, "# %s can't be adopted: %s\n", ad.RequestID, err)
+		return nil
+	}
+	yml, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sa.OutWriter, "# %s would be adopted as %s\n", ad.RequestID, ad.Deployment.ID())
+	sa.OutWriter.Write(yml)
+	fmt.Fprintln(sa.OutWriter, "---")
+	return nil
+}
+
+func (sa *SousAdopt) adopt(ad *singularity.Adoption) error {
+	m, err := ad.Manifest(sa.State.Defs)
+	if err != nil {
+		return errors.Wrapf(err, "can't adopt %s", ad.RequestID)
+	}
+	cluster := ad.Deployment.ClusterName
+	existing, ok := sa.State.Manifests.Get(m.ID())
+	if ok {
+		if _, deployed := existing.Deployments[cluster]; deployed {
+			return errors.Errorf("can't adopt %s: %v is already deployed to %s by Sous",
+				ad.RequestID, m.ID(), cluster)
+		}
+		existing = existing.Clone()
+		existing.SetExpandedDeployment(cluster, m.Deployments[cluster])
+		m = existing
+	}
+	return sa.Adopter.Adopt(ad, func() error {
+		sa.State.Manifests.Set(m.ID(), m)
+		return sa.StateWriter.WriteState(sa.State, sa.User)
+	})
+}
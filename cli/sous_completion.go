@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+type (
+	// SousCompletion is the description of the `sous completion` command
+	SousCompletion struct {
+		CLI *CLI
+	}
+
+	// SousCompleteValues is the hidden command completion scripts invoke to
+	// list the values a flag might take.
+	SousCompleteValues struct {
+		*sous.State
+		GDM graph.CurrentGDM
+	}
+)
+
+// completeValuesCommand is the name of the hidden SousCompleteValues command.
+const completeValuesCommand = "__complete"
+
+func init() {
+	TopLevelCommands["completion"] = &SousCompletion{}
+	TopLevelCommands[completeValuesCommand] = &SousCompleteValues{}
+}
+
+const sousCompletionHelp = `Generate a shell completion script for sous
+
+usage: sous completion bash|zsh|fish
+
+Writes a script to stdout which completes sous commands and their flags, as
+well as the names of clusters, and the repos, offsets and flavors in the GDM,
+given to flags like -cluster and -repo.
+
+To complete sous in every bash or zsh session, add the line
+
+    source <(sous completion bash)
+
+to ~/.bashrc, or the same with zsh to ~/.zshrc. For fish, run
+
+    sous completion fish > ~/.config/fish/completions/sous.fish
+
+The script lists sous' commands as they were when it was generated, so
+generate it again after upgrading sous.
+`
+
+// Help prints the help
+func (*SousCompletion) Help() string { return sousCompletionHelp }
+
+// Execute defines the behavior of `sous completion`
+func (sc *SousCompletion) Execute(args []string) cmdr.Result {
+	if len(args) != 1 {
+		return cmdr.UsageErrorf("which shell? one of %s", strings.Join(cmdr.CompletionShells, ", "))
+	}
+	script, err := sc.CLI.CompletionScript(args[0], "sous", completeValuesCommand)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	return cmdr.SuccessData([]byte(script))
+}
+
+const sousCompleteValuesHelp = `List the values a flag might take, for completion
+
+usage: sous __complete <flag>
+`
+
+// Help prints the help
+func (*SousCompleteValues) Help() string { return sousCompleteValuesHelp }
+
+// Hidden keeps `sous __complete` out of help and completion.
+func (*SousCompleteValues) Hidden() bool { return true }
+
+// RegisterOn adds flag options to the graph.
+func (*SousCompleteValues) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Execute defines the behavior of `sous __complete`
+func (sc *SousCompleteValues) Execute(args []string) cmdr.Result {
+	if len(args) != 1 {
+		return cmdr.UsageErrorf("usage: sous %s <flag>", completeValuesCommand)
+	}
+	values := completionValues(args[0], sc.State, sc.GDM.Deployments)
+	if len(values) == 0 {
+		return cmdr.Success()
+	}
+	return cmdr.SuccessData([]byte(strings.Join(values, "\n") + "\n"))
+}
+
+// completionValues returns the values in state and gdm, in order, that the
+// flag named might take.
+func completionValues(flag string, state *sous.State, gdm sous.Deployments) []string {
+	set := map[string]struct{}{}
+	switch flag {
+	case "format":
+		for _, f := range []string{cmdr.FormatTable, cmdr.FormatJSON, cmdr.FormatYAML} {
+			set[f] = struct{}{}
+		}
+	case "cluster":
+		for name := range state.Defs.Clusters {
+			set[name] = struct{}{}
+		}
+	case "repo", "offset", "flavor":
+		for _, d := range gdm.Snapshot() {
+			v := map[string]string{
+				"repo":   d.SourceID.Location.Repo,
+				"offset": d.SourceID.Location.Dir,
+				"flavor": d.Flavor,
+			}[flag]
+			if v != "" {
+				set[v] = struct{}{}
+			}
+		}
+	}
+	values := make([]string, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompletionValues(t *testing.T) {
+	state := makeTestState()
+	deps, err := state.Deployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for flag, want := range map[string][]string{
+		"cluster": {"cluster-1", "cluster-2"},
+		"repo":    {project1.Repo},
+		"flavor":  {},
+		"format":  {"json", "table", "yaml"},
+		"tag":     {},
+	} {
+		if got := completionValues(flag, state, deps); !reflect.DeepEqual(got, want) {
+			t.Errorf("-%s: got %q; want %q", flag, got, want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousFlavor is the description of the `sous flavor` command
+type SousFlavor struct{}
+
+// FlavorSubcommands are the subcommands of `sous flavor`
+var FlavorSubcommands = cmdr.Commands{}
+
+func init() { TopLevelCommands["flavor"] = &SousFlavor{} }
+
+const sousFlavorHelp = `list, create and delete the flavors of a source location
+
+A flavor is a manifest of its own for a source location, deployed alongside
+the unflavored manifest, e.g. to run the same code with another configuration.
+The source location is the current repo and offset, or those given by -repo
+and -offset.
+`
+
+// Help prints the help
+func (*SousFlavor) Help() string { return sousFlavorHelp }
+
+// Subcommands returns the subcommands of `sous flavor`
+func (*SousFlavor) Subcommands() cmdr.Commands { return FlavorSubcommands }
+
+// Execute defines the behavior of `sous flavor`
+func (*SousFlavor) Execute(args []string) cmdr.Result {
+	err := cmdr.UsageErrorf("usage: sous flavor [options] <command>")
+	err.Tip = "try `sous help flavor` for a list of commands"
+	return err
+}
+
+// flavorManifests returns the manifests of sl, ordered by flavor.
+func flavorManifests(ms sous.Manifests, sl sous.SourceLocation) []*sous.Manifest {
+	var flavors []*sous.Manifest
+	for mid, m := range ms.Snapshot() {
+		if mid.Source == sl {
+			flavors = append(flavors, m)
+		}
+	}
+	sort.Sort(byFlavor(flavors))
+	return flavors
+}
+
+type byFlavor []*sous.Manifest
+
+func (ms byFlavor) Len() int           { return len(ms) }
+func (ms byFlavor) Swap(i, j int)      { ms[i], ms[j] = ms[j], ms[i] }
+func (ms byFlavor) Less(i, j int) bool { return ms[i].Flavor < ms[j].Flavor }
+
+// flavorArg returns the flavor named in args, which must be the only one.
+func flavorArg(args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", cmdr.UsageErrorf("name one flavor")
+	}
+	return args[0], nil
+}
+
+// selectClusters returns the specs of the clusters named in the
+// comma-separated list, or all of specs if list is empty.
+func selectClusters(specs sous.DeploySpecs, list string) (sous.DeploySpecs, error) {
+	if list == "" {
+		return specs, nil
+	}
+	selected := sous.DeploySpecs{}
+	for _, name := range strings.Split(list, ",") {
+		spec, ok := specs[name]
+		if !ok {
+			return nil, errors.Errorf("no deployment to cluster %q", name)
+		}
+		selected[name] = spec
+	}
+	return selected, nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousFlavorClone is the description of the `sous flavor clone` command
+type SousFlavorClone struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.State
+	graph.StateWriter
+	User  sous.User
+	flags struct {
+		from, clusters string
+	}
+}
+
+func init() { FlavorSubcommands["clone"] = &SousFlavorClone{} }
+
+const sousFlavorCloneHelp = `create a new flavor as a copy of an existing one
+
+usage: sous flavor clone [-repo <repo>] [-offset <offset>] [-from <flavor>] [-clusters <c1,c2...>] <flavor>
+
+Creates a manifest for the new flavor which is a copy of the manifest of the
+flavor given with -from, or of the unflavored manifest. Only the deployments
+to the clusters given with -clusters are copied, if it's given.
+`
+
+// Help prints the help
+func (*SousFlavorClone) Help() string { return sousFlavorCloneHelp }
+
+// AddFlags adds the flags for sous flavor clone.
+func (sfc *SousFlavorClone) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sfc.DeployFilterFlags, ManifestFilterFlagsHelp)
+	fs.StringVar(&sfc.flags.from, "from", "",
+		"the flavor to copy (default: the unflavored manifest)")
+	fs.StringVar(&sfc.flags.clusters, "clusters", "",
+		"comma-separated clusters whose deployments to copy (default: all)")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sfc *SousFlavorClone) RegisterOn(psy Addable) {
+	psy.Add(&sfc.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous flavor clone`
+func (sfc *SousFlavorClone) Execute(args []string) cmdr.Result {
+	flavor, err := flavorArg(args)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	from := sous.ManifestID{Source: sfc.TargetManifestID.Source, Flavor: sfc.flags.from}
+	original, ok := sfc.State.Manifests.Get(from)
+	if !ok {
+		return EnsureErrorResult(errors.Errorf("no manifest %v to clone", from))
+	}
+	m := original.Clone()
+	m.Flavor = flavor
+	if m.Deployments, err = selectClusters(m.Deployments, sfc.flags.clusters); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	if err := addFlavor(sfc.State, m); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := sfc.StateWriter.WriteState(sfc.State, sfc.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return SuccessYAML(m)
+}
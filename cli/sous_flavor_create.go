@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousFlavorCreate is the description of the `sous flavor create` command
+type SousFlavorCreate struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.State
+	graph.StateWriter
+	User  sous.User
+	flags struct {
+		clusters string
+	}
+}
+
+func init() { FlavorSubcommands["create"] = &SousFlavorCreate{} }
+
+const sousFlavorCreateHelp = `create a new flavor of a source location
+
+usage: sous flavor create [-repo <repo>] [-offset <offset>] [-clusters <c1,c2...>] <flavor>
+
+Creates a manifest for the flavor, with the owners and kind of the unflavored
+manifest, and a deployment of one instance with default resources to each of
+the clusters given with -clusters, or to every cluster. Its deployments have
+no version until one is deployed with 'sous deploy -flavor <flavor>'.
+
+To start from the deployments of an existing flavor, use 'sous flavor clone'.
+`
+
+// Help prints the help
+func (*SousFlavorCreate) Help() string { return sousFlavorCreateHelp }
+
+// AddFlags adds the flags for sous flavor create.
+func (sfc *SousFlavorCreate) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sfc.DeployFilterFlags, ManifestFilterFlagsHelp)
+	fs.StringVar(&sfc.flags.clusters, "clusters", "",
+		"comma-separated clusters to deploy the flavor to (default: all)")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sfc *SousFlavorCreate) RegisterOn(psy Addable) {
+	psy.Add(&sfc.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous flavor create`
+func (sfc *SousFlavorCreate) Execute(args []string) cmdr.Result {
+	flavor, err := flavorArg(args)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	mid := sous.ManifestID{Source: sfc.TargetManifestID.Source, Flavor: flavor}
+	m := &sous.Manifest{Kind: sous.ManifestKindService, Deployments: sous.DeploySpecs{}}
+	if base, ok := sfc.State.Manifests.Get(sous.ManifestID{Source: mid.Source}); ok {
+		m.Kind = base.Kind
+		m.Owners = append([]string{}, base.Owners...)
+	}
+	m.SetID(mid)
+
+	all := sous.DeploySpecs{}
+	for name := range sfc.State.Defs.Clusters {
+		all[name] = sous.DeploySpec{DeployConfig: sous.DeployConfig{
+			Resources:    sous.Resources{},
+			Env:          sous.Env{},
+			NumInstances: 1,
+		}}
+	}
+	if m.Deployments, err = selectClusters(all, sfc.flags.clusters); err != nil {
+		return EnsureErrorResult(err)
+	}
+	sous.RepairAll(m.Validate())
+
+	if err := addFlavor(sfc.State, m); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := sfc.StateWriter.WriteState(sfc.State, sfc.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return SuccessYAML(m)
+}
+
+// addFlavor adds m to state, unless its flavor already exists.
+func addFlavor(state *sous.State, m *sous.Manifest) error {
+	if ok := state.Manifests.Add(m); !ok {
+		return errors.Errorf("flavor %q of %v already exists", m.Flavor, m.Source)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousFlavorDelete is the description of the `sous flavor delete` command
+type SousFlavorDelete struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.State
+	graph.StateWriter
+	graph.OutWriter
+	User  sous.User
+	flags struct {
+		confirm bool
+	}
+}
+
+func init() { FlavorSubcommands["delete"] = &SousFlavorDelete{} }
+
+const sousFlavorDeleteHelp = `delete a flavor of a source location
+
+usage: sous flavor delete [-repo <repo>] [-offset <offset>] [-confirm] <flavor>
+
+Lists the Singularity requests of the flavor's deployments, which are removed
+when the flavor is deleted. With -confirm, the flavor's manifest is deleted
+from the GDM, and the requests are removed by the next rectification.
+`
+
+// Help prints the help
+func (*SousFlavorDelete) Help() string { return sousFlavorDeleteHelp }
+
+// AddFlags adds the flags for sous flavor delete.
+func (sfd *SousFlavorDelete) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sfd.DeployFilterFlags, ManifestFilterFlagsHelp)
+	fs.BoolVar(&sfd.flags.confirm, "confirm", false,
+		"delete the flavor, rather than only listing what would be removed")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sfd *SousFlavorDelete) RegisterOn(psy Addable) {
+	psy.Add(&sfd.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous flavor delete`
+func (sfd *SousFlavorDelete) Execute(args []string) cmdr.Result {
+	flavor, err := flavorArg(args)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	mid := sous.ManifestID{Source: sfd.TargetManifestID.Source, Flavor: flavor}
+	m, ok := sfd.State.Manifests.Get(mid)
+	if !ok {
+		return EnsureErrorResult(errors.Errorf("no flavor %q of %v", flavor, mid.Source))
+	}
+
+	verb := "would remove"
+	if sfd.flags.confirm {
+		verb = "removes"
+	}
+	fmt.Fprintf(sfd.OutWriter, "Deleting %v %s these Singularity requests:\n", mid, verb)
+	for _, r := range flavorRequests(sfd.State.Defs, m) {
+		fmt.Fprintf(sfd.OutWriter, "  %s\n", r)
+	}
+	if !sfd.flags.confirm {
+		fmt.Fprintln(sfd.OutWriter, "Use -confirm to delete it.")
+		return cmdr.Success()
+	}
+
+	sfd.State.Manifests.Remove(mid)
+	if err := sfd.StateWriter.WriteState(sfd.State, sfd.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	fmt.Fprintf(sfd.OutWriter, "Deleted %v.\n", mid)
+	return cmdr.Success()
+}
+
+// flavorRequests describes the Singularity request of each of m's
+// deployments, ordered by cluster name.
+func flavorRequests(defs sous.Defs, m *sous.Manifest) []string {
+	var clusters []string
+	for name := range m.Deployments {
+		clusters = append(clusters, name)
+	}
+	sort.Strings(clusters)
+	var reqs []string
+	for _, name := range clusters {
+		reqID := singularity.MakeRequestID(sous.DeployID{ManifestID: m.ID(), Cluster: name})
+		url := "unknown cluster"
+		if c, ok := defs.Clusters[name]; ok {
+			url = c.BaseURL
+		}
+		reqs = append(reqs, fmt.Sprintf("%s (%s)", reqID, url))
+	}
+	return reqs
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousFlavorList is the description of the `sous flavor list` command
+type SousFlavorList struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.State
+	cmdr.Formatter
+}
+
+// A flavorRecord is the output form of a flavor.
+type flavorRecord struct {
+	Flavor   string
+	Clusters []string
+}
+
+func init() { FlavorSubcommands["list"] = &SousFlavorList{} }
+
+const sousFlavorListHelp = `list the flavors of a source location
+
+usage: sous flavor list [-repo <repo>] [-offset <offset>]
+
+Lists each flavor with the clusters it's deployed to. The unflavored
+manifest, if there is one, is listed with an empty flavor.
+`
+
+// Help prints the help
+func (*SousFlavorList) Help() string { return sousFlavorListHelp }
+
+// AddFlags adds the flags for sous flavor list.
+func (sfl *SousFlavorList) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sfl.DeployFilterFlags, ManifestFilterFlagsHelp)
+	sfl.AddFormatFlag(fs)
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sfl *SousFlavorList) RegisterOn(psy Addable) {
+	psy.Add(&sfl.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous flavor list`
+func (sfl *SousFlavorList) Execute(args []string) cmdr.Result {
+	records := []flavorRecord{}
+	for _, m := range flavorManifests(sfl.State.Manifests, sfl.TargetManifestID.Source) {
+		r := flavorRecord{Flavor: m.Flavor, Clusters: []string{}}
+		for name := range m.Deployments {
+			r.Clusters = append(r.Clusters, name)
+		}
+		sort.Strings(r.Clusters)
+		records = append(records, r)
+	}
+	return sfl.Result(records, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FLAVOR\tCLUSTERS")
+		for _, r := range records {
+			fmt.Fprintf(tw, "%s\t%s\n", r.Flavor, strings.Join(r.Clusters, ","))
+		}
+		return tw.Flush()
+	})
+}
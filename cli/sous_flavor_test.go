@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+func TestFlavorCommands(t *testing.T) {
+	state := makeTestState()
+	dummyWriter := &sous.DummyStateManager{State: state}
+	writer := graph.StateWriter{StateWriter: dummyWriter}
+	target := graph.TargetManifestID{Source: project1}
+
+	clone := &SousFlavorClone{TargetManifestID: target, State: state, StateWriter: writer}
+	clone.flags.clusters = "cluster-2"
+	res := clone.Execute([]string{"canary"})
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	canary, ok := state.Manifests.Get(sous.ManifestID{Source: project1, Flavor: "canary"})
+	require.True(t, ok)
+	assert.Len(t, canary.Deployments, 1)
+	assert.Equal(t, 3, canary.Deployments["cluster-2"].NumInstances)
+	assert.NotEqual(t, 0, clone.Execute([]string{"canary"}).ExitCode(), "cloned onto an existing flavor")
+
+	create := &SousFlavorCreate{TargetManifestID: target, State: state, StateWriter: writer}
+	res = create.Execute([]string{"batch"})
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	batch, ok := state.Manifests.Get(sous.ManifestID{Source: project1, Flavor: "batch"})
+	require.True(t, ok)
+	assert.Equal(t, []string{"owner1"}, batch.Owners)
+	assert.Len(t, batch.Deployments, 2)
+	assert.Empty(t, batch.Validate())
+
+	list := &SousFlavorList{TargetManifestID: target, State: state}
+	res = list.Execute(nil)
+	require.Equal(t, 0, res.ExitCode())
+	out := res.(cmdr.SuccessResult).String()
+	assert.Regexp(t, `(?m)^batch\s+cluster-1,cluster-2$`, out)
+	assert.Regexp(t, `(?m)^canary\s+cluster-2$`, out)
+
+	buf := &bytes.Buffer{}
+	del := &SousFlavorDelete{TargetManifestID: target, State: state, StateWriter: writer, OutWriter: buf}
+	require.Equal(t, 0, del.Execute([]string{"canary"}).ExitCode())
+	assert.Contains(t, buf.String(), "github.com>user>project:canary:cluster-2 (http://nothing.here.two)")
+	_, ok = state.Manifests.Get(canary.ID())
+	assert.True(t, ok, "deleted without -confirm")
+
+	del.flags.confirm = true
+	require.Equal(t, 0, del.Execute([]string{"canary"}).ExitCode())
+	_, ok = state.Manifests.Get(canary.ID())
+	assert.False(t, ok, "not deleted with -confirm")
+	assert.Equal(t, 3, dummyWriter.WriteCount)
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousGC is the description of the `sous gc` command
+type SousGC struct {
+	GDM              graph.CurrentGDM
+	GarbageCollector *docker.GarbageCollector
+	Harvester        *docker.Harvester
+	graph.OutWriter
+	flags struct {
+		retain  int
+		delete  bool
+		harvest bool
+	}
+}
+
+func init() { TopLevelCommands["gc"] = &SousGC{} }
+
+const sousGCHelp = `Remove images that no deployment needs from the Docker registry
+
+usage: sous gc [-delete] [-retain <n>] [-harvest]
+
+For each deployment in the GDM, sous gc retains the image deployed, the images
+of newer versions, and the images of the <n> versions before the one
+deployed. Every other image known to the name cache is removed from the
+registry and the name cache. Images of source locations which aren't
+deployed at all are removed.
+
+The name cache used is the one configured by Docker.DatabaseDriver and
+Docker.DatabaseConnection, which is usually the Sous server's. Use -harvest
+to first harvest the whole registry catalog into the name cache.
+
+By default, sous gc only reports the images it would remove.
+`
+
+// Help prints the help
+func (*SousGC) Help() string { return sousGCHelp }
+
+// AddFlags adds the flags for sous gc.
+func (sg *SousGC) AddFlags(fs *flag.FlagSet) {
+	fs.IntVar(&sg.flags.retain, "retain", -1,
+		"the number of older versions to retain for each deployment "+
+			"(default Docker.GCRetainVersions)")
+	fs.BoolVar(&sg.flags.delete, "delete", false,
+		"remove the images, rather than only reporting them")
+	fs.BoolVar(&sg.flags.harvest, "harvest", false,
+		"harvest the registry catalog into the name cache first")
+}
+
+// RegisterOn adds flag options to the graph.
+func (*SousGC) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Execute defines the behavior of `sous gc`
+func (sg *SousGC) Execute(args []string) cmdr.Result {
+	if sg.flags.harvest {
+		if err := sg.Harvester.Harvest(); err != nil {
+			return EnsureErrorResult(err)
+		}
+	}
+	if sg.flags.retain >= 0 {
+		sg.GarbageCollector.Retain = sg.flags.retain
+	}
+	report, err := sg.GarbageCollector.Collect(sg.GDM.Deployments, !sg.flags.delete)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	report.AsTable(sg.OutWriter)
+	return cmdr.Success()
+}
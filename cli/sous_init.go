@@ -18,19 +18,33 @@ type SousInit struct {
 	GDM               graph.CurrentGDM
 	State             *sous.State
 	StateWriter       graph.StateWriter
+	InReader          graph.InReader
+	OutWriter         graph.OutWriter
 	User              sous.User
+	flags             struct {
+		interactive bool
+		answers     string
+	}
 }
 
 func init() { TopLevelCommands["init"] = &SousInit{} }
 
 const sousInitHelp = `initialise a new sous project
 
-usage: sous init
+usage: sous init [-interactive | -answers <file>]
 
 Sous init uses contextual information from your current source code tree and
 repository to generate a basic configuration for that project. You will need to
 flesh out some additional details.
 
+With -interactive, it asks for the manifest's kind, owners and clusters, and
+the resources and env defined for the organisation, checking each answer, then
+shows the manifest and saves it once you confirm. The answers can instead be
+given in a YAML file with -answers, for scripts: the manifest is saved without
+asking, if every answer is valid. The file's fields are kind, owners,
+clusters, resources and env; any left out take the defaults that would have
+been offered.
+
 init must be invoked in a git repository that has either an 'upstream' or 
 'origin' remote configured.
 
@@ -51,6 +65,8 @@ func (si *SousInit) AddFlags(fs *flag.FlagSet) {
 	MustAddFlags(fs, &si.Flags, OtplFlagsHelp)
 	fs.StringVar(&si.DeployFilterFlags.Flavor, "flavor", "", flavorFlagHelp)
 	fs.StringVar(&si.DeployFilterFlags.Cluster, "cluster", "", clusterFlagHelp)
+	fs.BoolVar(&si.flags.interactive, "interactive", false, "ask for the manifest's settings")
+	fs.StringVar(&si.flags.answers, "answers", "", "a YAML file of answers to the questions -interactive asks")
 }
 
 // Execute fulfills the cmdr.Executor interface
@@ -64,6 +80,10 @@ func (si *SousInit) Execute(args []string) cmdr.Result {
 
 	m := si.Target.Manifest
 
+	if si.flags.interactive || si.flags.answers != "" {
+		return si.executeGuided(m, cluster)
+	}
+
 	if cluster != "" {
 		m.Deployments = sous.DeploySpecs{cluster: m.Deployments[cluster]}
 	}
@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/yaml"
+)
+
+type (
+	// initAnswers are the answers to the questions of sous init -interactive,
+	// which can also be given in a file with -answers.
+	initAnswers struct {
+		Kind      string
+		Owners    []string
+		Clusters  []string
+		Resources map[string]string
+		Env       map[string]string
+	}
+
+	// initQuestion is one of the questions of sous init -interactive.
+	initQuestion struct {
+		prompt, def string
+		// check returns an error if answer isn't a valid answer.
+		check func(answer string) error
+		// get and set read and record the question's answer in initAnswers.
+		get func(*initAnswers) string
+		set func(*initAnswers, string)
+	}
+)
+
+// executeGuided makes m from the answers asked for with -interactive, or
+// given in the file named by -answers. Answers default to the settings m has
+// already, and the cluster selected with -cluster.
+func (si *SousInit) executeGuided(m *sous.Manifest, cluster string) cmdr.Result {
+	if si.flags.interactive && si.flags.answers != "" {
+		return cmdr.UsageErrorf("-interactive and -answers can't be used together")
+	}
+	if _, exists := si.State.Manifests.Get(m.ID()); exists {
+		return cmdr.UsageErrorf("manifest %q already exists", m.ID())
+	}
+
+	questions := initQuestions(si.State.Defs, m, cluster, si.User)
+	in := bufio.NewReader(si.InReader)
+	var answers *initAnswers
+	var err error
+	if si.flags.interactive {
+		answers, err = askInitQuestions(questions, in, si.OutWriter)
+	} else {
+		answers, err = readInitAnswers(si.flags.answers, questions, si.State.Defs)
+	}
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	applyInitAnswers(m, answers)
+	if flaws := m.Validate(); len(flaws) != 0 {
+		return EnsureErrorResult(errors.Errorf("manifest %v is invalid: %v", m.ID(), flaws))
+	}
+
+	if si.flags.interactive {
+		yml, err := yaml.Marshal(m)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		fmt.Fprintf(si.OutWriter, "\n%s\n", yml)
+		if !confirm(in, si.OutWriter, "Save this manifest? [y/N] ") {
+			fmt.Fprintln(si.OutWriter, "Not saved.")
+			return cmdr.Success()
+		}
+	}
+
+	if ok := si.State.Manifests.Add(m); !ok {
+		return cmdr.UsageErrorf("manifest %q already exists", m.ID())
+	}
+	if err := si.StateWriter.WriteState(si.State, si.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if si.flags.interactive {
+		fmt.Fprintf(si.OutWriter, "Saved %v.\n", m.ID())
+		return cmdr.Success()
+	}
+	return SuccessYAML(m)
+}
+
+// initQuestions lists the questions sous init -interactive asks to make m:
+// its kind, owners and clusters, then the value of each resource and env var
+// defined in defs. The env vars set for each cluster aren't asked for.
+func initQuestions(defs sous.Defs, m *sous.Manifest, cluster string, user sous.User) []initQuestion {
+	kind := string(m.Kind)
+	if kind == "" {
+		kind = string(sous.ManifestKindService)
+	}
+	owners := m.Owners
+	if len(owners) == 0 && user.Email != "" {
+		owners = []string{user.Email}
+	}
+	clusters := []string{cluster}
+	if cluster == "" {
+		clusters = sortedClusterNames(defs.Clusters)
+	}
+
+	questions := []initQuestion{
+		{
+			prompt: "Kind",
+			def:    kind,
+			check:  checkKind,
+			get:    func(a *initAnswers) string { return a.Kind },
+			set:    func(a *initAnswers, v string) { a.Kind = v },
+		},
+		{
+			prompt: "Owners (comma separated)",
+			def:    strings.Join(owners, ", "),
+			check: func(v string) error {
+				if len(splitList(v)) == 0 {
+					return errors.Errorf("at least one owner is required")
+				}
+				return nil
+			},
+			get: func(a *initAnswers) string { return strings.Join(a.Owners, ", ") },
+			set: func(a *initAnswers, v string) { a.Owners = splitList(v) },
+		},
+		{
+			prompt: fmt.Sprintf("Clusters (comma separated, of %s)", strings.Join(sortedClusterNames(defs.Clusters), ", ")),
+			def:    strings.Join(clusters, ", "),
+			check:  func(v string) error { return checkClusters(defs.Clusters, splitList(v)) },
+			get:    func(a *initAnswers) string { return strings.Join(a.Clusters, ", ") },
+			set:    func(a *initAnswers, v string) { a.Clusters = splitList(v) },
+		},
+	}
+
+	for _, fd := range defs.Resources {
+		fd := fd
+		def := fd.Default
+		if v, ok := specValue(m, func(spec sous.DeploySpec) string { return spec.Resources[fd.Name] }); ok {
+			def = v
+		}
+		questions = append(questions, initQuestion{
+			prompt: describedPrompt("Resource "+fd.Name, string(fd.Type)),
+			def:    def,
+			check:  fd.Check,
+			get:    func(a *initAnswers) string { return a.Resources[fd.Name] },
+			set:    func(a *initAnswers, v string) { a.Resources[fd.Name] = v },
+		})
+	}
+
+	for _, ed := range defs.EnvVars {
+		if ed.Scope == "cluster" {
+			continue
+		}
+		ed := ed
+		def, _ := specValue(m, func(spec sous.DeploySpec) string { return spec.Env[ed.Name] })
+		questions = append(questions, initQuestion{
+			prompt: describedPrompt("Env "+ed.Name, ed.Desc) + " (optional)",
+			def:    def,
+			check: func(v string) error {
+				if v == "" {
+					return nil
+				}
+				return errors.Wrap(ed.Type.Check(v), ed.Name)
+			},
+			get: func(a *initAnswers) string { return a.Env[ed.Name] },
+			set: func(a *initAnswers, v string) {
+				if v != "" {
+					a.Env[ed.Name] = v
+				}
+			},
+		})
+	}
+	return questions
+}
+
+// askInitQuestions asks each question in turn, until it's given a valid
+// answer. An empty answer takes the question's default.
+func askInitQuestions(questions []initQuestion, in *bufio.Reader, out io.Writer) (*initAnswers, error) {
+	answers := newInitAnswers()
+	for _, q := range questions {
+		prompt := q.prompt + ": "
+		if q.def != "" {
+			prompt = fmt.Sprintf("%s [%s]: ", q.prompt, q.def)
+		}
+		for {
+			fmt.Fprint(out, prompt)
+			answer, err := in.ReadString('\n')
+			if err != nil && (err != io.EOF || answer == "") {
+				fmt.Fprintln(out)
+				return nil, errors.Errorf("no answer given to %q", q.prompt)
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				answer = q.def
+			}
+			if err := q.check(answer); err != nil {
+				fmt.Fprintf(out, "  %s\n", err)
+				continue
+			}
+			q.set(answers, answer)
+			break
+		}
+	}
+	return answers, nil
+}
+
+// readInitAnswers reads the answers in the YAML file at path, gives each
+// question left unanswered its default, and returns an error listing the
+// answers that aren't valid.
+func readInitAnswers(path string, questions []initQuestion, defs sous.Defs) (*initAnswers, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading answers")
+	}
+	given := newInitAnswers()
+	if err := yaml.Unmarshal(body, given); err != nil {
+		return nil, errors.Wrapf(err, "parsing answers in %s", path)
+	}
+
+	var problems []string
+	for name := range given.Resources {
+		if !definesResource(defs, name) {
+			problems = append(problems, fmt.Sprintf("no resource named %q in defs", name))
+		}
+	}
+	for name := range given.Env {
+		if !definesEnv(defs, name) {
+			problems = append(problems, fmt.Sprintf("no env var named %q in defs", name))
+		}
+	}
+	sort.Strings(problems)
+
+	answers := newInitAnswers()
+	for _, q := range questions {
+		answer := q.get(given)
+		if answer == "" {
+			answer = q.def
+		}
+		if err := q.check(answer); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		q.set(answers, answer)
+	}
+	if len(problems) != 0 {
+		return nil, errors.Errorf("invalid answers in %s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return answers, nil
+}
+
+// applyInitAnswers makes m of the kind, with the owners, and deployed to the
+// clusters answered, each with the resources and env answered.
+func applyInitAnswers(m *sous.Manifest, a *initAnswers) {
+	m.Kind = sous.ManifestKind(a.Kind)
+	m.Owners = a.Owners
+	specs := sous.DeploySpecs{}
+	for _, name := range a.Clusters {
+		spec := m.Deployments[name]
+		spec.Resources = spec.Resources.Clone()
+		for k, v := range a.Resources {
+			if v != "" {
+				spec.Resources[k] = v
+			}
+		}
+		env := sous.Env{}
+		for k, v := range spec.Env {
+			env[k] = v
+		}
+		for k, v := range a.Env {
+			env[k] = v
+		}
+		spec.Env = env
+		if spec.NumInstances == 0 {
+			spec.NumInstances = 1
+		}
+		specs[name] = spec
+	}
+	m.Deployments = specs
+}
+
+func newInitAnswers() *initAnswers {
+	return &initAnswers{Resources: map[string]string{}, Env: map[string]string{}}
+}
+
+// specValue returns the value get finds in the first of m's deployments, in
+// order of cluster name, that has one.
+func specValue(m *sous.Manifest, get func(sous.DeploySpec) string) (string, bool) {
+	var clusters []string
+	for name := range m.Deployments {
+		clusters = append(clusters, name)
+	}
+	sort.Strings(clusters)
+	for _, name := range clusters {
+		if v := get(m.Deployments[name]); v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func checkKind(kind string) error {
+	if len(sous.ManifestKind(kind).Validate()) == 0 {
+		return nil
+	}
+	return errors.Errorf("%q isn't a kind of manifest: pick one of %s", kind, strings.Join([]string{
+		string(sous.ManifestKindService), sous.ManifestKindWorker, sous.ManifestKindOnDemand,
+		sous.ManifestKindScheduled, sous.ManifestKindOnce, sous.ScheduledJob,
+	}, ", "))
+}
+
+func checkClusters(defined sous.Clusters, names []string) error {
+	if len(names) == 0 {
+		return errors.Errorf("at least one cluster is required")
+	}
+	for _, name := range names {
+		if _, ok := defined[name]; !ok {
+			return errors.Errorf("cluster %q not defined, pick from: %s", name, strings.Join(sortedClusterNames(defined), ", "))
+		}
+	}
+	return nil
+}
+
+func definesResource(defs sous.Defs, name string) bool {
+	for _, fd := range defs.Resources {
+		if fd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func definesEnv(defs sous.Defs, name string) bool {
+	for _, ed := range defs.EnvVars {
+		if ed.Name == name && ed.Scope != "cluster" {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedClusterNames(clusters sous.Clusters) []string {
+	names := []string{}
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// describedPrompt adds desc, if there is one, to prompt.
+func describedPrompt(prompt, desc string) string {
+	if desc == "" {
+		return prompt
+	}
+	return fmt.Sprintf("%s (%s)", prompt, desc)
+}
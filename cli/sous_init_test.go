@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+func guidedInitFixture() (*SousInit, *sous.DummyStateManager, *bytes.Buffer) {
+	state := makeTestState()
+	state.Defs.Resources = sous.FieldDefinitions{
+		{Name: "cpus", Type: "Float"},
+		{Name: "memory", Type: "MemorySize", Default: "100"},
+		{Name: "ports", Type: "Integer", Default: "1"},
+	}
+	state.Defs.EnvVars = sous.EnvDefs{
+		{Name: "CLUSTER_LONG_NAME", Scope: "cluster"},
+		{Name: "WORKERS", Desc: "worker threads", Type: "int"},
+	}
+	dummyWriter := &sous.DummyStateManager{State: state}
+	m := &sous.Manifest{
+		Source: sous.SourceLocation{Repo: "github.com/user/new"},
+		Deployments: sous.DeploySpecs{
+			"cluster-1": {DeployConfig: sous.DeployConfig{Resources: sous.Resources{"ports": "2"}, NumInstances: 1}},
+			"cluster-2": {DeployConfig: sous.DeployConfig{Resources: sous.Resources{"ports": "2"}, NumInstances: 1}},
+		},
+	}
+	out := &bytes.Buffer{}
+	si := &SousInit{
+		Target:      graph.TargetManifest{Manifest: m},
+		State:       state,
+		StateWriter: graph.StateWriter{StateWriter: dummyWriter},
+		OutWriter:   out,
+		User:        sous.User{Email: "dev@example.com"},
+	}
+	return si, dummyWriter, out
+}
+
+func TestInitInteractive(t *testing.T) {
+	si, dummyWriter, out := guidedInitFixture()
+	si.flags.interactive = true
+	si.DeployFilterFlags.Cluster = "cluster-2"
+	si.InReader = strings.NewReader(strings.Join([]string{
+		"job",    // not a kind
+		"worker", // kind
+		"",       // owners: the user
+		"",       // clusters: -cluster
+		"",       // cpus has no default
+		"lots",   // nor is this a float
+		"0.5",    // cpus
+		"",       // memory: its default
+		"",       // ports: the spec's
+		"4",      // WORKERS
+		"y",
+	}, "\n") + "\n")
+
+	res := si.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v\n%s", res, out)
+	assert.Contains(t, out.String(), `"job" isn't a kind of manifest`)
+	assert.Contains(t, out.String(), "cpus is required")
+	assert.Contains(t, out.String(), `cpus: "lots" isn't a Float`)
+	assert.Contains(t, out.String(), "Resource ports (Integer) [2]: ")
+	assert.Contains(t, out.String(), "Env WORKERS (worker threads) (optional): ")
+	assert.NotContains(t, out.String(), "CLUSTER_LONG_NAME")
+	assert.Contains(t, out.String(), "Saved github.com/user/new.")
+	assert.Equal(t, 1, dummyWriter.WriteCount)
+
+	m, ok := si.State.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/new"}})
+	require.True(t, ok)
+	assert.Equal(t, sous.ManifestKind(sous.ManifestKindWorker), m.Kind)
+	assert.Equal(t, []string{"dev@example.com"}, m.Owners)
+	require.Len(t, m.Deployments, 1)
+	spec := m.Deployments["cluster-2"]
+	assert.Equal(t, sous.Resources{"cpus": "0.5", "memory": "100", "ports": "2"}, spec.Resources)
+	assert.Equal(t, "4", spec.Env["WORKERS"])
+
+	si, dummyWriter, out = guidedInitFixture()
+	si.flags.interactive = true
+	si.InReader = strings.NewReader("\n\n\n0.5\n\n\n\nn\n")
+	res = si.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v\n%s", res, out)
+	assert.Contains(t, out.String(), "Not saved.")
+	assert.Equal(t, 0, dummyWriter.WriteCount)
+}
+
+func TestInitAnswers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-init")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "answers.yaml")
+
+	si, dummyWriter, _ := guidedInitFixture()
+	si.flags.answers = path
+	require.NoError(t, ioutil.WriteFile(path, []byte("kind: nonsense\nresources:\n  disk: 10\nenv:\n  WORKERS: many\n"), 0600))
+	res := si.Execute(nil)
+	require.NotEqual(t, 0, res.ExitCode())
+	for _, problem := range []string{`no resource named "disk"`, `"nonsense" isn't a kind`, "cpus is required", `WORKERS: "many" isn't a int`} {
+		assert.Contains(t, fmt.Sprint(res), problem)
+	}
+	assert.Equal(t, 0, dummyWriter.WriteCount)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("owners: [a@example.com, b@example.com]\nresources:\n  cpus: 2\n"), 0600))
+	res = si.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	assert.Equal(t, 1, dummyWriter.WriteCount)
+	m, ok := si.State.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/new"}})
+	require.True(t, ok)
+	assert.Equal(t, sous.ManifestKind(sous.ManifestKindService), m.Kind)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, m.Owners)
+	assert.Len(t, m.Deployments, 2)
+	assert.Equal(t, "2", m.Deployments["cluster-1"].Resources["cpus"])
+
+	si.flags.interactive = true
+	assert.NotEqual(t, 0, si.Execute(nil).ExitCode(), "-interactive with -answers")
+}
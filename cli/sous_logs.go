@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousLogs is the description of the `sous logs` command
+type SousLogs struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.ResolveFilter
+	*sous.State
+	graph.OutWriter
+	HTTPClient graph.HTTPClient
+	TaskReader *singularity.TaskReader
+	User       sous.User
+	// sleep waits between reads when following a log. It defaults to
+	// time.Sleep.
+	sleep func(time.Duration)
+	flags struct {
+		task           string
+		stderr, follow bool
+	}
+}
+
+func init() { TopLevelCommands["logs"] = &SousLogs{} }
+
+const sousLogsHelp = `print the output of a deployment's Singularity task
+
+usage: sous logs -cluster <cluster> [-repo <repo>] [-offset <offset>] [-flavor <flavor>] [-task <task>] [-stderr] [-follow]
+
+Prints the stdout, or with -stderr the stderr, of one of the tasks of the
+deployment in the cluster: the one given with -task, as listed by 'sous
+tasks', or otherwise the newest. With -follow, it keeps printing output as the
+task writes it, until the task finishes.
+`
+
+// logsPollInterval is how long sous logs -follow waits for more output after
+// reaching the end of a log.
+const logsPollInterval = 2 * time.Second
+
+// Help prints the help
+func (*SousLogs) Help() string { return sousLogsHelp }
+
+// AddFlags adds the flags for sous logs.
+func (sl *SousLogs) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sl.DeployFilterFlags, MetadataFilterFlagsHelp)
+	fs.StringVar(&sl.flags.task, "task", "", "the ID of the task, as listed by sous tasks")
+	fs.BoolVar(&sl.flags.stderr, "stderr", false, "print stderr rather than stdout")
+	fs.BoolVar(&sl.flags.follow, "follow", false, "keep printing output as it's written")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sl *SousLogs) RegisterOn(psy Addable) {
+	psy.Add(&sl.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous logs`
+func (sl *SousLogs) Execute(args []string) cmdr.Result {
+	did, err := taskDeployID(sl.TargetManifestID, sl.ResolveFilter)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	ts := taskSource{client: sl.HTTPClient, reader: sl.TaskReader, clusters: sl.State.Defs.Clusters, user: sl.User}
+
+	taskID := sl.flags.task
+	if taskID == "" {
+		tasks, err := ts.tasks(did, 1)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		if len(tasks) == 0 {
+			return EnsureErrorResult(errors.Errorf("%v has no tasks", did))
+		}
+		taskID = newestTask(tasks).ID
+	}
+	file := "stdout"
+	if sl.flags.stderr {
+		file = "stderr"
+	}
+	sleep := sl.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	var position int64
+	finished := false
+	for {
+		chunk, err := ts.log(did, taskID, file, position)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		fmt.Fprint(sl.OutWriter, chunk.Data)
+		if chunk.Data != "" {
+			position = chunk.NextOffset
+			continue
+		}
+		if !sl.flags.follow || finished {
+			return cmdr.Success()
+		}
+		active, err := taskActive(ts, did, taskID)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		if !active {
+			// Read once more, for anything written before it finished.
+			finished = true
+			continue
+		}
+		sleep(logsPollInterval)
+	}
+}
+
+// taskActive returns true if the task taskID of did hasn't finished.
+func taskActive(ts taskSource, did sous.DeployID, taskID string) (bool, error) {
+	tasks, err := ts.tasks(did, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tasks {
+		if t.ID == taskID {
+			return t.Active, nil
+		}
+	}
+	return false, nil
+}
+
+// newestTask returns the task started most recently.
+func newestTask(tasks []singularity.Task) singularity.Task {
+	newest := tasks[0]
+	for _, t := range tasks[1:] {
+		if t.Started.After(newest.Started) {
+			newest = t
+		}
+	}
+	return newest
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/yaml"
+)
+
+// SousManifestEdit is the description of the `sous manifest edit` command
+type SousManifestEdit struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.State
+	graph.StateWriter
+	graph.InReader
+	graph.OutWriter
+	*sous.ResolveFilter
+	User sous.User
+	// edit lets the user edit the file at path, returning once it's saved.
+	// It defaults to runEditor.
+	edit  func(path string) error
+	flags struct {
+		yes bool
+	}
+}
+
+func init() { ManifestSubcommands["edit"] = &SousManifestEdit{} }
+
+const sousManifestEditHelp = `edit a deployment manifest in your editor
+
+usage: sous manifest edit [-repo <repo>] [-offset <offset>] [-flavor <flavor>] [-yes]
+
+Opens $VISUAL, or $EDITOR, or vi, on the current manifest as YAML. When you
+save it and quit, the manifest is validated; if it has flaws, the editor is
+opened again with the flaws listed in comments at the top. Saving it
+unchanged with flaws gives up, as does saving an empty file.
+
+Once the manifest is valid, the differences between it and the current
+manifest are shown, and it's saved once you confirm them (or straight away,
+with -yes). If the manifest on the server has changed since it was opened,
+it isn't saved.
+`
+
+// Help prints the help
+func (*SousManifestEdit) Help() string { return sousManifestEditHelp }
+
+// AddFlags adds the flags for sous manifest edit.
+func (sme *SousManifestEdit) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sme.DeployFilterFlags, ManifestFilterFlagsHelp)
+	fs.BoolVar(&sme.flags.yes, "yes", false, "save the changes without asking")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sme *SousManifestEdit) RegisterOn(psy Addable) {
+	psy.Add(&sme.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous manifest edit`
+func (sme *SousManifestEdit) Execute(args []string) cmdr.Result {
+	mid := sous.ManifestID(sme.TargetManifestID)
+
+	current, present := sme.State.Manifests.Get(mid)
+	if !present {
+		return EnsureErrorResult(errors.Errorf("No manifest matched by %v yet. See `sous init`", sme.ResolveFilter))
+	}
+	yml, err := yaml.Marshal(current)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	edited, err := sme.editManifest(mid, yml)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if edited == nil {
+		fmt.Fprintln(sme.OutWriter, "Empty manifest saved: nothing changed.")
+		return cmdr.Success()
+	}
+	changed, diffs := current.Diff(edited)
+	if !changed {
+		fmt.Fprintln(sme.OutWriter, "No changes made.")
+		return cmdr.Success()
+	}
+
+	fmt.Fprintf(sme.OutWriter, "Changes to %v (\"this\" is the current manifest, \"other\" is yours):\n", mid)
+	for _, d := range diffs {
+		fmt.Fprintf(sme.OutWriter, "  %s\n", d)
+	}
+	if !sme.flags.yes && !confirm(bufio.NewReader(sme.InReader), sme.OutWriter, "Save these changes? [y/N] ") {
+		fmt.Fprintln(sme.OutWriter, "Not saved.")
+		return cmdr.Success()
+	}
+
+	// The state writer only updates the manifest if the server's copy is
+	// still the one that was edited.
+	sme.State.Manifests.Set(mid, edited)
+	if err := sme.StateWriter.WriteState(sme.State, sme.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return cmdr.Success()
+}
+
+// editManifest lets the user edit yml until they save a valid manifest with
+// the ID mid, and returns it. It returns nil if they save an empty file.
+func (sme *SousManifestEdit) editManifest(mid sous.ManifestID, yml []byte) (*sous.Manifest, error) {
+	dir, err := ioutil.TempDir("", "sous-manifest-edit")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "manifest.yaml")
+
+	edit := sme.edit
+	if edit == nil {
+		edit = runEditor
+	}
+	text := yml
+	var invalid []byte
+	for {
+		if err := ioutil.WriteFile(path, text, 0600); err != nil {
+			return nil, err
+		}
+		if err := edit(path); err != nil {
+			return nil, errors.Wrap(err, "editing manifest")
+		}
+		saved, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		body := stripFlawComments(saved)
+		if len(bytes.TrimSpace(body)) == 0 {
+			return nil, nil
+		}
+		m, flaws := validateEditedManifest(mid, body, sme.State.Defs)
+		if len(flaws) == 0 {
+			return m, nil
+		}
+		if bytes.Equal(body, invalid) {
+			return nil, errors.Errorf("manifest %v is still invalid:\n  %s", mid, strings.Join(flaws, "\n  "))
+		}
+		invalid = body
+		text = append(flawComments(flaws), body...)
+	}
+}
+
+// validateEditedManifest parses body as a manifest, and lists its flaws, which
+// include not having the ID mid, and being deployed to clusters not in defs.
+func validateEditedManifest(mid sous.ManifestID, body []byte, defs sous.Defs) (*sous.Manifest, []string) {
+	m := &sous.Manifest{}
+	if err := yaml.Unmarshal(body, m); err != nil {
+		return nil, []string{err.Error()}
+	}
+	var flaws []string
+	if m.ID() != mid {
+		flaws = append(flaws, fmt.Sprintf("the manifest's source and flavor can't be edited: %v was changed to %v", mid, m.ID()))
+	}
+	for _, f := range m.Validate() {
+		flaws = append(flaws, fmt.Sprint(f))
+	}
+	var clusters []string
+	for name := range m.Deployments {
+		if _, ok := defs.Clusters[name]; !ok {
+			clusters = append(clusters, name)
+		}
+	}
+	sort.Strings(clusters)
+	for _, name := range clusters {
+		flaws = append(flaws, fmt.Sprintf("no cluster named %q in defs", name))
+	}
+	return m, flaws
+}
+
+// flawCommentPrefix begins the comments listing a manifest's flaws.
+const flawCommentPrefix = "# sous: "
+
+func flawComments(flaws []string) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(flawCommentPrefix + "This manifest is invalid. Fix these flaws and save it, or save an empty file to give up:\n")
+	for _, f := range flaws {
+		fmt.Fprintf(b, "%s  %s\n", flawCommentPrefix, strings.Replace(f, "\n", " ", -1))
+	}
+	return b.Bytes()
+}
+
+func stripFlawComments(text []byte) []byte {
+	b := &bytes.Buffer{}
+	for _, line := range bytes.SplitAfter(text, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte(flawCommentPrefix)) {
+			b.Write(line)
+		}
+	}
+	return b.Bytes()
+}
+
+// runEditor runs the user's editor on the file at path.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Run it with the shell, so that editors configured with flags work.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return errors.Wrapf(cmd.Run(), "running %s", editor)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"flag"
+	"io"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/graph"
@@ -17,6 +18,7 @@ type SousManifestGet struct {
 	*sous.State
 	graph.OutWriter
 	*sous.ResolveFilter
+	cmdr.Formatter
 }
 
 func init() { ManifestSubcommands["get"] = &SousManifestGet{} }
@@ -27,6 +29,7 @@ func (*SousManifestGet) Help() string { return sousManifestHelp }
 
 func (smg *SousManifestGet) AddFlags(fs *flag.FlagSet) {
 	MustAddFlags(fs, &smg.DeployFilterFlags, ManifestFilterFlagsHelp)
+	smg.AddFormatFlag(fs)
 }
 
 func (smg *SousManifestGet) RegisterOn(psy Addable) {
@@ -41,10 +44,16 @@ func (smg *SousManifestGet) Execute(args []string) cmdr.Result {
 		return EnsureErrorResult(errors.Errorf("No manifest matched by %v yet. See `sous init`", smg.ResolveFilter))
 	}
 
-	yml, err := yaml.Marshal(mani)
+	err := smg.Render(smg.OutWriter, mani, func(w io.Writer) error {
+		yml, err := yaml.Marshal(mani)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(yml)
+		return err
+	})
 	if err != nil {
 		return EnsureErrorResult(err)
 	}
-	smg.OutWriter.Write(yml)
 	return cmdr.Success()
 }
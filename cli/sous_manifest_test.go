@@ -2,12 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"io/ioutil"
 	"testing"
 
 	"github.com/nyarly/testify/assert"
 	"github.com/nyarly/testify/require"
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
 	"github.com/samsalisbury/yaml"
 )
 
@@ -63,3 +65,78 @@ func TestManifestSet(t *testing.T) {
 	assert.Equal(t, upManifest.Flavor, "vanilla")
 
 }
+
+func TestManifestEdit(t *testing.T) {
+	mid := sous.ManifestID{Source: project1}
+	state := makeTestState()
+	current, present := state.Manifests.Get(mid)
+	require.True(t, present)
+
+	dummyWriter := sous.DummyStateManager{State: state}
+	out := &bytes.Buffer{}
+	edits := 0
+	sme := &SousManifestEdit{
+		TargetManifestID: graph.TargetManifestID(mid),
+		State:            state,
+		StateWriter:      graph.StateWriter{StateWriter: &dummyWriter},
+		InReader:         graph.InReader(bytes.NewBufferString("y\n")),
+		OutWriter:        graph.OutWriter(out),
+		edit: func(path string) error {
+			edits++
+			text, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			m := current.Clone()
+			m.Owners = []string{"sam"}
+			if edits > 1 {
+				// The test state has no resources, so they must be added
+				// after the first save.
+				assert.Contains(t, string(text), flawCommentPrefix)
+				assert.Contains(t, string(text), `Missing resource field "cpus"`)
+				for cluster, spec := range m.Deployments {
+					spec.Resources = sous.Resources{"cpus": "1", "memory": "256", "ports": "1"}
+					m.Deployments[cluster] = spec
+				}
+			}
+			yml, err := yaml.Marshal(m)
+			require.NoError(t, err)
+			return ioutil.WriteFile(path, append(text[:0:0], yml...), 0600)
+		},
+	}
+
+	res := sme.Execute([]string{})
+	assert.Equal(t, 0, res.ExitCode())
+	assert.Equal(t, 2, edits)
+	assert.Equal(t, 1, dummyWriter.WriteCount)
+	assert.Contains(t, out.String(), "Save these changes?")
+
+	edited, present := state.Manifests.Get(mid)
+	require.True(t, present)
+	assert.Equal(t, []string{"sam"}, edited.Owners)
+}
+
+func TestManifestEditStillInvalid(t *testing.T) {
+	mid := sous.ManifestID{Source: project1}
+	state := makeTestState()
+	dummyWriter := sous.DummyStateManager{State: state}
+	edits := 0
+	sme := &SousManifestEdit{
+		TargetManifestID: graph.TargetManifestID(mid),
+		State:            state,
+		StateWriter:      graph.StateWriter{StateWriter: &dummyWriter},
+		OutWriter:        graph.OutWriter(&bytes.Buffer{}),
+		edit: func(path string) error {
+			edits++
+			if edits > 1 {
+				// Save it unchanged.
+				return nil
+			}
+			return ioutil.WriteFile(path, []byte("kind: http-service\ndeployments:\n  nowhere: {}\n"), 0600)
+		},
+	}
+
+	res := sme.Execute([]string{})
+	assert.NotEqual(t, 0, res.ExitCode())
+	assert.Equal(t, 2, edits)
+	assert.Equal(t, 0, dummyWriter.WriteCount)
+	assert.Regexp(t, `no cluster named "nowhere"`, res.(cmdr.ErrorResult).Error())
+}
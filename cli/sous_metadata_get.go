@@ -19,6 +19,7 @@ type SousMetadataGet struct {
 	*sous.State
 	graph.CurrentGDM
 	graph.OutWriter
+	cmdr.Formatter
 }
 
 func init() { MetadataSubcommands["get"] = &SousMetadataGet{} }
@@ -29,6 +30,7 @@ func (*SousMetadataGet) Help() string { return sousMetadataGetHelp }
 
 func (smg *SousMetadataGet) AddFlags(fs *flag.FlagSet) {
 	MustAddFlags(fs, &smg.DeployFilterFlags, MetadataFilterFlagsHelp)
+	smg.AddFormatFlag(fs)
 }
 
 func (smg *SousMetadataGet) RegisterOn(psy Addable) {
@@ -48,7 +50,15 @@ func (smg *SousMetadataGet) Execute(args []string) cmdr.Result {
 		if dep == nil {
 			return EnsureErrorResult(errors.Errorf("No manifest matched by %v", smg.ResolveFilter))
 		}
-		if err := outputMetadata(dep.Metadata, smg.ResolveFilter.Cluster, args, smg.OutWriter); err != nil {
+		cluster := smg.ResolveFilter.Cluster
+		v, err := selectMetadata(dep.Metadata, cluster, args)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		err = smg.Render(smg.OutWriter, v, func(w io.Writer) error {
+			return outputMetadata(dep.Metadata, cluster, args, w)
+		})
+		if err != nil {
 			return EnsureErrorResult(err)
 		}
 		return cmdr.Success()
@@ -66,16 +76,42 @@ func (smg *SousMetadataGet) Execute(args []string) cmdr.Result {
 		return EnsureErrorResult(errors.Errorf("No manifest matched by %v", smg.ResolveFilter))
 	}
 
-	for clusterName, deploySpec := range mani.Deployments {
-		smg.OutWriter.Write([]byte(fmt.Sprintf("Metadata for deployment in %s\n", clusterName)))
-		if err := outputMetadata(deploySpec.Metadata, clusterName, args, smg.OutWriter); err != nil {
+	all := map[string]interface{}{}
+	for clusterName, deploySpec := range mani.ExpandedDeployments() {
+		v, err := selectMetadata(deploySpec.Metadata, clusterName, args)
+		if err != nil {
 			return EnsureErrorResult(err)
 		}
+		all[clusterName] = v
+	}
+	err = smg.Render(smg.OutWriter, all, func(w io.Writer) error {
+		for clusterName, deploySpec := range mani.ExpandedDeployments() {
+			w.Write([]byte(fmt.Sprintf("Metadata for deployment in %s\n", clusterName)))
+			if err := outputMetadata(deploySpec.Metadata, clusterName, args, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return EnsureErrorResult(err)
 	}
-
 	return cmdr.Success()
 }
 
+// selectMetadata returns the metadata, or if a key is given in args, the
+// value of that key.
+func selectMetadata(metadata sous.Metadata, clusterName string, args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return metadata, nil
+	}
+	value, present := metadata[args[0]]
+	if !present {
+		return nil, errors.Errorf("No value for %q in cluster %s", args[0], clusterName)
+	}
+	return value, nil
+}
+
 func outputMetadata(metadata sous.Metadata, clusterName string, args []string, out io.Writer) error {
 	if len(args) == 0 {
 		yml, err := yaml.Marshal(metadata)
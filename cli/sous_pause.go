@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousPause is the description of the `sous pause` command
+type SousPause struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.ResolveFilter
+	*sous.State
+	graph.StateWriter
+	graph.OutWriter
+	User sous.User
+}
+
+func init() { TopLevelCommands["pause"] = &SousPause{} }
+
+const sousPauseHelp = `pause a deployment, keeping its manifest
+
+usage: sous pause -cluster <cluster> [-repo <repo>] [-offset <offset>] [-flavor <flavor>]
+
+Marks the deployment in the cluster paused in the GDM. The next rectification
+pauses its Singularity request, which stops its tasks but keeps the request
+and its history. Other changes to a paused deployment are made when it's
+resumed with 'sous resume'.
+`
+
+// Help prints the help
+func (*SousPause) Help() string { return sousPauseHelp }
+
+// AddFlags adds the flags for sous pause.
+func (sp *SousPause) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sp *SousPause) RegisterOn(psy Addable) {
+	psy.Add(&sp.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous pause`
+func (sp *SousPause) Execute(args []string) cmdr.Result {
+	did, err := taskDeployID(sp.TargetManifestID, sp.ResolveFilter)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := setPaused(sp.State, did, true); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := sp.StateWriter.WriteState(sp.State, sp.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	fmt.Fprintf(sp.OutWriter, "Paused %v in %s.\n", did.ManifestID, did.Cluster)
+	return cmdr.Success()
+}
+
+// setPaused marks the deployment did paused, or not, in state's manifests.
+func setPaused(state *sous.State, did sous.DeployID, paused bool) error {
+	m, ok := state.Manifests.Get(did.ManifestID)
+	if !ok {
+		return errors.Errorf("No manifest matched by %v yet. See `sous init`", did.ManifestID)
+	}
+	spec, ok := m.Deployments[did.Cluster]
+	if !ok {
+		return errors.Errorf("%v isn't deployed to %q", did.ManifestID, did.Cluster)
+	}
+	if spec.Paused == paused {
+		if paused {
+			return errors.Errorf("%v is already paused in %s", did.ManifestID, did.Cluster)
+		}
+		return errors.Errorf("%v isn't paused in %s", did.ManifestID, did.Cluster)
+	}
+	spec.Paused = paused
+	m.Deployments[did.Cluster] = spec
+	state.Manifests.Set(did.ManifestID, m)
+	return nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	state := makeTestState()
+	dummyWriter := &sous.DummyStateManager{State: state}
+	writer := graph.StateWriter{StateWriter: dummyWriter}
+	target := graph.TargetManifestID{Source: project1}
+	filter := &sous.ResolveFilter{Cluster: "cluster-1"}
+	paused := func(cluster string) bool {
+		m, ok := state.Manifests.Get(sous.ManifestID{Source: project1})
+		require.True(t, ok)
+		return m.Deployments[cluster].Paused
+	}
+
+	buf := &bytes.Buffer{}
+	pause := &SousPause{TargetManifestID: target, ResolveFilter: filter, State: state, StateWriter: writer, OutWriter: buf}
+	res := pause.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	assert.True(t, paused("cluster-1"))
+	assert.False(t, paused("cluster-2"))
+	assert.Contains(t, buf.String(), "Paused github.com/user/project in cluster-1")
+	assert.NotEqual(t, 0, pause.Execute(nil).ExitCode(), "paused twice")
+
+	ds, err := state.Deployments()
+	require.NoError(t, err)
+	d, ok := ds.Get(sous.DeployID{ManifestID: sous.ManifestID{Source: project1}, Cluster: "cluster-1"})
+	require.True(t, ok)
+	assert.True(t, d.Paused)
+
+	resume := &SousResume{TargetManifestID: target, ResolveFilter: filter, State: state, StateWriter: writer, OutWriter: buf}
+	res = resume.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	assert.False(t, paused("cluster-1"))
+	assert.NotEqual(t, 0, resume.Execute(nil).ExitCode(), "resumed twice")
+	assert.Equal(t, 2, dummyWriter.WriteCount)
+
+	resume.ResolveFilter = &sous.ResolveFilter{}
+	assert.NotEqual(t, 0, resume.Execute(nil).ExitCode(), "no -cluster")
+	resume.ResolveFilter = &sous.ResolveFilter{Cluster: "cluster-3"}
+	assert.NotEqual(t, 0, resume.Execute(nil).ExitCode(), "not deployed to the cluster")
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPlumbingDB is the `sous plumbing db` command.
+type SousPlumbingDB struct {
+	NameCache *docker.NameCache
+	graph.OutWriter
+	flags struct {
+		migrate, verify bool
+	}
+}
+
+func init() { PlumbingSubcommands["db"] = &SousPlumbingDB{} }
+
+const sousPlumbingDBHelp = `reports the schema version of the name cache database
+
+usage: sous plumbing db [-migrate] [-verify]
+
+The name cache database is the one configured by Docker.DatabaseDriver and
+Docker.DatabaseConnection. Pending migrations are usually applied whenever
+the database is opened; -migrate applies them explicitly. -verify checks that
+the schema is the same as that of a new database.
+`
+
+// Help implements Command on SousPlumbingDB.
+func (*SousPlumbingDB) Help() string { return sousPlumbingDBHelp }
+
+// AddFlags implements cmdr.AddFlags on SousPlumbingDB.
+func (spd *SousPlumbingDB) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&spd.flags.migrate, "migrate", false, "apply any pending schema migrations")
+	fs.BoolVar(&spd.flags.verify, "verify", false, "verify the schema against the latest version")
+}
+
+// RegisterOn implements Registrant on SousPlumbingDB.
+func (*SousPlumbingDB) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Execute implements cmdr.Executor on SousPlumbingDB.
+func (spd *SousPlumbingDB) Execute(args []string) cmdr.Result {
+	var st docker.SchemaStatus
+	var err error
+	if spd.flags.migrate {
+		st, err = spd.NameCache.Migrate()
+	} else {
+		st, err = spd.NameCache.SchemaStatus()
+	}
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	fmt.Fprintf(spd.OutWriter, "Schema version %d of %d\n", st.Version, st.Latest)
+	for _, am := range st.Applied {
+		fmt.Fprintf(spd.OutWriter, "  %d: %s (%s)\n", am.Version, am.Description, am.Applied)
+	}
+	for i, p := range st.Pending {
+		fmt.Fprintf(spd.OutWriter, "  %d: %s (pending)\n", st.Version+i+1, p)
+	}
+
+	if spd.flags.verify {
+		if err := spd.NameCache.VerifySchema(); err != nil {
+			return EnsureErrorResult(err)
+		}
+		fmt.Fprintln(spd.OutWriter, "Schema verified.")
+	}
+	return cmdr.Success()
+}
@@ -4,18 +4,34 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
 	"time"
 
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/cmdr"
 )
 
-// SousPlumbingStatus is the `sous plumbing status` object.
-type SousPlumbingStatus struct {
-	DeployFilterFlags config.DeployFilterFlags
-	StatusPoller      *sous.StatusPoller
-}
+type (
+	// SousPlumbingStatus is the `sous plumbing status` object.
+	SousPlumbingStatus struct {
+		DeployFilterFlags config.DeployFilterFlags
+		StatusPoller      *sous.StatusPoller
+		graph.OutWriter
+		cmdr.Formatter
+	}
+
+	// plumbingStatusRecord is the output of `sous plumbing status`: the state
+	// resolution reached, and the status of the deployment's resolution in
+	// each cluster.
+	plumbingStatusRecord struct {
+		State    string
+		Clusters map[string]sous.ResolveStatusRecord
+	}
+)
 
 func init() { PlumbingSubcommands["status"] = &SousPlumbingStatus{} }
 
@@ -27,6 +43,7 @@ func (*SousPlumbingStatus) Help() string {
 // AddFlags implements cmdr.AddFlags on SousPlumbingStatus.
 func (sps *SousPlumbingStatus) AddFlags(fs *flag.FlagSet) {
 	MustAddFlags(fs, &sps.DeployFilterFlags, DeployFilterFlagsHelp)
+	sps.AddFormatFlag(fs)
 }
 
 // RegisterOn implements Registrant on SousPlumbingStatus.
@@ -47,9 +64,45 @@ func (sps *SousPlumbingStatus) Execute(args []string) cmdr.Result {
 	if err != nil {
 		return cmdr.EnsureErrorResult(err)
 	}
+
+	rec := plumbingStatusRecord{State: state.String(), Clusters: map[string]sous.ResolveStatusRecord{}}
+	for cluster, rs := range sps.StatusPoller.Statuses() {
+		rec.Clusters[cluster] = rs.Record()
+	}
+	if err := sps.Render(sps.OutWriter, rec, rec.asTable); err != nil {
+		return cmdr.EnsureErrorResult(err)
+	}
 	if state != sous.ResolveComplete {
 		return cmdr.EnsureErrorResult(fmt.Errorf("failed"))
 	}
 
 	return cmdr.Success()
 }
+
+// asTable writes how the deployment was resolved in each cluster, and any
+// errors, then the state reached.
+func (rec plumbingStatusRecord) asTable(to io.Writer) error {
+	clusters := []string{}
+	for c := range rec.Clusters {
+		clusters = append(clusters, c)
+	}
+	sort.Strings(clusters)
+
+	w := &tabwriter.Writer{}
+	w.Init(to, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Cluster\tPhase\tResolution\tError")
+	for _, c := range clusters {
+		rs := rec.Clusters[c]
+		for _, rez := range rs.Log {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c, rs.Phase, rez.Resolution, rez.Error)
+		}
+		for _, e := range rs.Errors {
+			fmt.Fprintf(w, "%s\t%s\t\t%s\n", c, rs.Phase, e)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(to, rec.State)
+	return err
+}
@@ -1,7 +1,8 @@
 package cli
 
 import (
-	"os"
+	"flag"
+	"io"
 
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
@@ -16,6 +17,7 @@ type SousQueryAds struct {
 	sous.Registry
 	GDM   graph.CurrentGDM
 	State *sous.State
+	cmdr.Formatter
 	flags struct {
 		singularity string
 		registry    string
@@ -29,6 +31,11 @@ const sousQueryAdsHelp = `The current state of deployment for every project and
 // Help prints the help
 func (*SousQueryAds) Help() string { return sousQueryAdsHelp }
 
+// AddFlags adds the flags for sous query ads.
+func (sb *SousQueryAds) AddFlags(fs *flag.FlagSet) {
+	sb.AddFormatFlag(fs)
+}
+
 // RegisterOn adds stuff to the graph.
 func (*SousQueryAds) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunNeither)
@@ -40,6 +47,8 @@ func (sb *SousQueryAds) Execute(args []string) cmdr.Result {
 	if err != nil {
 		return EnsureErrorResult(err)
 	}
-	sous.DumpDeployStatuses(os.Stdout, ads)
-	return cmdr.Success()
+	return sb.Result(ads.Records(), func(w io.Writer) error {
+		sous.DumpDeployStatuses(w, ads)
+		return nil
+	})
 }
@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"flag"
+	"io"
+
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/cmdr"
@@ -9,17 +12,25 @@ import (
 // SousQueryArtifacts is the description of the `sous query gdm` command
 type SousQueryArtifacts struct {
 	*sous.RegistryDumper
-	graph.ErrWriter
+	cmdr.Formatter
 }
 
 func init() { QuerySubcommands["artifacts"] = &SousQueryArtifacts{} }
 
 const sousQueryArtifactsHelp = `Lists the images that Sous is currently aware of.
 
+If a server is configured, the images are those known to the server, which
+are the ones its resolver will deploy. Otherwise the local cache is listed.
+
 Note that Sous may discover more images after attempting a rectify
 
 `
 
+// AddFlags adds the flags for sous query artifacts.
+func (sqa *SousQueryArtifacts) AddFlags(fs *flag.FlagSet) {
+	sqa.AddFormatFlag(fs)
+}
+
 func (*SousQueryArtifacts) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunNeither)
 }
@@ -29,6 +40,11 @@ func (*SousQueryArtifacts) Help() string { return sousQueryArtifactsHelp }
 
 // Execute defines the behavior of `sous query gdm`
 func (sqa *SousQueryArtifacts) Execute(args []string) cmdr.Result {
-	err := sqa.RegistryDumper.AsTable(sqa.ErrWriter)
-	return ProduceResult(err)
+	es, err := sqa.RegistryDumper.Entries()
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	return sqa.Result(es, func(w io.Writer) error {
+		return sous.DumpArtifacts(w, es)
+	})
 }
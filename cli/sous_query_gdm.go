@@ -1,7 +1,8 @@
 package cli
 
 import (
-	"os"
+	"flag"
+	"io"
 
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
@@ -10,7 +11,8 @@ import (
 
 // SousQueryGDM is the description of the `sous query gdm` command
 type SousQueryGDM struct {
-	GDM   graph.CurrentGDM
+	GDM graph.CurrentGDM
+	cmdr.Formatter
 	flags struct {
 		singularity string
 		registry    string
@@ -28,6 +30,11 @@ a problem is preventing sous from modifying the current state of Singularity.
 // Help prints the help
 func (*SousQueryGDM) Help() string { return sousQueryGDMHelp }
 
+// AddFlags adds the flags for sous query gdm.
+func (sb *SousQueryGDM) AddFlags(fs *flag.FlagSet) {
+	sb.AddFormatFlag(fs)
+}
+
 func (*SousQueryGDM) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunNeither)
 }
@@ -35,6 +42,8 @@ func (*SousQueryGDM) RegisterOn(psy Addable) {
 // Execute defines the behavior of `sous query gdm`
 func (sb *SousQueryGDM) Execute(args []string) cmdr.Result {
 	sous.Log.Vomit.Printf("%v", sb.GDM.Snapshot())
-	sous.DumpDeployments(os.Stdout, sb.GDM.Deployments)
-	return cmdr.Success()
+	return sb.Result(sb.GDM.Records(), func(w io.Writer) error {
+		sous.DumpDeployments(w, sb.GDM.Deployments)
+		return nil
+	})
 }
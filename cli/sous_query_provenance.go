@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousQueryProvenance is the description of the `sous query provenance` command
+type SousQueryProvenance struct {
+	NameCache *docker.NameCache
+	cmdr.Formatter
+}
+
+func init() { QuerySubcommands["provenance"] = &SousQueryProvenance{} }
+
+const sousQueryProvenanceHelp = `Shows how the image for a source ID was built
+
+usage: sous query provenance <repo>,<version>[,<offset>]
+
+Reports who built the image, on which host, with which buildpack, from which
+base image, Dockerfile and git revision, and when, as recorded in the labels
+of the image by sous build.
+`
+
+// Help prints the help
+func (*SousQueryProvenance) Help() string { return sousQueryProvenanceHelp }
+
+// AddFlags adds the flags for sous query provenance.
+func (sqp *SousQueryProvenance) AddFlags(fs *flag.FlagSet) {
+	sqp.AddFormatFlag(fs)
+}
+
+// RegisterOn adds stuff to the graph.
+func (*SousQueryProvenance) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunNeither)
+}
+
+// Execute defines the behavior of `sous query provenance`
+func (sqp *SousQueryProvenance) Execute(args []string) cmdr.Result {
+	if len(args) != 1 {
+		return cmdr.UsageErrorf("expected exactly one source ID, received %d arguments", len(args))
+	}
+	sid, err := sous.ParseSourceID(args[0])
+	if err != nil {
+		return cmdr.UsageErrorf("%s", err)
+	}
+	p, err := sqp.NameCache.Provenance(sid)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if p == nil {
+		return EnsureErrorResult(errors.Errorf("no provenance is recorded for %v", sid))
+	}
+	return sqp.Result(p, p.AsTable)
+}
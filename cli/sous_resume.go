@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousResume is the description of the `sous resume` command
+type SousResume struct {
+	config.DeployFilterFlags
+	graph.TargetManifestID
+	*sous.ResolveFilter
+	*sous.State
+	graph.StateWriter
+	graph.OutWriter
+	User sous.User
+}
+
+func init() { TopLevelCommands["resume"] = &SousResume{} }
+
+const sousResumeHelp = `resume a paused deployment
+
+usage: sous resume -cluster <cluster> [-repo <repo>] [-offset <offset>] [-flavor <flavor>]
+
+Marks the deployment in the cluster, paused with 'sous pause', no longer
+paused in the GDM. The next rectification unpauses its Singularity request,
+and then makes any changes to the deployment made while it was paused.
+`
+
+// Help prints the help
+func (*SousResume) Help() string { return sousResumeHelp }
+
+// AddFlags adds the flags for sous resume.
+func (sr *SousResume) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.DeployFilterFlags, MetadataFilterFlagsHelp)
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sr *SousResume) RegisterOn(psy Addable) {
+	psy.Add(&sr.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous resume`
+func (sr *SousResume) Execute(args []string) cmdr.Result {
+	did, err := taskDeployID(sr.TargetManifestID, sr.ResolveFilter)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := setPaused(sr.State, did, false); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := sr.StateWriter.WriteState(sr.State, sr.User); err != nil {
+		return EnsureErrorResult(err)
+	}
+	fmt.Fprintf(sr.OutWriter, "Resumed %v in %s.\n", did.ManifestID, did.Cluster)
+	return cmdr.Success()
+}
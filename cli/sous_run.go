@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+type (
+	// SousRun is the description of the `sous run` command
+	SousRun struct {
+		config.DeployFilterFlags
+		graph.TargetManifestID
+		*sous.ResolveFilter
+		*sous.State
+		graph.OutWriter
+		HTTPClient graph.HTTPClient
+		Runner     *singularity.Runner
+		User       sous.User
+		// sleep waits between checks on the run with -wait. It defaults to
+		// time.Sleep.
+		sleep func(time.Duration)
+		flags struct {
+			env  envFlag
+			wait bool
+		}
+	}
+
+	// envFlag collects the NAME=value pairs given with repeated -env flags.
+	envFlag map[string]string
+)
+
+func init() { TopLevelCommands["run"] = &SousRun{} }
+
+const sousRunHelp = `run an on-demand or run-once deployment now
+
+usage: sous run -cluster <cluster> [-repo <repo>] [-offset <offset>] [-flavor <flavor>] [-env NAME=value]... [-wait] [-- <arg>...]
+
+Asks Singularity to run the deployment in the cluster, whose manifest must be
+of the kind on-demand or once, and prints the ID of the run. Any arguments
+after -- replace the deployment's Args for this run, and each -env adds to its
+Env. The run is recorded as triggered by you.
+
+With -wait, it waits for the run's task to finish, prints its final state, and
+fails if the task did.
+`
+
+// runPollInterval is how long sous run -wait waits between checks on the run.
+const runPollInterval = 2 * time.Second
+
+// Help prints the help
+func (*SousRun) Help() string { return sousRunHelp }
+
+// AddFlags adds the flags for sous run.
+func (sr *SousRun) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sr.DeployFilterFlags, MetadataFilterFlagsHelp)
+	sr.flags.env = envFlag{}
+	fs.Var(sr.flags.env, "env", "NAME=value to add to the run's env; repeat for more")
+	fs.BoolVar(&sr.flags.wait, "wait", false, "wait for the run to finish, and report how it did")
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (sr *SousRun) RegisterOn(psy Addable) {
+	psy.Add(&sr.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous run`
+func (sr *SousRun) Execute(args []string) cmdr.Result {
+	did, err := taskDeployID(sr.TargetManifestID, sr.ResolveFilter)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	m, ok := sr.State.Manifests.Get(did.ManifestID)
+	if !ok {
+		return EnsureErrorResult(errors.Errorf("No manifest matched by %v yet. See `sous init`", sr.ResolveFilter))
+	}
+	if err := singularity.Runnable(m, did.Cluster); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	rr := singularity.RunRequest{ID: uuid.NewV4().String(), Args: args, Env: sr.flags.env}
+	if err := sr.trigger(m, did, rr); err != nil {
+		return EnsureErrorResult(err)
+	}
+	if !sr.flags.wait {
+		return cmdr.SuccessData([]byte(rr.ID + "\n"))
+	}
+
+	fmt.Fprintf(sr.OutWriter, "Triggered run %s of %v; waiting for it to finish.\n", rr.ID, did)
+	sleep := sr.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	for {
+		task, err := sr.runTask(did, rr.ID)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		if task != nil && task.Finished() {
+			fmt.Fprintf(sr.OutWriter, "Task %s on %s: %s %s\n", task.ID, task.Host, task.State, task.Message)
+			if !task.Succeeded() {
+				return EnsureErrorResult(errors.Errorf("run %s failed: %s", rr.ID, task.State))
+			}
+			return cmdr.Success()
+		}
+		sleep(runPollInterval)
+	}
+}
+
+// trigger asks the server, if one is configured, or otherwise Singularity,
+// to run the deployment did of m.
+func (sr *SousRun) trigger(m *sous.Manifest, did sous.DeployID, rr singularity.RunRequest) error {
+	if sr.HTTPClient.HTTPClient == nil {
+		return sr.Runner.Trigger(sr.State.Defs.Clusters, m, did.Cluster, rr, sr.User)
+	}
+	params := deployIDParams(did)
+	params["run"] = rr.ID
+	body := map[string]interface{}{"Args": rr.Args, "Env": rr.Env}
+	return sr.HTTPClient.Create("./run", params, body, sr.User)
+}
+
+// runTask returns the task of the run runID of did, or nil if it hasn't been
+// launched yet.
+func (sr *SousRun) runTask(did sous.DeployID, runID string) (*singularity.Task, error) {
+	if sr.HTTPClient.HTTPClient == nil {
+		return sr.Runner.RunTask(sr.State.Defs.Clusters, did, runID)
+	}
+	params := deployIDParams(did)
+	params["run"] = runID
+	task := &singularity.Task{}
+	err := sr.HTTPClient.Retrieve("./run", params, task, sr.User)
+	if sous.IsNotFound(err) {
+		return nil, nil
+	}
+	return task, err
+}
+
+func (e envFlag) String() string {
+	pairs := []string{}
+	for name, value := range e {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// Set implements flag.Value on envFlag.
+func (e envFlag) Set(pair string) error {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return errors.Errorf("%q isn't of the form NAME=value", pair)
+	}
+	e[parts[0]] = parts[1]
+	return nil
+}
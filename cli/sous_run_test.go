@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	sing "github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/swaggering"
+)
+
+func TestRun(t *testing.T) {
+	fake := fake_singularity.NewServer()
+	defer fake.Close()
+	state := makeTestState()
+	state.Defs.Clusters["cluster-1"].BaseURL = fake.URL
+	target := graph.TargetManifestID{Source: project1}
+	did := sous.DeployID{ManifestID: sous.ManifestID(target), Cluster: "cluster-1"}
+	reqID := singularity.MakeRequestID(did)
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(t, err)
+	_, err = sing.NewClient(fake.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	run := &SousRun{TargetManifestID: target, ResolveFilter: &sous.ResolveFilter{Cluster: "cluster-1"},
+		State: state, OutWriter: buf, Runner: singularity.NewRunner(singularity.NewTaskReader()),
+		User: sous.User{Name: "Tester", Email: "tester@example.com"}}
+	assert.NotEqual(t, 0, run.Execute(nil).ExitCode(), "project1 is a service")
+
+	m, _ := state.Manifests.Get(did.ManifestID)
+	m.Kind = sous.ManifestKindOnDemand
+	run.flags.env = envFlag{}
+	require.NoError(t, run.flags.env.Set("GREETING=hello=world"))
+	assert.Error(t, run.flags.env.Set("nonsense"))
+	res := run.Execute([]string{"-n", "3"})
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	runID := strings.TrimSpace(res.(cmdr.SuccessResult).String())
+	taskID := fake.RunTaskID(reqID, runID)
+	require.NotEmpty(t, taskID)
+	fake.UpdateTask(taskID, func(task *fake_singularity.Task) {
+		assert.Equal(t, []string{"-n", "3"}, task.Args)
+		assert.Equal(t, map[string]string{"GREETING": "hello=world"}, task.Env)
+		assert.Contains(t, task.RunMessage, "tester@example.com")
+	})
+
+	// With -wait, it reports how the run's task finished, once it has.
+	for _, final := range []string{"TASK_FINISHED", "TASK_FAILED"} {
+		buf.Reset()
+		run.flags.wait = true
+		sleeps := 0
+		run.sleep = func(time.Duration) {
+			sleeps++
+			runID := strings.Fields(buf.String())[2]
+			fake.UpdateTask(fake.RunTaskID(reqID, runID), func(task *fake_singularity.Task) {
+				task.Active, task.State, task.Message = false, final, "done"
+			})
+		}
+		res = run.Execute(nil)
+		assert.Equal(t, 1, sleeps)
+		assert.Contains(t, buf.String(), final+" done")
+		if final == "TASK_FINISHED" {
+			assert.Equal(t, 0, res.ExitCode(), "%v", res)
+		} else {
+			assert.NotEqual(t, 0, res.ExitCode(), "the run failed")
+		}
+	}
+}
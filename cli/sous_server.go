@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/docker"
 	"github.com/opentable/sous/ext/git"
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
@@ -75,6 +76,25 @@ func (ss *SousServer) Execute(args []string) cmdr.Result {
 	ss.SousGraph.MustInject(&arWrapper)
 	arWrapper.AutoResolver.Kickoff()
 
+	var harvester struct {
+		*docker.Harvester
+	}
+	ss.SousGraph.MustInject(&harvester)
+	harvester.Kickoff()
+
+	var collector struct {
+		*docker.GarbageCollector
+		graph.StateReader
+	}
+	ss.SousGraph.MustInject(&collector)
+	collector.GarbageCollector.Kickoff(func() (sous.Deployments, error) {
+		state, err := collector.StateReader.ReadState()
+		if err != nil {
+			return sous.Deployments{}, err
+		}
+		return state.Deployments()
+	})
+
 	ss.Log.Info.Printf("Sous Server v%s running at %s for %s", ss.Sous.Version, ss.flags.laddr, ss.DeployFilterFlags.Cluster)
 
 	return EnsureErrorResult(server.Run(ss.SousGraph, ss.flags.laddr)) //always non-nil
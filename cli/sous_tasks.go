@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+type (
+	// SousTasks is the description of the `sous tasks` command
+	SousTasks struct {
+		config.DeployFilterFlags
+		graph.TargetManifestID
+		*sous.ResolveFilter
+		*sous.State
+		HTTPClient graph.HTTPClient
+		TaskReader *singularity.TaskReader
+		User       sous.User
+		cmdr.Formatter
+		flags struct {
+			recent int
+		}
+	}
+
+	// taskSource finds the tasks of deployments, and reads their logs, from
+	// the server if one is configured, and otherwise from Singularity.
+	taskSource struct {
+		client   graph.HTTPClient
+		reader   *singularity.TaskReader
+		clusters sous.Clusters
+		user     sous.User
+	}
+)
+
+func init() { TopLevelCommands["tasks"] = &SousTasks{} }
+
+const sousTasksHelp = `list the Singularity tasks of a deployment
+
+usage: sous tasks -cluster <cluster> [-repo <repo>] [-offset <offset>] [-flavor <flavor>] [-recent <n>]
+
+Lists the active tasks of the deployment in the cluster, and the ones that
+finished most recently, newest first, with the host each ran on, when it
+started, and its last state. The message of a finished task's last state gives
+its exit status. Read a task's output with 'sous logs'.
+`
+
+// Help prints the help
+func (*SousTasks) Help() string { return sousTasksHelp }
+
+// AddFlags adds the flags for sous tasks.
+func (st *SousTasks) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &st.DeployFilterFlags, MetadataFilterFlagsHelp)
+	fs.IntVar(&st.flags.recent, "recent", 5, "the number of finished tasks to list")
+	st.AddFormatFlag(fs)
+}
+
+// RegisterOn adds the DeployFilterFlags to the graph.
+func (st *SousTasks) RegisterOn(psy Addable) {
+	psy.Add(&st.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous tasks`
+func (st *SousTasks) Execute(args []string) cmdr.Result {
+	did, err := taskDeployID(st.TargetManifestID, st.ResolveFilter)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	ts := taskSource{client: st.HTTPClient, reader: st.TaskReader, clusters: st.State.Defs.Clusters, user: st.User}
+	tasks, err := ts.tasks(did, st.flags.recent)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	return st.Result(tasks, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TASK\tHOST\tSTARTED\tSTATE\tMESSAGE")
+		for _, t := range tasks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.ID, t.Host, t.Started.Format(time.RFC3339), t.State, t.Message)
+		}
+		return tw.Flush()
+	})
+}
+
+// taskDeployID returns the deployment in the cluster chosen with -cluster of
+// the manifest mid.
+func taskDeployID(mid graph.TargetManifestID, rf *sous.ResolveFilter) (sous.DeployID, error) {
+	if rf.Cluster == "" {
+		return sous.DeployID{}, cmdr.UsageErrorf("You must select a cluster using the -cluster flag.")
+	}
+	return sous.DeployID{ManifestID: sous.ManifestID(mid), Cluster: rf.Cluster}, nil
+}
+
+// deployIDParams are the query parameters the server's /tasks and /logs
+// take to identify did.
+func deployIDParams(did sous.DeployID) map[string]string {
+	return map[string]string{
+		"repo":    did.ManifestID.Source.Repo,
+		"offset":  did.ManifestID.Source.Dir,
+		"flavor":  did.ManifestID.Flavor,
+		"cluster": did.Cluster,
+	}
+}
+
+func (ts taskSource) tasks(did sous.DeployID, recent int) ([]singularity.Task, error) {
+	if ts.client.HTTPClient == nil {
+		return ts.reader.DeploymentTasks(ts.clusters, did, recent)
+	}
+	params := deployIDParams(did)
+	params["recent"] = strconv.Itoa(recent)
+	tasks := []singularity.Task{}
+	return tasks, ts.client.Retrieve("./tasks", params, &tasks, ts.user)
+}
+
+func (ts taskSource) log(did sous.DeployID, taskID, file string, position int64) (singularity.LogChunk, error) {
+	if ts.client.HTTPClient == nil {
+		return ts.reader.DeploymentLog(ts.clusters, did, taskID, file, position)
+	}
+	params := deployIDParams(did)
+	params["task"] = taskID
+	params["file"] = file
+	params["position"] = strconv.FormatInt(position, 10)
+	chunk := singularity.LogChunk{}
+	return chunk, ts.client.Retrieve("./logs", params, &chunk, ts.user)
+}
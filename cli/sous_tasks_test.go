@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	sing "github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/swaggering"
+)
+
+func TestTasksAndLogs(t *testing.T) {
+	fake := fake_singularity.NewServer()
+	defer fake.Close()
+	state := makeTestState()
+	state.Defs.Clusters["cluster-1"].BaseURL = fake.URL
+	target := graph.TargetManifestID{Source: project1}
+	filter := &sous.ResolveFilter{Cluster: "cluster-1"}
+	reqID := singularity.MakeRequestID(sous.DeployID{ManifestID: sous.ManifestID(target), Cluster: "cluster-1"})
+
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(t, err)
+	_, err = sing.NewClient(fake.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(t, err)
+	failed, err := fake.AddTask(reqID, fake_singularity.Task{Host: "host1", State: "TASK_FAILED",
+		Message: "Exited with status 1", Stdout: "starting\n", Stderr: "boom\n"})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond) // so the running task starts later
+	running, err := fake.AddTask(reqID, fake_singularity.Task{Host: "host2", Active: true,
+		State: "TASK_RUNNING", Stdout: "up\n"})
+	require.NoError(t, err)
+
+	tasks := &SousTasks{TargetManifestID: target, ResolveFilter: filter, State: state, TaskReader: singularity.NewTaskReader()}
+	tasks.flags.recent = 5
+	res := tasks.Execute(nil)
+	require.Equal(t, 0, res.ExitCode(), "%v", res)
+	out := res.(cmdr.SuccessResult).String()
+	assert.Regexp(t, `(?m)^`+running+`\s+host2\s+\S+\s+TASK_RUNNING\s*$`, out)
+	assert.Regexp(t, `(?m)^`+failed+`\s+host1\s+\S+\s+TASK_FAILED\s+Exited with status 1$`, out)
+
+	tasks.ResolveFilter = &sous.ResolveFilter{}
+	assert.NotEqual(t, 0, tasks.Execute(nil).ExitCode(), "no -cluster")
+
+	buf := &bytes.Buffer{}
+	logs := &SousLogs{TargetManifestID: target, ResolveFilter: filter, State: state, OutWriter: buf,
+		TaskReader: singularity.NewTaskReader()}
+	require.Equal(t, 0, logs.Execute(nil).ExitCode())
+	assert.Equal(t, "up\n", buf.String(), "the newest task's stdout")
+
+	buf.Reset()
+	logs.flags.task, logs.flags.stderr = failed, true
+	require.Equal(t, 0, logs.Execute(nil).ExitCode())
+	assert.Equal(t, "boom\n", buf.String())
+
+	// Following the running task prints its output until it finishes.
+	buf.Reset()
+	logs.flags.task, logs.flags.stderr, logs.flags.follow = running, false, true
+	sleeps := 0
+	logs.sleep = func(time.Duration) {
+		sleeps++
+		fake.UpdateTask(running, func(t *fake_singularity.Task) {
+			t.Stdout += "more\n"
+			if sleeps == 2 {
+				t.Active = false
+			}
+		})
+	}
+	require.Equal(t, 0, logs.Execute(nil).ExitCode())
+	assert.Equal(t, "up\nmore\nmore\n", buf.String())
+	assert.Equal(t, 2, sleeps)
+}
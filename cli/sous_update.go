@@ -86,6 +86,7 @@ func updateState(s *sous.State, gdm graph.CurrentGDM, sid sous.SourceID, did sou
 	if err != nil {
 		return EnsureErrorResult(err)
 	}
+	manifests.KeepDefaults(s.Manifests)
 	s.Manifests = manifests
 	return nil
 }
@@ -89,6 +89,7 @@ var updateStateTests = []struct {
 			Defs: sous.Defs{Clusters: sous.Clusters{
 				"blah": &sous.Cluster{Name: "blah"},
 			}},
+			Manifests: sous.NewManifests(),
 		},
 		GDM: graph.CurrentGDM{Deployments: sous.NewDeployments()},
 		DID: sous.DeployID{
@@ -36,6 +36,55 @@ type (
 		Docker docker.Config
 		// User identifies the user of this client.
 		User sous.User
+		// AuthToken is the bearer token this instance presents when it talks to
+		// a Sous server, including sibling servers.
+		AuthToken string `env:"SOUS_AUTH_TOKEN"`
+		// Auth configures how a Sous server authenticates its clients.
+		Auth AuthConfig
+		// TLS configures TLS for the server and for connections to servers.
+		TLS TLSConfig
+		// Leader configures leader election between redundant servers for the
+		// same cluster.
+		Leader LeaderConfig
+		// Autoscale configures where the server reads load from when applying
+		// autoscaling policies.
+		Autoscale AutoscaleConfig
+	}
+
+	// AutoscaleConfig configures the metrics used to apply the autoscaling
+	// policies of deployments. If MetricsFile is empty, deployments are not
+	// autoscaled.
+	AutoscaleConfig struct {
+		// MetricsFile is a YAML file of current metrics per deployment, as
+		// read by storage.FileMetricsSource.
+		MetricsFile string `env:"SOUS_AUTOSCALE_METRICS_FILE"`
+	}
+
+	// LeaderConfig configures leader election between redundant Sous servers
+	// for a cluster. Only the leader resolves deployments; the other servers
+	// serve reads and forward writes to the leader. If LeaseFile is empty,
+	// this server always acts as leader.
+	LeaderConfig struct {
+		// LeaseFile is the file holding the leadership lease. It must be on
+		// storage shared by all the servers for the cluster.
+		LeaseFile string `env:"SOUS_LEADER_LEASE_FILE"`
+		// AdvertiseURL is the URL at which the other servers can reach this
+		// one. It identifies this server when it holds the lease.
+		AdvertiseURL string `env:"SOUS_ADVERTISE_URL"`
+		// LeaseSeconds is how long a lease lasts without renewal. Defaults to
+		// 180.
+		LeaseSeconds int `env:"SOUS_LEADER_LEASE_SECONDS"`
+	}
+
+	// AuthConfig configures authentication for the Sous server. If UsersFile
+	// is empty, the server does not authenticate requests at all.
+	AuthConfig struct {
+		// UsersFile is a YAML file listing the users known to this server,
+		// whether they are admins, and the static tokens issued to them.
+		UsersFile string `env:"SOUS_AUTH_USERS_FILE"`
+		// SigningKey is the shared secret used to verify signed tokens. If it
+		// is empty, only static tokens are accepted.
+		SigningKey string `env:"SOUS_AUTH_SIGNING_KEY"`
 	}
 )
 
@@ -63,7 +112,15 @@ func (c Config) Validate() error {
 			return err
 		}
 	}
-	return nil
+	if c.Auth.SigningKey != "" && c.Auth.UsersFile == "" {
+		return errors.Errorf("Config.Auth.SigningKey requires Config.Auth.UsersFile")
+	}
+	if c.Leader.LeaseFile != "" {
+		if err := checkURL(c.Leader.AdvertiseURL, "Config.Leader.AdvertiseURL"); err != nil {
+			return err
+		}
+	}
+	return c.TLS.Validate()
 }
 
 // DefaultConfig returns the default configuration.
@@ -87,6 +144,12 @@ func (c *Config) Equal(other *Config) bool {
 	if c.Docker != other.Docker {
 		return false
 	}
+	if c.AuthToken != other.AuthToken || c.Auth != other.Auth {
+		return false
+	}
+	if c.TLS != other.TLS || c.Leader != other.Leader || c.Autoscale != other.Autoscale {
+		return false
+	}
 	if len(c.SiblingURLs) != len(other.SiblingURLs) {
 		return false
 	}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig configures TLS for the Sous server, and for Sous clients
+// (including servers talking to their siblings).
+type TLSConfig struct {
+	// CertFile and KeyFile name the PEM encoded certificate and private key
+	// that this instance presents: to its clients when serving, and to
+	// servers that verify client certificates when acting as a client. If
+	// CertFile is set, the server listens with TLS.
+	CertFile string `env:"SOUS_TLS_CERT_FILE"`
+	KeyFile  string `env:"SOUS_TLS_KEY_FILE"`
+	// CAFile is a PEM bundle of the certificate authorities trusted to sign
+	// server certificates. If it is empty, the system roots are used.
+	CAFile string `env:"SOUS_TLS_CA_FILE"`
+	// ClientCAFile is a PEM bundle of the certificate authorities trusted to
+	// sign client certificates. If it is set, the server requires every
+	// client to present a certificate signed by one of them.
+	ClientCAFile string `env:"SOUS_TLS_CLIENT_CA_FILE"`
+}
+
+// Validate returns an error if this TLSConfig is inconsistent.
+func (tc TLSConfig) Validate() error {
+	if (tc.CertFile == "") != (tc.KeyFile == "") {
+		return errors.Errorf("Config.TLS.CertFile and Config.TLS.KeyFile must be set together")
+	}
+	if tc.ClientCAFile != "" && tc.CertFile == "" {
+		return errors.Errorf("Config.TLS.ClientCAFile requires Config.TLS.CertFile")
+	}
+	return nil
+}
+
+// ServerConfig returns the *tls.Config a Sous server should listen with, or
+// nil if the server should not use TLS.
+func (tc TLSConfig) ServerConfig() (*tls.Config, error) {
+	if tc.CertFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading server certificate")
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if tc.ClientCAFile != "" {
+		pool, err := loadCertPool(tc.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientConfig returns the *tls.Config a Sous client should use to connect to
+// servers, or nil if the defaults are sufficient.
+func (tc TLSConfig) ClientConfig() (*tls.Config, error) {
+	if tc.CAFile == "" && tc.CertFile == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if tc.CAFile != "" {
+		pool, err := loadCertPool(tc.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if tc.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading CA bundle %q", path)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in CA bundle %q", path)
+	}
+	return pool, nil
+}
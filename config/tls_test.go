@@ -0,0 +1,149 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/lib"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// issue creates a certificate for cn, signed by parent (or self-signed if
+// parent is nil).
+func issue(t *testing.T, cn string, parent *testCert, serial int64) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write stores the certificate and key as PEM in dir, returning their paths.
+func (tc *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.der})
+	keyDER, err := x509.MarshalECPrivateKey(tc.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestTLSConfig_MutualAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := issue(t, "Sous Test CA", nil, 1)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCert, serverKey := issue(t, "sous-server", ca, 2).write(t, dir, "server")
+	clientCert, clientKey := issue(t, "sous-sibling", ca, 3).write(t, dir, "client")
+
+	serverTLS := TLSConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: caFile}
+	if err := serverTLS.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := serverTLS.ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Servers":[]}`))
+	}))
+	srv.TLS = sc
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(tc TLSConfig) error {
+		cc, err := tc.ClientConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl, err := sous.NewClient(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl.SetTLSConfig(cc)
+		data := struct{ Servers []interface{} }{}
+		return cl.Retrieve("./servers", nil, &data, sous.User{})
+	}
+
+	if err := get(TLSConfig{CAFile: caFile, CertFile: clientCert, KeyFile: clientKey}); err != nil {
+		t.Errorf("mutually authenticated request failed: %v", err)
+	}
+	if err := get(TLSConfig{CAFile: caFile}); err == nil {
+		t.Errorf("request without a client certificate succeeded")
+	}
+	if err := get(TLSConfig{CertFile: clientCert, KeyFile: clientKey}); err == nil {
+		t.Errorf("request without the CA bundle succeeded")
+	}
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	bad := []TLSConfig{
+		{CertFile: "cert.pem"},
+		{KeyFile: "key.pem"},
+		{ClientCAFile: "ca.pem"},
+	}
+	for _, tc := range bad {
+		if err := tc.Validate(); err == nil {
+			t.Errorf("%+v returns nil from Validate()", tc)
+		}
+	}
+	if err := (TLSConfig{}).Validate(); err != nil {
+		t.Errorf("empty TLSConfig is invalid: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"database/sql"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+// pushFileArtifact pushes the image held in the file artifact of type typ at
+// path into a registry, named by each of names, with labels added. It returns
+// the first name, referring to the image by digest.
+func pushFileArtifact(cl docker_registry.Client, typ, path string, labels map[string]string, names ...string) (string, error) {
+	switch typ {
+	default:
+		return "", errors.Errorf("can't push artifacts of type %q", typ)
+	case sous.ArtifactTypeOCILayout:
+		return cl.PushOCILayout(path, labels, names...)
+	case sous.ArtifactTypeDockerArchive:
+		return cl.PushArchive(path, labels, names...)
+	}
+}
+
+// InsertArtifact implements sous.ArtifactInserter on NameCache. It records
+// art as the artifact for sid. Docker images are recorded as by Insert;
+// artifacts of other types are recorded by type, and GetArtifact returns them
+// for sid only when no image has been recorded.
+func (nc *NameCache) InsertArtifact(sid sous.SourceID, art *sous.BuildArtifact) error {
+	if art.Type == "" || art.Type == sous.ArtifactTypeDocker {
+		return nc.Insert(sid, art.Name, "", art.Qualities)
+	}
+	if !sous.IsFileArtifactType(art.Type) {
+		return errors.Errorf("unknown artifact type %q", art.Type)
+	}
+	return nc.dbRecordArtifactFile(sid, art.Type, art.Name)
+}
+
+// fileArtifact returns the file artifact recorded for sid, preferring types
+// in the order of sous.ArtifactTypes, or nil if there's none.
+func (nc *NameCache) fileArtifact(sid sous.SourceID) (*sous.BuildArtifact, error) {
+	files, err := nc.dbQueryArtifactFiles(sid)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range sous.ArtifactTypes {
+		if name, ok := files[t]; ok {
+			return &sous.BuildArtifact{Name: name, Type: t}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (nc *NameCache) dbRecordArtifactFile(sid sous.SourceID, typ, name string) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+	id, err := nc.ensureInDB(
+		"select location_id from docker_search_location where repo = $1 and offset = $2",
+		"insert into docker_search_location (repo, offset) values ($1, $2);",
+		sid.Location.Repo, sid.Location.Dir)
+	if err != nil {
+		return err
+	}
+	_, err = nc.DB.Exec("insert into artifact_files "+
+		"(location_id, version, artifact_type, name) values ($1, $2, $3, $4)",
+		id, sid.Version.Format(semv.Complete), typ, name)
+	return errors.Wrapf(err, "recording %s %s for %v", typ, name, sid)
+}
+
+// dbQueryArtifactFiles returns the names of the file artifacts recorded for
+// sid, by type.
+func (nc *NameCache) dbQueryArtifactFiles(sid sous.SourceID) (map[string]string, error) {
+	rows, err := nc.DB.Query("select artifact_files.artifact_type, artifact_files.name "+
+		"from artifact_files natural join docker_search_location "+
+		"where "+
+		"docker_search_location.repo = $1 and "+
+		"docker_search_location.offset = $2 and "+
+		"semverEqual(artifact_files.version, $3)",
+		sid.Location.Repo, sid.Location.Dir, sid.Version.String())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking up artifacts for %v", sid)
+	}
+	defer rows.Close()
+	files := map[string]string{}
+	for rows.Next() {
+		var typ, name string
+		if err := rows.Scan(&typ, &name); err != nil {
+			return nil, errors.Wrapf(err, "looking up artifacts for %v", sid)
+		}
+		files[typ] = name
+	}
+	return files, errors.Wrapf(rows.Err(), "looking up artifacts for %v", sid)
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 	"text/template"
 
 	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
 	"github.com/opentable/sous/util/shell"
 )
 
@@ -19,6 +21,9 @@ type (
 		DockerRegistryHost        string
 		SourceShell, ScratchShell shell.Shell
 		Pack                      sous.Buildpack
+		// RegistryClient pushes artifacts built as files, rather than into
+		// the local Docker daemon, into the registry.
+		RegistryClient docker_registry.Client
 	}
 	// BuildTarget represents a single target within a Build.
 	BuildTarget interface {
@@ -60,6 +65,12 @@ func (b *Builder) info(msg string) {
 
 // Register registers the build artifact to the the registry
 func (b *Builder) Register(br *sous.BuildResult, bc *sous.BuildContext) error {
+	if sous.IsFileArtifactType(br.ArtifactType) {
+		if err := b.pushFileArtifact(br, bc); err != nil {
+			return err
+		}
+		return b.recordName(br, bc)
+	}
 	err := b.pushToRegistry(br, bc)
 	if err != nil {
 		return err
@@ -68,10 +79,19 @@ func (b *Builder) Register(br *sous.BuildResult, bc *sous.BuildContext) error {
 	return b.recordName(br, bc)
 }
 
-// ApplyMetadata applies container metadata etc. to a container
+// labelEscaper escapes label values for the double quotes they're written in
+// by the metadata Dockerfile.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// ApplyMetadata applies container metadata etc. to a container, including
+// labels recording the provenance of the build.
 func (b *Builder) ApplyMetadata(br *sous.BuildResult, bc *sous.BuildContext) error {
 	br.VersionName = b.VersionTag(bc.Version())
 	br.RevisionName = b.RevisionTag(bc.Version())
+	if sous.IsFileArtifactType(br.ArtifactType) {
+		// The labels are added to the image's config as it's pushed.
+		return nil
+	}
 
 	c := b.SourceShell.Cmd("docker", "build", "-t", br.VersionName, "-t", br.RevisionName, "-")
 	bf := b.metadataDockerfile(br, bc)
@@ -83,6 +103,10 @@ func (b *Builder) ApplyMetadata(br *sous.BuildResult, bc *sous.BuildContext) err
 func (b *Builder) metadataDockerfile(br *sous.BuildResult, bc *sous.BuildContext) io.Reader {
 	bf := bytes.Buffer{}
 	sv := bc.Version()
+	labels := Labels(sv)
+	for k, v := range ProvenanceLabels(br.Provenance) {
+		labels[k] = labelEscaper.Replace(v)
+	}
 	md := template.Must(template.New("metadata").Parse(metadataDockerfileTmpl))
 	md.Execute(&bf, struct {
 		ImageID    string
@@ -90,7 +114,7 @@ func (b *Builder) metadataDockerfile(br *sous.BuildResult, bc *sous.BuildContext
 		Advisories []string
 	}{
 		br.ImageID,
-		Labels(sv),
+		labels,
 		br.Advisories,
 	})
 	return &bf
@@ -109,6 +133,26 @@ func (b *Builder) pushToRegistry(br *sous.BuildResult, bc *sous.BuildContext) er
 	return verr
 }
 
+// pushFileArtifact pushes an image built as a file into the registry, with
+// the labels `docker build` would have applied to it.
+func (b *Builder) pushFileArtifact(br *sous.BuildResult, bc *sous.BuildContext) error {
+	if b.RegistryClient == nil {
+		return fmt.Errorf("can't push %s %s: no registry client", br.ArtifactType, br.ArtifactPath)
+	}
+	sv := bc.Version()
+	labels := Labels(sv)
+	for k, v := range ProvenanceLabels(br.Provenance) {
+		labels[k] = v
+	}
+	if len(br.Advisories) > 0 {
+		labels[DockerAdvisoriesLabel] = strings.Join(br.Advisories, ",")
+	}
+	b.SourceShell.ConsoleEcho(fmt.Sprintf("[pushing %s %s]", br.ArtifactType, br.ArtifactPath))
+	_, err := pushFileArtifact(b.RegistryClient, br.ArtifactType, br.ArtifactPath, labels,
+		b.VersionTag(sv), b.RevisionTag(sv))
+	return err
+}
+
 // recordName inserts metadata about the newly built image into our local name cache
 func (b *Builder) recordName(br *sous.BuildResult, bc *sous.BuildContext) error {
 	sv := bc.Version()
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/nyarly/testify/assert"
@@ -38,6 +39,29 @@ LABEL \
   com.opentable.sous.advisories="something is horribly wrong"`, string(mddf))
 }
 
+func TestMetadataDockerfileProvenance(t *testing.T) {
+	b := Builder{}
+	br := sous.BuildResult{
+		ImageID:    "identifier",
+		Provenance: &sous.Provenance{Buildpack: "dockerfile", Detected: `Dockerfile "quoted"`},
+	}
+	bc := sous.BuildContext{
+		Source: sous.SourceContext{RemoteURL: "github.com/opentable/test", NearestTagName: "2.3.7"},
+	}
+	mddf, err := ioutil.ReadAll(b.metadataDockerfile(&br, &bc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{
+		`com.opentable.sous.build.buildpack="dockerfile"`,
+		`com.opentable.sous.build.detected="Dockerfile \"quoted\""`,
+	} {
+		if !strings.Contains(string(mddf), label) {
+			t.Errorf("metadata Dockerfile should contain %s, was:\n%s", label, mddf)
+		}
+	}
+}
+
 func TestTagStrings(t *testing.T) {
 	assert := assert.New(t)
 
@@ -8,6 +8,19 @@ type Config struct {
 	// DatabaseConnection is the database connection string for local
 	// persistence.
 	DatabaseConnection string `env:"SOUS_DOCKER_DB_CONN"`
+	// HarvestIntervalSeconds is the time between harvests of the whole
+	// registry catalog by the server. If it is zero, the server doesn't
+	// harvest the catalog.
+	HarvestIntervalSeconds int `env:"SOUS_DOCKER_HARVEST_INTERVAL"`
+	// HarvestConcurrency is the number of repositories harvested at once.
+	HarvestConcurrency int `env:"SOUS_DOCKER_HARVEST_CONCURRENCY"`
+	// GCIntervalSeconds is the time between garbage collections of the
+	// registry by the server. If it is zero, the server doesn't collect
+	// garbage.
+	GCIntervalSeconds int `env:"SOUS_DOCKER_GC_INTERVAL"`
+	// GCRetainVersions is the number of versions older than the one
+	// deployed which garbage collection retains for each deployment.
+	GCRetainVersions int `env:"SOUS_DOCKER_GC_RETAIN"`
 }
 
 // DefaultConfig builds a default configuration, which can be then overridden by
@@ -17,6 +30,8 @@ func DefaultConfig() Config {
 		RegistryHost:       "docker.otenv.com",
 		DatabaseDriver:     "sqlite3_sous",
 		DatabaseConnection: InMemory,
+		HarvestConcurrency: 4,
+		GCRetainVersions:   3,
 	}
 }
 
@@ -5,4 +5,20 @@ const (
 	DockerPathLabel     = "com.opentable.sous.repo_offset"
 	DockerVersionLabel  = "com.opentable.sous.version"
 	DockerRevisionLabel = "com.opentable.sous.revision"
+	// DockerAdvisoriesLabel lists the advisories on an image, separated by
+	// commas.
+	DockerAdvisoriesLabel = "com.opentable.sous.advisories"
+)
+
+// Labels recording the provenance of images built by Sous.
+const (
+	DockerBuildUserLabel      = "com.opentable.sous.build.user"
+	DockerBuildHostLabel      = "com.opentable.sous.build.host"
+	DockerBuildpackLabel      = "com.opentable.sous.build.buildpack"
+	DockerBuildDetectedLabel  = "com.opentable.sous.build.detected"
+	DockerBaseImageLabel      = "com.opentable.sous.build.base_image"
+	DockerDockerfileHashLabel = "com.opentable.sous.build.dockerfile_hash"
+	DockerRemoteURLLabel      = "com.opentable.sous.build.remote_url"
+	DockerFullRevisionLabel   = "com.opentable.sous.build.full_revision"
+	DockerBuiltLabel          = "com.opentable.sous.build.timestamp"
 )
@@ -1,9 +1,11 @@
 package docker
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/opentable/sous/lib"
@@ -25,6 +27,7 @@ const (
 var (
 	appVersionPattern  = regexp.MustCompile(`(?m)^ARG ` + AppVersionBuildArg + `\b`)
 	appRevisionPattern = regexp.MustCompile(`(?m)^ARG ` + AppRevisionBuildArg + `\b`)
+	fromPattern        = regexp.MustCompile(`(?im)^FROM\s+(?:--\S+\s+)*(\S+)`)
 )
 
 // datectData is data passed from the detect step to the build step as the
@@ -80,9 +83,47 @@ func (d *DockerfileBuildpack) Build(c *sous.BuildContext, dr *sous.DetectResult)
 		ImageID:    match[1],
 		Elapsed:    time.Since(start),
 		Advisories: c.Advisories,
+		Provenance: d.provenance(c),
 	}, nil
 }
 
+// provenance records the Dockerfile built and the image it was built from.
+// The base image is named by digest if Docker knows it, since tags move.
+func (d *DockerfileBuildpack) provenance(c *sous.BuildContext) *sous.Provenance {
+	p := &sous.Provenance{Buildpack: "dockerfile"}
+	sh := c.Sh.Clone()
+	sh.LongRunning(false)
+	df, err := sh.Stdout("cat", filepath.Join(c.Source.OffsetDir, "Dockerfile"))
+	if err != nil {
+		Log.Debug.Printf("Reading Dockerfile for provenance: %v", err)
+		return p
+	}
+	p.DockerfileHash = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(df)))
+	p.BaseImage = baseImage(df)
+	if p.BaseImage == "" {
+		return p
+	}
+	digests, err := sh.Stdout("docker", "inspect", "--format", "{{range .RepoDigests}}{{.}} {{end}}", p.BaseImage)
+	if err != nil {
+		Log.Debug.Printf("Finding digest of base image %s: %v", p.BaseImage, err)
+		return p
+	}
+	if fs := strings.Fields(digests); len(fs) > 0 {
+		p.BaseImage = fs[0]
+	}
+	return p
+}
+
+// baseImage returns the image named by the last FROM instruction in df, which
+// is the base of the image it builds.
+func baseImage(df string) string {
+	froms := fromPattern.FindAllStringSubmatch(df, -1)
+	if len(froms) == 0 {
+		return ""
+	}
+	return froms[len(froms)-1][1]
+}
+
 // Detect detects if c has a Dockerfile or not.
 func (d *DockerfileBuildpack) Detect(c *sous.BuildContext) (*sous.DetectResult, error) {
 	dfPath := filepath.Join(c.Source.OffsetDir, "Dockerfile")
@@ -97,7 +138,11 @@ func (d *DockerfileBuildpack) Detect(c *sous.BuildContext) (*sous.DetectResult,
 	}
 	hasAppVersion := appVersionPattern.MatchString(df)
 	hasAppRevision := appRevisionPattern.MatchString(df)
-	result := &sous.DetectResult{Compatible: true, Data: detectData{
+	desc := fmt.Sprintf("Dockerfile %s", dfPath)
+	if base := baseImage(df); base != "" {
+		desc = fmt.Sprintf("%s, from %s", desc, base)
+	}
+	result := &sous.DetectResult{Compatible: true, Description: desc, Data: detectData{
 		HasAppVersionArg:  hasAppVersion,
 		HasAppRevisionArg: hasAppRevision,
 	}}
@@ -106,6 +106,19 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestBaseImage(t *testing.T) {
+	for df, want := range map[string]string{
+		"":                              "",
+		"FROM blah":                     "blah",
+		"from blah:1.2 AS build\nRUN x": "blah:1.2",
+		"FROM golang AS build\nFROM --platform=linux/amd64 alpine:3.5\n": "alpine:3.5",
+	} {
+		if got := baseImage(df); got != want {
+			t.Errorf("baseImage(%q) = %q; want %q", df, got, want)
+		}
+	}
+}
+
 func assertError(expectedErr string, actualErr error) error {
 	if actualErr == nil && expectedErr == "" {
 		return nil
@@ -0,0 +1,266 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// A GarbageCollector removes images which no deployment needs from the
+	// Docker registry and the NameCache. For each deployment in the GDM, it
+	// retains the image deployed, the images of newer versions, which may be
+	// about to be deployed, and the images of the Retain versions before the
+	// one deployed, which the deployment may be rolled back to. The images of
+	// source locations which aren't deployed at all are removed.
+	GarbageCollector struct {
+		*NameCache
+		// Retain is the number of older versions retained for each
+		// deployment.
+		Retain int
+		// Interval is the time between collections started by Kickoff. If it
+		// is zero, Kickoff does nothing.
+		Interval time.Duration
+		// Leadership, if not nil, decides whether this server is the leader,
+		// and so whether Kickoff should collect garbage at all.
+		Leadership *sous.Leadership
+		report     GCReport
+		sync.RWMutex
+	}
+
+	// A GCReport describes the images a garbage collection retained and
+	// removed.
+	GCReport struct {
+		// DryRun is true if the removed images were only reported, and are
+		// still in the registry and the NameCache.
+		DryRun bool
+		// Started and Finished are when the collection started and finished.
+		Started, Finished time.Time
+		// Retained and Removed list the images retained and removed.
+		Retained, Removed []GCImage
+		// Errors lists the images which could not be removed, and why.
+		Errors []string
+	}
+
+	// A GCImage is an image known to the NameCache.
+	GCImage struct {
+		SourceID sous.SourceID
+		// Name is the canonical name of the image.
+		Name string
+		// Digest is the digest of the image, if known.
+		Digest string `json:",omitempty"`
+	}
+
+	gcImages    []GCImage
+	newestFirst []semv.Version
+)
+
+func (is gcImages) Len() int           { return len(is) }
+func (is gcImages) Swap(i, j int)      { is[i], is[j] = is[j], is[i] }
+func (is gcImages) Less(i, j int) bool { return is[i].SourceID.String() < is[j].SourceID.String() }
+
+func (vs newestFirst) Len() int           { return len(vs) }
+func (vs newestFirst) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+func (vs newestFirst) Less(i, j int) bool { return vs[j].Less(vs[i]) }
+
+// NewGarbageCollector creates a GarbageCollector for the images in nc.
+func NewGarbageCollector(nc *NameCache, retain int, interval time.Duration) *GarbageCollector {
+	return &GarbageCollector{
+		NameCache: nc,
+		Retain:    retain,
+		Interval:  interval,
+	}
+}
+
+// Report returns the report of the last collection started by Kickoff.
+func (gc *GarbageCollector) Report() GCReport {
+	gc.RLock()
+	defer gc.RUnlock()
+	return gc.report
+}
+
+// Kickoff collects garbage every Interval, using the GDM returned by gdm,
+// until the returned channel is closed.
+func (gc *GarbageCollector) Kickoff(gdm func() (sous.Deployments, error)) sous.TriggerChannel {
+	done := make(sous.TriggerChannel)
+	if gc.Interval <= 0 {
+		return done
+	}
+	go func() {
+		for {
+			if !gc.Leadership.IsLeader() {
+				Log.Debug.Printf("Not collecting garbage: %q is the leader", gc.Leadership.Leader().Holder)
+			} else if err := gc.collectGDM(gdm); err != nil {
+				Log.Warn.Printf("Collecting garbage in %s: %v", gc.DockerRegistryHost, err)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(gc.Interval):
+			}
+		}
+	}()
+	return done
+}
+
+func (gc *GarbageCollector) collectGDM(gdm func() (sous.Deployments, error)) error {
+	ds, err := gdm()
+	if err != nil {
+		return errors.Wrap(err, "reading GDM")
+	}
+	report, err := gc.Collect(ds, false)
+	gc.Lock()
+	gc.report = report
+	gc.Unlock()
+	return err
+}
+
+// Collect removes the images gdm doesn't need from the registry and the
+// NameCache, and reports what it removed. If dryRun is true, it only reports
+// what it would remove. Failures to remove individual images are recorded in
+// the report, rather than returned, and those images are left in the
+// NameCache. Images recorded without a digest have it resolved from the
+// registry first, so that no manifest a retained image uses is deleted; if
+// that fails for any retained image, nothing is removed, and Collect returns
+// an error.
+func (gc *GarbageCollector) Collect(gdm sous.Deployments, dryRun bool) (GCReport, error) {
+	report := GCReport{DryRun: dryRun, Started: time.Now()}
+	defer func() { report.Finished = time.Now() }()
+
+	images, err := gc.dbQueryAllImages()
+	if err != nil {
+		return report, err
+	}
+	sort.Sort(gcImages(images))
+
+	retained := gc.retained(gdm, images)
+	retainedDigests := map[string]struct{}{}
+	for _, im := range images {
+		if _, ok := retained[im.SourceID.String()]; ok {
+			report.Retained = append(report.Retained, im)
+			digest, err := gc.resolveDigest(im)
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			retainedDigests[digest] = struct{}{}
+		}
+	}
+	if len(report.Errors) > 0 {
+		// Any image removed might share the manifest of a retained image
+		// whose digest is unknown.
+		return report, errors.Errorf("no images removed: the digests of %d retained images couldn't be resolved", len(report.Errors))
+	}
+
+	for _, im := range images {
+		if _, ok := retained[im.SourceID.String()]; ok {
+			continue
+		}
+		digest, err := gc.resolveDigest(im)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error()+", so it was not removed")
+			continue
+		}
+		im.Digest = digest
+		if !dryRun {
+			if err := gc.remove(im, retainedDigests); err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+		}
+		report.Removed = append(report.Removed, im)
+	}
+	return report, nil
+}
+
+// resolveDigest returns the digest of im, asking the registry for it if the
+// NameCache doesn't have it.
+func (gc *GarbageCollector) resolveDigest(im GCImage) (string, error) {
+	if im.Digest != "" {
+		return im.Digest, nil
+	}
+	md, err := gc.RegistryClient.GetImageMetadata(im.Name, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving the digest of %s", im.Name)
+	}
+	digest := digestOf(md)
+	if digest == "" {
+		return "", errors.Errorf("resolving the digest of %s: the registry didn't supply one", im.Name)
+	}
+	return digest, nil
+}
+
+// remove deletes im, and its copies in other registries, from the registries,
+// unless a retained image shares its manifest, and then from the NameCache.
+// im.Digest must be resolved.
+func (gc *GarbageCollector) remove(im GCImage, retainedDigests map[string]struct{}) error {
+	if _, shared := retainedDigests[im.Digest]; !shared {
+		copies, err := gc.dbQueryCopies(im.Name)
+		if err != nil {
+			return err
+		}
+		for _, n := range append([]string{pinnedName(im.Name, im.Digest)}, copies...) {
+			if err := gc.RegistryClient.DeleteImage(n); err != nil {
+				return errors.Wrapf(err, "deleting %s from its registry", n)
+			}
+		}
+	}
+	return gc.dbDeleteImage(im.Name)
+}
+
+// retained returns the SourceIDs, as strings, of the images gdm needs.
+func (gc *GarbageCollector) retained(gdm sous.Deployments, images []GCImage) map[string]struct{} {
+	versions := map[sous.SourceLocation][]semv.Version{}
+	for _, im := range images {
+		loc := im.SourceID.Location
+		versions[loc] = append(versions[loc], im.SourceID.Version)
+	}
+	for _, vs := range versions {
+		sort.Sort(newestFirst(vs))
+	}
+
+	retained := map[string]struct{}{}
+	for _, d := range gdm.Snapshot() {
+		loc := d.SourceID.Location
+		older := 0
+		for _, v := range versions[loc] {
+			if v.Less(d.SourceID.Version) {
+				if older >= gc.Retain {
+					break
+				}
+				older++
+			}
+			retained[sous.SourceID{Location: loc, Version: v}.String()] = struct{}{}
+		}
+	}
+	return retained
+}
+
+// AsTable writes the images the report removed, and why any couldn't be
+// removed, to a Writer.
+func (r GCReport) AsTable(to io.Writer) {
+	w := &tabwriter.Writer{}
+	w.Init(to, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Repo\tOffset\tVersion\tName")
+	for _, im := range r.Removed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", im.SourceID.Location.Repo, im.SourceID.Location.Dir,
+			im.SourceID.Version.Format(semv.MajorMinorPatch), pinnedName(im.Name, im.Digest))
+	}
+	w.Flush()
+
+	verb := "Removed"
+	if r.DryRun {
+		verb = "Would remove"
+	}
+	fmt.Fprintf(to, "%s %d images, retaining %d.\n", verb, len(r.Removed), len(r.Retained))
+	for _, e := range r.Errors {
+		fmt.Fprintf(to, "Error: %s\n", e)
+	}
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+)
+
+func TestGarbageCollector(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("gc"))
+
+	digest := func(n byte) string {
+		return "sha256:" + string([]byte{'0' + n}) + "12345678901234567890123456789ab012345678901234567890123456789ab"
+	}
+	versions := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}
+	for i, v := range versions {
+		d := digest(byte(i))
+		if v == "1.1.0" {
+			d = digest(2) // 1.1.0 was tagged again as 1.2.0
+		}
+		dc.AddMetadata(`wackadoo:`+v+`$`, docker_registry.Metadata{Registry: host, Digest: d})
+		sid := sous.MustNewSourceID("github.com/opentable/wackadoo", "", v)
+		assert.NoError(nc.Insert(sid, host+"/wackadoo:"+v, "", nil))
+	}
+	dc.AddMetadata(`other:`, docker_registry.Metadata{Registry: host, Digest: digest(9)})
+	other := sous.MustNewSourceID("github.com/opentable/other", "", "2.0.0")
+	assert.NoError(nc.Insert(other, host+"/other:2.0.0", "", nil))
+
+	gdm := sous.NewDeployments(&sous.Deployment{
+		ClusterName: "cluster-1",
+		SourceID:    sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.3.0"),
+	})
+	gc := NewGarbageCollector(nc, 1, 0)
+
+	names := func(ims []GCImage) (ns []string) {
+		for _, im := range ims {
+			ns = append(ns, im.Name)
+		}
+		return
+	}
+
+	report, err := gc.Collect(gdm, true)
+	assert.NoError(err)
+	assert.True(report.DryRun)
+	assert.Equal([]string{
+		host + "/other:2.0.0",
+		host + "/wackadoo:1.0.0",
+		host + "/wackadoo:1.1.0",
+	}, names(report.Removed))
+	assert.Equal([]string{
+		host + "/wackadoo:1.2.0",
+		host + "/wackadoo:1.3.0",
+		host + "/wackadoo:1.4.0",
+		host + "/wackadoo:1.5.0",
+	}, names(report.Retained))
+	assert.Empty(dc.Deleted(), "a dry run should not delete images")
+	_, err = nc.GetArtifact(other)
+	assert.NoError(err, "a dry run should not remove images from the name cache")
+
+	report, err = gc.Collect(gdm, false)
+	assert.NoError(err)
+	assert.Len(report.Removed, 3)
+	assert.Empty(report.Errors)
+	assert.Equal([]string{
+		host + "/other@" + digest(9),
+		host + "/wackadoo@" + digest(0),
+	}, dc.Deleted(), "images sharing a manifest with a retained image should stay in the registry")
+
+	_, err = nc.GetArtifact(other)
+	assert.Error(err)
+	_, err = nc.GetArtifact(sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.1.0"))
+	assert.Error(err)
+	_, err = nc.GetArtifact(sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.2.0"))
+	assert.NoError(err)
+}
+
+func TestGarbageCollector_ResolvesDigests(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("gc_digests"))
+
+	digest := "sha256:012345678901234567890123456789ab012345678901234567890123456789ab"
+	kept := sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.1.0")
+	dc.AddMetadata(`wackadoo:1.1.0$`, docker_registry.Metadata{Registry: host, Digest: digest})
+	assert.NoError(nc.Insert(kept, host+"/wackadoo:1.1.0", "", nil))
+
+	// Neither image's digest is known when it's recorded.
+	retagged := sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.0.0")
+	assert.NoError(nc.Insert(retagged, host+"/wackadoo:1.0.0", "", nil))
+	lost := sous.MustNewSourceID("github.com/opentable/lost", "", "1.0.0")
+	assert.NoError(nc.Insert(lost, host+"/lost:1.0.0", "", nil))
+	dc.AddMetadata(`wackadoo:1.0.0$`, docker_registry.Metadata{Registry: host, Digest: digest})
+
+	gdm := sous.NewDeployments(&sous.Deployment{ClusterName: "cluster-1", SourceID: kept})
+	report, err := NewGarbageCollector(nc, 0, 0).Collect(gdm, false)
+	assert.NoError(err)
+
+	assert.Len(report.Removed, 1)
+	assert.Len(report.Errors, 1, "an image whose digest can't be resolved should be skipped")
+	assert.Empty(dc.Deleted(), "an image sharing a retained image's manifest should stay in the registry")
+	_, err = nc.GetArtifact(retagged)
+	assert.Error(err)
+	_, err = nc.GetArtifact(lost)
+	assert.NoError(err)
+}
+
+func TestGarbageCollector_UnresolvedRetainedDigest(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("gc_unresolved"))
+
+	digest := "sha256:012345678901234567890123456789ab012345678901234567890123456789ab"
+	old := sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.0.0")
+	dc.AddMetadata(`wackadoo:1.0.0$`, docker_registry.Metadata{Registry: host, Digest: digest})
+	assert.NoError(nc.Insert(old, host+"/wackadoo:1.0.0", "", nil))
+
+	// The retained image's digest is neither recorded nor in the registry,
+	// so it might share the old image's manifest.
+	kept := sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.1.0")
+	assert.NoError(nc.Insert(kept, host+"/wackadoo:1.1.0", "", nil))
+
+	gdm := sous.NewDeployments(&sous.Deployment{ClusterName: "cluster-1", SourceID: kept})
+	report, err := NewGarbageCollector(nc, 0, 0).Collect(gdm, false)
+	assert.Error(err)
+
+	assert.Empty(report.Removed)
+	assert.Len(report.Errors, 1)
+	assert.Empty(dc.Deleted(), "no image should be deleted while a retained digest is unknown")
+	_, err = nc.GetArtifact(old)
+	assert.NoError(err)
+}
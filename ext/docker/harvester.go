@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Harvester periodically crawls the catalog of the Docker registry and
+	// warms up the NameCache with every repository in it, so that images
+	// pushed without `sous build` are known to the resolver. Images which
+	// haven't changed since they were cached are skipped, according to their
+	// etags.
+	Harvester struct {
+		*NameCache
+		// Interval is the time between the start of one harvest and the
+		// next. If it is zero, Kickoff does nothing.
+		Interval time.Duration
+		// Concurrency is the number of repositories warmed up at once.
+		Concurrency int
+		status      HarvestStatus
+		sync.RWMutex
+	}
+
+	// HarvestStatus describes the progress of the harvest underway, or the
+	// results of the last one.
+	HarvestStatus struct {
+		// Running is true while a harvest is underway.
+		Running bool
+		// Started and Finished are when the harvest started and finished.
+		Started, Finished time.Time
+		// Repos is the number of repositories in the registry catalog, and
+		// Harvested the number which have been warmed up so far.
+		Repos, Harvested int
+		// Images is the number of tagged images found so far, and Unchanged
+		// the number of those which hadn't changed since they were cached.
+		Images, Unchanged int
+		// Errors lists the repositories which could not be harvested, and
+		// why.
+		Errors []string
+	}
+)
+
+// NewHarvester creates a Harvester warming up nc.
+func NewHarvester(nc *NameCache, interval time.Duration, concurrency int) *Harvester {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Harvester{
+		NameCache:   nc,
+		Interval:    interval,
+		Concurrency: concurrency,
+	}
+}
+
+// Status returns the status of the current or last harvest.
+func (h *Harvester) Status() HarvestStatus {
+	h.RLock()
+	defer h.RUnlock()
+	st := h.status
+	st.Errors = append([]string{}, h.status.Errors...)
+	return st
+}
+
+// Kickoff starts harvesting every Interval, until the returned channel is
+// closed.
+func (h *Harvester) Kickoff() sous.TriggerChannel {
+	done := make(sous.TriggerChannel)
+	if h.Interval <= 0 {
+		return done
+	}
+	go func() {
+		for {
+			if err := h.Harvest(); err != nil {
+				Log.Warn.Printf("Harvesting %s: %v", h.DockerRegistryHost, err)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(h.Interval):
+			}
+		}
+	}()
+	return done
+}
+
+// Harvest warms up the cache with every repository in the registry catalog,
+// Concurrency repositories at a time. Failures to harvest individual
+// repositories are recorded in the status, rather than returned.
+func (h *Harvester) Harvest() error {
+	if !h.begin() {
+		return errors.Errorf("a harvest is already running")
+	}
+	defer h.update(func(st *HarvestStatus) {
+		st.Running = false
+		st.Finished = time.Now()
+	})
+
+	repos, err := h.RegistryClient.Repositories(h.DockerRegistryHost)
+	if err != nil {
+		err = errors.Wrap(err, "listing registry catalog")
+		h.update(func(st *HarvestStatus) { st.Errors = append(st.Errors, err.Error()) })
+		return err
+	}
+	h.update(func(st *HarvestStatus) { st.Repos = len(repos) })
+
+	work := make(chan string)
+	wg := sync.WaitGroup{}
+	wg.Add(h.Concurrency)
+	for i := 0; i < h.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for r := range work {
+				h.harvestRepo(r)
+			}
+		}()
+	}
+	for _, r := range repos {
+		work <- r
+	}
+	close(work)
+	wg.Wait()
+	return nil
+}
+
+func (h *Harvester) harvestRepo(r string) {
+	name := h.DockerRegistryHost + "/" + r
+	tags, unchanged, err := h.warmup(name)
+	h.update(func(st *HarvestStatus) {
+		st.Harvested++
+		st.Images += tags
+		st.Unchanged += unchanged
+		if err != nil {
+			st.Errors = append(st.Errors, errors.Wrap(err, name).Error())
+		}
+	})
+}
+
+// begin resets the status for a new harvest, returning false if one is
+// already running.
+func (h *Harvester) begin() bool {
+	h.Lock()
+	defer h.Unlock()
+	if h.status.Running {
+		return false
+	}
+	h.status = HarvestStatus{Running: true, Started: time.Now()}
+	return true
+}
+
+func (h *Harvester) update(f func(*HarvestStatus)) {
+	h.Lock()
+	defer h.Unlock()
+	f(&h.status)
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+)
+
+// etagClient responds to requests for metadata with an up to date etag as
+// a registry would: with ErrManifestNotModified.
+type etagClient struct {
+	*docker_registry.DummyRegistryClient
+}
+
+func (ec etagClient) GetImageMetadata(in, etag string) (docker_registry.Metadata, error) {
+	md, err := ec.DummyRegistryClient.GetImageMetadata(in, etag)
+	if err == nil && etag != "" && etag == md.Etag {
+		return docker_registry.Metadata{}, distribution.ErrManifestNotModified
+	}
+	return md, err
+}
+
+func TestHarvester_Harvest(t *testing.T) {
+	assert := assert.New(t)
+
+	host := "docker.repo.io"
+	dc := docker_registry.NewDummyClient()
+	nc := NewNameCache(host, etagClient{dc}, inMemoryDB("harvester"))
+	h := NewHarvester(nc, 0, 2)
+
+	repos := []string{"ot/one", "ot/two", "ot/three"}
+	dc.FeedRepositories(repos)
+	dc.FeedTags([]string{"1.0.0"})
+	for _, r := range repos {
+		sid := sous.MustNewSourceID("github.com/opentable/"+r[3:], "", "1.0.0")
+		sum := sha256.Sum256([]byte(r))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		cn := r + "@" + digest
+		dc.AddMetadata(r, docker_registry.Metadata{
+			Registry:      host,
+			Labels:        Labels(sid),
+			Etag:          digest,
+			CanonicalName: cn,
+			AllNames:      []string{cn, r + ":1.0.0"},
+		})
+	}
+
+	assert.NoError(h.Harvest())
+	st := h.Status()
+	assert.False(st.Running)
+	assert.Equal(3, st.Repos)
+	assert.Equal(3, st.Harvested)
+	assert.Equal(3, st.Images)
+	assert.Equal(0, st.Unchanged)
+	assert.Empty(st.Errors)
+
+	sid := sous.MustNewSourceID("github.com/opentable/two", "", "1.0.0")
+	art, err := nc.GetArtifact(sid)
+	if assert.NoError(err) {
+		assert.Regexp(`^docker\.repo\.io/ot/two@sha256:`, art.Name)
+	}
+
+	assert.NoError(h.Harvest())
+	st = h.Status()
+	assert.Equal(3, st.Images)
+	assert.Equal(3, st.Unchanged, "unchanged images should be skipped by etag")
+}
+
+func TestHarvester_Errors(t *testing.T) {
+	dc := docker_registry.NewDummyClient()
+	nc := NewNameCache("docker.repo.io", dc, inMemoryDB("harvester_errors"))
+	h := NewHarvester(nc, 0, 1)
+	dc.FeedRepositories([]string{"not a valid repo name"})
+
+	if err := h.Harvest(); err != nil {
+		t.Fatal(err)
+	}
+	st := h.Status()
+	if len(st.Errors) != 1 {
+		t.Errorf("got errors %q, want 1", st.Errors)
+	}
+	if st.Harvested != 1 {
+		t.Errorf("got %d harvested, want 1", st.Harvested)
+	}
+}
@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/opentable/sous/lib"
+)
+
+// ImageFileBuildpack is a buildpack for projects whose image is built by
+// some other tool, like buildah or bazel, as a file: an OCI image layout
+// directory named image.oci, or a `docker save` tarball named image.tar. It
+// builds nothing; the Builder pushes the file into the registry as it
+// registers it, so only the image in the registry is ever deployed.
+type ImageFileBuildpack struct{}
+
+// imageFiles lists the files ImageFileBuildpack looks for, in order, and the
+// types of artifact they are.
+var imageFiles = []struct{ name, artifactType string }{
+	{"image.oci", sous.ArtifactTypeOCILayout},
+	{"image.tar", sous.ArtifactTypeDockerArchive},
+}
+
+// imageFile is the data passed from the detect step to the build step as the
+// Data field in the DetectResult.
+type imageFile struct {
+	// Path is the absolute path of the image file.
+	Path string
+	// ArtifactType is the type of artifact it is.
+	ArtifactType string
+}
+
+// NewImageFileBuildpack creates an image file buildpack.
+func NewImageFileBuildpack() *ImageFileBuildpack {
+	return &ImageFileBuildpack{}
+}
+
+// Detect detects if c has an image file or not. An OCI image layout must
+// have its oci-layout file.
+func (ifb *ImageFileBuildpack) Detect(c *sous.BuildContext) (*sous.DetectResult, error) {
+	for _, f := range imageFiles {
+		path := filepath.Join(c.Source.OffsetDir, f.name)
+		marker := path
+		if f.artifactType == sous.ArtifactTypeOCILayout {
+			marker = filepath.Join(path, "oci-layout")
+		}
+		if !c.Sh.Exists(marker) {
+			continue
+		}
+		return &sous.DetectResult{
+			Compatible:  true,
+			Description: fmt.Sprintf("%s %s", f.artifactType, path),
+			Data:        imageFile{Path: c.Sh.Abs(path), ArtifactType: f.artifactType},
+		}, nil
+	}
+	return nil, fmt.Errorf("neither %s nor %s exists",
+		filepath.Join(c.Source.OffsetDir, imageFiles[0].name), filepath.Join(c.Source.OffsetDir, imageFiles[1].name))
+}
+
+// Build implements Buildpack.Build. It returns the image file detected as the
+// artifact built.
+func (ifb *ImageFileBuildpack) Build(c *sous.BuildContext, dr *sous.DetectResult) (*sous.BuildResult, error) {
+	f := dr.Data.(imageFile)
+	return &sous.BuildResult{
+		ArtifactType: f.ArtifactType,
+		ArtifactPath: f.Path,
+		Advisories:   c.Advisories,
+		Provenance:   &sous.Provenance{Buildpack: "image-file"},
+	}, nil
+}
+
+// SelectBuildpack returns the ImageFileBuildpack if c has an image file, and
+// otherwise the DockerfileBuildpack.
+func SelectBuildpack(c *sous.BuildContext) (sous.Buildpack, error) {
+	if _, err := NewImageFileBuildpack().Detect(c); err == nil {
+		return NewImageFileBuildpack(), nil
+	}
+	return NewDockerfileBuildpack(), nil
+}
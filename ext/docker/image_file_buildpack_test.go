@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/shell"
+)
+
+func TestImageFileBuildpack(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "sous-image-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sh, err := shell.DefaultInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &sous.BuildContext{Sh: sh, Source: sous.SourceContext{}}
+
+	_, err = NewImageFileBuildpack().Detect(c)
+	assert.Error(err)
+	bp, err := SelectBuildpack(c)
+	assert.NoError(err)
+	assert.IsType(&DockerfileBuildpack{}, bp)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "image.tar"), nil, 0644))
+	dr, err := NewImageFileBuildpack().Detect(c)
+	if assert.NoError(err) {
+		br, err := NewImageFileBuildpack().Build(c, dr)
+		assert.NoError(err)
+		assert.Equal(sous.ArtifactTypeDockerArchive, br.ArtifactType)
+		assert.Equal(filepath.Join(dir, "image.tar"), br.ArtifactPath)
+	}
+
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "image.oci"), 0755))
+	_, err = NewImageFileBuildpack().Detect(c)
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "image.oci", "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644))
+	dr, err = NewImageFileBuildpack().Detect(c)
+	if assert.NoError(err) {
+		assert.Equal(sous.ArtifactTypeOCILayout, dr.Data.(imageFile).ArtifactType, "an OCI image layout should be preferred")
+	}
+	bp, err = SelectBuildpack(c)
+	assert.NoError(err)
+	assert.IsType(&ImageFileBuildpack{}, bp)
+}
@@ -30,6 +30,8 @@ type (
 		RegistryClient     docker_registry.Client
 		DB                 *sql.DB
 		DockerRegistryHost string
+		// writes serializes updates to DB, which span several statements.
+		writes sync.Mutex
 	}
 
 	imageName string
@@ -103,24 +105,34 @@ func (nc *NameCache) ListSourceIDs() ([]sous.SourceID, error) {
 
 // Warmup warms up the cache.
 func (nc *NameCache) Warmup(r string) error {
+	_, _, err := nc.warmup(r)
+	return err
+}
+
+// warmup pulls every tag of the repository r into the cache, returning the
+// number of tags found, and how many of those the registry reported as
+// unchanged since they were cached.
+func (nc *NameCache) warmup(r string) (tags, unchanged int, err error) {
 	ref, err := reference.ParseNamed(r)
 	if err != nil {
-		return errors.Errorf("%v for %v", err, r)
+		return 0, 0, errors.Errorf("%v for %v", err, r)
 	}
 	ts, err := nc.RegistryClient.AllTags(r)
 	if err != nil {
-		return errors.Wrap(err, "warming up")
+		return 0, 0, errors.Wrap(err, "warming up")
 	}
 	for _, t := range ts {
 		Log.Debug.Printf("Harvested tag: %v for repo: %v", t, r)
 		in, err := reference.WithTag(ref, t)
 		Log.Vomit.Print(in, err)
 		if err == nil {
-			a := NewBuildArtifact(in.String(), strpairs{})
-			nc.GetSourceID(a) //pull it into the cache...
+			tags++
+			if _, same, _ := nc.lookupSourceID(in.String()); same {
+				unchanged++
+			}
 		}
 	}
-	return nil
+	return tags, unchanged, nil
 }
 
 // ImageLabels gets the labels for an image name.
@@ -134,13 +146,69 @@ func (nc *NameCache) ImageLabels(in string) (map[string]string, error) {
 	return Labels(sv), nil
 }
 
-// GetArtifact implements sous.Registry.GetArtifact.
+// GetArtifact implements sous.Registry.GetArtifact. The artifact is named by
+// the digest first recorded for its image, where that is known, so that
+// pushing the image's tag again doesn't change what's deployed. If the tag
+// has been pushed again, GetArtifact returns a *sous.TagMovedError. If no
+// image can be found for sid, but a file artifact has been recorded for it,
+// that is returned instead; other errors finding the image are returned.
 func (nc *NameCache) GetArtifact(sid sous.SourceID) (*sous.BuildArtifact, error) {
 	name, qls, err := nc.getImageName(sid)
 	if err != nil {
+		if _, notFound := errors.Cause(err).(NoImageNameFound); !notFound {
+			return nil, err
+		}
+		file, ferr := nc.fileArtifact(sid)
+		if ferr != nil {
+			return nil, ferr
+		}
+		if file != nil {
+			return file, nil
+		}
 		return nil, err
 	}
-	return NewBuildArtifact(name, qls), nil
+	digest, moved, err := nc.dbQueryDigests(name)
+	if err != nil {
+		return nil, err
+	}
+	if moved != "" {
+		return nil, &sous.TagMovedError{SourceID: sid, Image: name, Recorded: digest, Current: moved}
+	}
+	return NewBuildArtifact(pinnedName(name, digest), qls), nil
+}
+
+// pinnedName returns the image name in, referring to the image by digest
+// rather than tag, if the digest is known.
+func pinnedName(in, digest string) string {
+	if digest == "" || digestOfName(in) != "" {
+		return in
+	}
+	ref, err := reference.ParseNamed(in)
+	if err != nil {
+		return in
+	}
+	return ref.Name() + "@" + digest
+}
+
+// digestOfName returns the digest of the image named in, if it is named by
+// digest. Otherwise it returns the empty string.
+func digestOfName(in string) string {
+	ref, err := reference.ParseNamed(in)
+	if err != nil {
+		return ""
+	}
+	if d, ok := ref.(reference.Digested); ok {
+		return d.Digest().String()
+	}
+	return ""
+}
+
+// digestOf returns the digest of the image described by md.
+func digestOf(md docker_registry.Metadata) string {
+	if md.Digest != "" {
+		return md.Digest
+	}
+	return digestOfName(md.CanonicalName)
 }
 
 func meansBodyUnchanged(err error) bool {
@@ -150,8 +218,15 @@ func meansBodyUnchanged(err error) bool {
 
 // GetSourceID looks up the source ID for a given image name.
 func (nc *NameCache) GetSourceID(a *sous.BuildArtifact) (sous.SourceID, error) {
-	in := a.Name
-	var sid sous.SourceID
+	sid, _, err := nc.lookupSourceID(a.Name)
+	return sid, err
+}
+
+// lookupSourceID looks up the source ID for the image named in, updating the
+// cache from the registry. The registry is asked for the image conditionally
+// on the etag already cached for it, and unchanged is true if it reports that
+// the image has not changed.
+func (nc *NameCache) lookupSourceID(in string) (sid sous.SourceID, unchanged bool, err error) {
 
 	Log.Vomit.Printf("Getting source ID for %s", in)
 
@@ -160,13 +235,13 @@ func (nc *NameCache) GetSourceID(a *sous.BuildArtifact) (sous.SourceID, error) {
 		Log.Vomit.Print(nif)
 	} else if err != nil {
 		Log.Vomit.Print("Err: ", err)
-		return sous.SourceID{}, err
+		return sous.SourceID{}, false, err
 	} else {
 		Log.Vomit.Printf("Found: %v %v %v %v", repo, offset, version, etag)
 
 		sid, err = sous.NewSourceID(repo, offset, version)
 		if err != nil {
-			return sid, err
+			return sid, false, err
 		}
 	}
 
@@ -174,19 +249,29 @@ func (nc *NameCache) GetSourceID(a *sous.BuildArtifact) (sous.SourceID, error) {
 	Log.Vomit.Printf("%+ v %v %T %#v", md, err, err, err)
 	if meansBodyUnchanged(err) {
 		Log.Debug.Printf("Image name: %s -> Source ID: %v", in, sid)
-		return sid, nil
+		return sid, true, nil
 	}
 	if err != nil {
-		return sid, err
+		return sid, false, err
 	}
 
 	newSID, err := SourceIDFromLabels(md.Labels)
 	if err != nil {
-		return sid, err
+		return sid, false, err
 	}
 
 	qualities := qualitiesFromLabels(md.Labels)
 
+	digest := digestOf(md)
+	moved, err := nc.dbRecordMovedTag(newSID, digest)
+	if err != nil {
+		return sid, false, err
+	}
+	if moved != nil {
+		Log.Warn.Print(moved)
+		return newSID, false, nil
+	}
+
 	fullCanon := nc.DockerRegistryHost + "/" + md.CanonicalName
 	mirrored := false
 	if md.Registry != nc.DockerRegistryHost {
@@ -199,9 +284,12 @@ func (nc *NameCache) GetSourceID(a *sous.BuildArtifact) (sous.SourceID, error) {
 	}
 
 	Log.Vomit.Printf("Recording %q (with etag: %s) as canonical for %v", fullCanon, md.Etag, newSID)
-	err = nc.dbInsert(newSID, fullCanon, md.Etag, qualities)
+	err = nc.dbInsert(newSID, fullCanon, md.Etag, digest, qualities)
 	if err != nil {
-		return sid, err
+		return sid, false, err
+	}
+	if err := nc.recordProvenance(fullCanon, md.Labels); err != nil {
+		return sid, false, err
 	}
 
 	names := []string{}
@@ -216,7 +304,7 @@ func (nc *NameCache) GetSourceID(a *sous.BuildArtifact) (sous.SourceID, error) {
 	}
 
 	Log.Debug.Printf("Image name: %s -> (updated) Source ID: %v", in, newSID)
-	return newSID, err
+	return newSID, false, err
 }
 
 // GetImageName returns the docker image name for a given source ID
@@ -255,7 +343,7 @@ func (nc *NameCache) getImageNameAfterHarvest(sid sous.SourceID) (string, strpai
 }
 
 func qualitiesFromLabels(lm map[string]string) []sous.Quality {
-	advs, ok := lm[`com.opentable.sous.advisories`]
+	advs, ok := lm[DockerAdvisoriesLabel]
 	if !ok {
 		return []sous.Quality{}
 	}
@@ -274,9 +362,67 @@ func (nc *NameCache) GetCanonicalName(in string) (string, error) {
 }
 
 // Insert puts a given SourceID/image name pair into the name cache
-// used by Builder at the moment to register after a build
+// used by Builder at the moment to register after a build. The digest and
+// provenance of the image are recorded too, if the registry can supply
+// them. If a different image has already been recorded for sid, Insert
+// returns a *sous.TagMovedError. If in is empty, qs are attached to the
+// image already recorded for sid.
 func (nc *NameCache) Insert(sid sous.SourceID, in, etag string, qs []sous.Quality) error {
-	return nc.dbInsert(sid, in, etag, qs)
+	if in == "" {
+		return nc.dbAddQualities(sid, qs)
+	}
+	digest := digestOfName(in)
+	var labels map[string]string
+	if digest == "" {
+		md, err := nc.RegistryClient.GetImageMetadata(in, "")
+		if err != nil {
+			Log.Debug.Printf("Recording %q without a digest: %v", in, err)
+		}
+		digest = digestOf(md)
+		labels = md.Labels
+	}
+	moved, err := nc.dbRecordMovedTag(sid, digest)
+	if err != nil {
+		return err
+	}
+	if moved != nil {
+		return moved
+	}
+	if err := nc.dbInsert(sid, in, etag, digest, qs); err != nil {
+		return err
+	}
+	return nc.recordProvenance(in, labels)
+}
+
+// Replicate implements sous.Replicator on NameCache. It copies the image
+// named by art into registry, unless it's already there, and records the copy.
+func (nc *NameCache) Replicate(sid sous.SourceID, art *sous.BuildArtifact, registry string) (*sous.BuildArtifact, error) {
+	ref, err := reference.ParseNamed(art.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "replicating %s", art.Name)
+	}
+	if host, _ := reference.SplitHostname(ref); host == registry {
+		return art, nil
+	}
+	id, err := nc.dbQueryMetadataID(sid)
+	if err != nil {
+		return nil, err
+	}
+	name, err := nc.dbQueryCopy(id, registry)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		Log.Info.Printf("Copying %s into %s", art.Name, registry)
+		name, err = nc.RegistryClient.CopyImage(art.Name, registry)
+		if err != nil {
+			return nil, err
+		}
+		if err := nc.dbRecordCopy(id, registry, name); err != nil {
+			return nil, err
+		}
+	}
+	return &sous.BuildArtifact{Name: name, Type: art.Type, Qualities: art.Qualities}, nil
 }
 
 func (nc *NameCache) harvest(sl sous.SourceLocation) error {
@@ -332,67 +478,6 @@ type DBConfig struct {
 	Driver, Connection string
 }
 
-var schema = []string{
-	"pragma foreign_keys = ON;",
-
-	"create table _database_metadata_(" +
-		"name text not null unique on conflict replace" +
-		", value text" +
-		");",
-
-	"create table docker_repo_name(" +
-		"repo_name_id integer primary key autoincrement" +
-		", name text not null" +
-		", constraint upsertable unique (name)" +
-		");",
-
-	"create table docker_search_location(" +
-		"location_id integer primary key autoincrement" +
-		", repo text not null" +
-		", offset text not null" +
-		", constraint upsertable unique (repo, offset)" +
-		");",
-
-	"create table repo_through_location(" +
-		"repo_name_id references docker_repo_name" +
-		"    not null" +
-		", location_id references docker_search_location" +
-		"    not null" +
-		",  primary key (repo_name_id, location_id)" +
-		");",
-
-	"create table docker_search_metadata(" +
-		"metadata_id integer primary key autoincrement" +
-		", location_id references docker_search_location" +
-		"    not null" +
-		", etag text not null" +
-		", canonicalName text not null" +
-		", version text not null" +
-		", constraint upsertable unique (location_id, version)" +
-		", constraint canonical unique (canonicalName)" +
-		");",
-
-	"create table docker_search_name(" +
-		"name_id integer primary key autoincrement" +
-		", metadata_id references docker_search_metadata" +
-		"    on delete cascade not null" +
-		", name text not null unique" +
-		");",
-
-	// "qualities" includes advisories. assuming that assertions will also
-	// be represented here
-	"create table docker_image_qualities(" +
-		"assertion_id integer primary key autoincrement" +
-		", metadata_id references docker_search_metadata" +
-		"    not null" +
-		", quality text not null" +
-		", kind text not null" +
-		", constraint upsertable unique (metadata_id, quality, kind) on conflict ignore" +
-		");",
-}
-
-var schemaFingerprint = fingerPrintSchema(schema)
-
 var registerSQLOnce = &sync.Once{}
 
 // GetDatabase initialises a new database for a NameCache.
@@ -442,39 +527,6 @@ func semverEqual(a, b string) (bool, error) {
 	return aVer.Equals(bVer), nil
 }
 
-// GroomDatabase ensures that the database to back the cache is the correct schema
-func (nc *NameCache) GroomDatabase() error {
-	db := nc.DB
-	var tgp string
-	err := db.QueryRow("select value from _database_metadata_ where name = 'fingerprint';").Scan(&tgp)
-	if err != nil || tgp != schemaFingerprint {
-		//log.Println(err, tgp, schemaFingerprint)
-		err = nil
-		repos := captureRepos(db)
-
-		clobber(db)
-
-		for _, cmd := range schema {
-			if err := sqlExec(db, cmd); err != nil {
-				return errors.Wrapf(err, "groom DB/create: %v", db)
-			}
-		}
-		if _, err := db.Exec("insert into _database_metadata_ (name, value) values"+
-			" ('fingerprint', ?),"+
-			" ('created', ?);",
-			schemaFingerprint, time.Now().UTC().Format(time.UnixDate)); err != nil {
-			return errors.Wrapf(err, "groom DB/fp: %v", db)
-		}
-		for _, r := range repos {
-			if err := nc.Warmup(r); err != nil {
-				return errors.Wrap(err, "groom DB")
-			}
-		}
-	}
-
-	return errors.Wrap(err, "groom DB")
-}
-
 func clobber(db *sql.DB) {
 	Log.Debug.Print("DB Clobbering time!")
 	sqlExec(db, "PRAGMA writable_schema = 1;")
@@ -552,6 +604,7 @@ func (nc *NameCache) dump(io io.Writer) {
 	nc.dumpRows(io, "select * from docker_search_metadata")
 	nc.dumpRows(io, "select * from docker_search_name")
 	nc.dumpRows(io, "select * from docker_image_qualities")
+	nc.dumpRows(io, "select * from docker_image_registries")
 }
 
 func sqlExec(db *sql.DB, sql string) error {
@@ -593,7 +646,9 @@ func (nc *NameCache) ensureInDB(sel, ins string, args ...interface{}) (id int64,
 	return id, errors.Wrapf(err, "getting id of new value: %q %v", ins, args[0:insN])
 }
 
-func (nc *NameCache) dbInsert(sid sous.SourceID, in, etag string, quals []sous.Quality) error {
+func (nc *NameCache) dbInsert(sid sous.SourceID, in, etag, digest string, quals []sous.Quality) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
 	ref, err := reference.ParseNamed(in)
 	Log.Debug.Printf("Parsed image name: %v from %q", ref, in)
 	if err != nil {
@@ -636,13 +691,21 @@ func (nc *NameCache) dbInsert(sid sous.SourceID, in, etag string, quals []sous.Q
 
 	id, err = nc.ensureInDB(
 		"select metadata_id from docker_search_metadata  where canonicalName = $1",
-		"insert or replace into docker_search_metadata (canonicalName, location_id, etag, version) values ($1, $2, $3, $4);",
-		in, id, etag, versionString)
+		"insert or replace into docker_search_metadata (canonicalName, location_id, etag, version, digest) values ($1, $2, $3, $4, $5);",
+		in, id, etag, versionString, digest)
 
 	if err != nil {
 		return err
 	}
 
+	if digest != "" {
+		_, err = nc.DB.Exec("update docker_search_metadata set digest = $1 "+
+			"where metadata_id = $2 and digest = ''", digest, id)
+		if err != nil {
+			return errors.Wrapf(err, "recording digest %s for %q", digest, in)
+		}
+	}
+
 	for _, q := range quals {
 		if q.Kind == "advisory" && q.Name == "" {
 			continue
@@ -659,6 +722,188 @@ func (nc *NameCache) dbInsert(sid sous.SourceID, in, etag string, quals []sous.Q
 	return nc.dbAddNamesForID(id, []string{in})
 }
 
+// dbQueryMetadataID returns the id of the image recorded for sid.
+func (nc *NameCache) dbQueryMetadataID(sid sous.SourceID) (id int64, err error) {
+	row := nc.DB.QueryRow("select "+
+		"docker_search_metadata.metadata_id "+
+		"from "+
+		"docker_search_metadata natural join docker_search_location "+
+		"where "+
+		"docker_search_location.repo = $1 and "+
+		"docker_search_location.offset = $2 and "+
+		"semverEqual(docker_search_metadata.version, $3)",
+		sid.Location.Repo, sid.Location.Dir, sid.Version.String())
+	err = row.Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, NoImageNameFound{sid}
+	}
+	return id, errors.Wrapf(err, "looking up image for %v", sid)
+}
+
+// dbRecordProvenance records p as the provenance of the image with canonical
+// name cn.
+func (nc *NameCache) dbRecordProvenance(cn string, p *sous.Provenance) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+	built := ""
+	if !p.Built.IsZero() {
+		built = p.Built.UTC().Format(time.RFC3339)
+	}
+	_, err := nc.DB.Exec("insert into docker_image_provenance "+
+		"(metadata_id, build_user, build_host, buildpack, detected, base_image, "+
+		"dockerfile_hash, remote_url, revision, built) "+
+		"select metadata_id, $1, $2, $3, $4, $5, $6, $7, $8, $9 "+
+		"from docker_search_metadata where canonicalName = $10",
+		p.User, p.Host, p.Buildpack, p.Detected, p.BaseImage,
+		p.DockerfileHash, p.RemoteURL, p.Revision, built, cn)
+	return errors.Wrapf(err, "recording provenance of %q", cn)
+}
+
+// dbQueryProvenance returns the provenance recorded for image id, or nil if
+// none was.
+func (nc *NameCache) dbQueryProvenance(id int64) (*sous.Provenance, error) {
+	p := &sous.Provenance{}
+	var built string
+	row := nc.DB.QueryRow("select build_user, build_host, buildpack, detected, "+
+		"base_image, dockerfile_hash, remote_url, revision, built "+
+		"from docker_image_provenance where metadata_id = $1", id)
+	err := row.Scan(&p.User, &p.Host, &p.Buildpack, &p.Detected,
+		&p.BaseImage, &p.DockerfileHash, &p.RemoteURL, &p.Revision, &built)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if built != "" {
+		p.Built, err = time.Parse(time.RFC3339, built)
+	}
+	return p, err
+}
+
+// dbQueryCopy returns the name of the copy of image id held by registry, or
+// the empty string if there isn't one.
+func (nc *NameCache) dbQueryCopy(id int64, registry string) (name string, err error) {
+	row := nc.DB.QueryRow("select name from docker_image_registries "+
+		"where metadata_id = $1 and registry = $2", id, registry)
+	err = row.Scan(&name)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return name, errors.Wrapf(err, "looking up copy in %s", registry)
+}
+
+// dbRecordCopy records that registry holds a copy of image id, named name.
+// The name is added to the names of the image, so that the SourceID of a
+// deployment of the copy can be found.
+func (nc *NameCache) dbRecordCopy(id int64, registry, name string) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+	_, err := nc.DB.Exec("insert into docker_image_registries "+
+		"(metadata_id, registry, name) values ($1, $2, $3)", id, registry, name)
+	if err != nil {
+		return errors.Wrapf(err, "recording copy %s", name)
+	}
+	return nc.dbAddNamesForID(id, []string{name})
+}
+
+// dbQueryCopies returns the names of the copies of the image with canonical
+// name cn held by other registries.
+func (nc *NameCache) dbQueryCopies(cn string) (names []string, err error) {
+	rows, err := nc.DB.Query("select docker_image_registries.name "+
+		"from docker_image_registries natural join docker_search_metadata "+
+		"where docker_search_metadata.canonicalName = $1", cn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking up copies of %s", cn)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, errors.Wrapf(err, "looking up copies of %s", cn)
+		}
+		names = append(names, n)
+	}
+	return names, errors.Wrapf(rows.Err(), "looking up copies of %s", cn)
+}
+
+// dbAddQualities attaches qs to the image recorded for sid.
+func (nc *NameCache) dbAddQualities(sid sous.SourceID, qs []sous.Quality) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+
+	id, err := nc.dbQueryMetadataID(sid)
+	if err != nil {
+		return errors.Wrap(err, "adding qualities")
+	}
+
+	for _, q := range qs {
+		_, err := nc.DB.Exec("insert into docker_image_qualities"+
+			"  (metadata_id, quality, kind)"+
+			"  values"+
+			"  ($1,$2,$3)",
+			id, q.Name, q.Kind)
+		if err != nil {
+			return errors.Wrapf(err, "adding %s %q to %v", q.Kind, q.Name, sid)
+		}
+	}
+	return nil
+}
+
+// dbRecordMovedTag checks whether digest differs from the digest already
+// recorded for sid. If so, it records digest as the one the tag has moved to,
+// leaving the first recorded image in place, and returns a
+// *sous.TagMovedError.
+func (nc *NameCache) dbRecordMovedTag(sid sous.SourceID, digest string) (*sous.TagMovedError, error) {
+	if digest == "" {
+		return nil, nil
+	}
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+
+	var id int64
+	var cn, recorded string
+	row := nc.DB.QueryRow("select "+
+		"docker_search_metadata.metadata_id, "+
+		"docker_search_metadata.canonicalName, "+
+		"docker_search_metadata.digest "+
+		"from "+
+		"docker_search_metadata natural join docker_search_location "+
+		"where "+
+		"docker_search_location.repo = $1 and "+
+		"docker_search_location.offset = $2 and "+
+		"semverEqual(docker_search_metadata.version, $3)",
+		sid.Location.Repo, sid.Location.Dir, sid.Version.String())
+	err := row.Scan(&id, &cn, &recorded)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking recorded digest for %v", sid)
+	}
+	if recorded == "" || recorded == digest {
+		return nil, nil
+	}
+	_, err = nc.DB.Exec("update docker_search_metadata set moved_digest = $1 "+
+		"where metadata_id = $2", digest, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "recording moved tag for %v", sid)
+	}
+	return &sous.TagMovedError{SourceID: sid, Image: cn, Recorded: recorded, Current: digest}, nil
+}
+
+// dbQueryDigests returns the digest recorded for the image with canonical
+// name cn, and the digest its tag has since moved to, if any.
+func (nc *NameCache) dbQueryDigests(cn string) (digest, moved string, err error) {
+	row := nc.DB.QueryRow("select digest, moved_digest from docker_search_metadata "+
+		"where canonicalName = $1", cn)
+	err = row.Scan(&digest, &moved)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return digest, moved, errors.Wrapf(err, "getting digests for %q", cn)
+}
+
 func (nc *NameCache) dbAddNamesForID(id int64, ins []string) error {
 	add, err := nc.DB.Prepare("insert or replace into docker_search_name " +
 		"(metadata_id, name) values ($1, $2)")
@@ -677,6 +922,8 @@ func (nc *NameCache) dbAddNamesForID(id int64, ins []string) error {
 }
 
 func (nc *NameCache) dbAddNames(cn string, ins []string) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
 	var id int64
 	Log.Debug.Printf("Adding names for %s: %+v", cn, ins)
 	row := nc.DB.QueryRow("select metadata_id from docker_search_metadata "+
@@ -758,6 +1005,63 @@ func (nc *NameCache) dbQueryAllSourceIds() (ids []sous.SourceID, err error) {
 	return
 }
 
+// dbQueryAllImages returns the SourceID, canonical name and digest of every
+// image recorded in the cache.
+func (nc *NameCache) dbQueryAllImages() (images []GCImage, err error) {
+	rows, err := nc.DB.Query("select docker_search_location.repo, " +
+		"docker_search_location.offset, " +
+		"docker_search_metadata.version, " +
+		"docker_search_metadata.canonicalName, " +
+		"docker_search_metadata.digest " +
+		"from " +
+		"docker_search_location natural join docker_search_metadata")
+	if err != nil {
+		return nil, errors.Wrap(err, "listing cached images")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r, o, v string
+		var ci GCImage
+		if err := rows.Scan(&r, &o, &v, &ci.Name, &ci.Digest); err != nil {
+			return nil, errors.Wrap(err, "listing cached images")
+		}
+		ver, err := semv.Parse(v)
+		if err != nil {
+			Log.Debug.Printf("Skipping cached image %q with bad version %q: %v", ci.Name, v, err)
+			continue
+		}
+		ci.SourceID = sous.SourceID{
+			Location: sous.SourceLocation{Repo: r, Dir: o},
+			Version:  ver,
+		}
+		images = append(images, ci)
+	}
+	return images, errors.Wrap(rows.Err(), "listing cached images")
+}
+
+// dbDeleteImage removes the image with canonical name cn from the cache,
+// along with its other names and qualities.
+func (nc *NameCache) dbDeleteImage(cn string) error {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+	for _, del := range []string{
+		"delete from docker_image_registries where metadata_id in " +
+			"(select metadata_id from docker_search_metadata where canonicalName = $1)",
+		"delete from docker_image_qualities where metadata_id in " +
+			"(select metadata_id from docker_search_metadata where canonicalName = $1)",
+		"delete from docker_image_provenance where metadata_id in " +
+			"(select metadata_id from docker_search_metadata where canonicalName = $1)",
+		"delete from docker_search_name where metadata_id in " +
+			"(select metadata_id from docker_search_metadata where canonicalName = $1)",
+		"delete from docker_search_metadata where canonicalName = $1",
+	} {
+		if _, err := nc.DB.Exec(del, cn); err != nil {
+			return errors.Wrapf(err, "removing %q from the name cache", cn)
+		}
+	}
+	return nil
+}
+
 type strpairs []strpair
 type strpair [2]string
 
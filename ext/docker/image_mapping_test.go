@@ -27,7 +27,7 @@ func inMemoryDB(name string) *sql.DB {
 func BenchmarkFPSchema(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		fingerPrintSchema(schema)
+		fingerPrintSchema(schemaMigrations[0].statements)
 	}
 }
 
@@ -106,6 +106,8 @@ func TestReharvest(t *testing.T) {
 	}
 	nc.dump(os.Stderr)
 
+	// A database from before schema migrations, with an unrecognized schema.
+	nc.DB.Exec("drop table _schema_migrations_")
 	nc.DB.Exec("update _database_metadata_ set value='' where name='fingerprint'")
 
 	dc.FeedTags([]string{"version" + vstr})
@@ -496,3 +498,172 @@ func TestUnion(t *testing.T) {
 	assert.Contains(all, "c")
 	assert.Contains(all, "d")
 }
+
+func TestTagMoved(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	base := "ot/wackadoo"
+	nc := NewNameCache(host, dc, inMemoryDB("tag_moved"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	in := host + "/" + base + ":version-1.2.3"
+	first := "sha256:012345678901234567890123456789AB012345678901234567890123456789AB"
+	second := "sha256:112345678901234567890123456789AB012345678901234567890123456789AB"
+
+	feed := func(digest string) {
+		cn := base + "@" + digest
+		dc.FeedMetadata(docker_registry.Metadata{
+			Registry:      host,
+			Labels:        Labels(sv),
+			Etag:          digest,
+			CanonicalName: cn,
+			AllNames:      []string{cn, base + ":version-1.2.3"},
+			Digest:        digest,
+		})
+	}
+
+	feed(first)
+	_, err := nc.GetSourceID(NewBuildArtifact(in, nil))
+	assert.NoError(err)
+	art, err := nc.GetArtifact(sv)
+	if assert.NoError(err) {
+		assert.Equal(host+"/"+base+"@"+first, art.Name)
+	}
+
+	dc = docker_registry.NewDummyClient()
+	nc.RegistryClient = dc
+	feed(second)
+	_, err = nc.GetSourceID(NewBuildArtifact(in, nil))
+	assert.NoError(err)
+
+	_, err = nc.GetArtifact(sv)
+	if moved, ok := err.(*sous.TagMovedError); assert.True(ok, "got %v", err) {
+		assert.Equal(first, moved.Recorded)
+		assert.Equal(second, moved.Current)
+	}
+}
+
+func TestInsertPinsDigest(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("insert_digest"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	in := host + "/ot/wackadoo:version-1.2.3"
+	digest := "sha256:012345678901234567890123456789AB012345678901234567890123456789AB"
+	dc.FeedMetadata(docker_registry.Metadata{Registry: host, Digest: digest})
+
+	assert.NoError(nc.Insert(sv, in, "", nil))
+	art, err := nc.GetArtifact(sv)
+	if assert.NoError(err) {
+		assert.Equal(host+"/ot/wackadoo@"+digest, art.Name)
+	}
+
+	moved := "sha256:112345678901234567890123456789AB012345678901234567890123456789AB"
+	dc = docker_registry.NewDummyClient()
+	dc.FeedMetadata(docker_registry.Metadata{Registry: host, Digest: moved})
+	nc.RegistryClient = dc
+	assert.IsType(&sous.TagMovedError{}, nc.Insert(sv, in, "", nil))
+}
+
+func TestInsertAssertions(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("assertions"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	assert.Error(nc.Insert(sv, "", "", []sous.Quality{{Name: "integration-tested", Kind: "assertion"}}),
+		"attaching qualities to an unknown artifact should fail")
+
+	in := host + "/ot/wackadoo:version-1.2.3"
+	assert.NoError(nc.Insert(sv, in, "", nil))
+	assert.NoError(nc.Insert(sv, "", "", []sous.Quality{{Name: "integration-tested", Kind: "assertion"}}))
+
+	art, err := nc.GetArtifact(sv)
+	if assert.NoError(err) {
+		assert.Equal(in, art.Name)
+		assert.Equal([]sous.Quality{{Name: "integration-tested", Kind: "assertion"}}, art.Qualities)
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	mirror := "mirror.repo.io"
+	nc := NewNameCache(host, etagClient{dc}, inMemoryDB("replicate"))
+	dc.AddMetadata(mirror, docker_registry.Metadata{Registry: mirror, Etag: "copied"})
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	in := host + "/ot/wackadoo:version-1.2.3"
+	assert.NoError(nc.Insert(sv, in, "copied", nil))
+	art, err := nc.GetArtifact(sv)
+	if !assert.NoError(err) {
+		return
+	}
+
+	same, err := nc.Replicate(sv, art, host)
+	assert.NoError(err)
+	assert.Equal(art, same, "an image in the target registry should not be copied")
+
+	copied, err := nc.Replicate(sv, art, mirror)
+	assert.NoError(err)
+	assert.Equal(mirror+"/ot/wackadoo:version-1.2.3", copied.Name)
+	assert.Len(dc.CallsTo("CopyImage"), 1)
+
+	again, err := nc.Replicate(sv, art, mirror)
+	assert.NoError(err)
+	assert.Equal(copied.Name, again.Name)
+	assert.Len(dc.CallsTo("CopyImage"), 1, "a copied image should not be copied again")
+
+	sid, err := nc.GetSourceID(copied)
+	assert.NoError(err)
+	assert.Equal(sv, sid)
+
+	_, err = nc.Replicate(sous.MustNewSourceID("https://github.com/opentable/wackadoo", "", "9.9.9"), art, mirror)
+	assert.Error(err)
+}
+
+func TestFileArtifacts(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("file_artifacts"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	_, err := nc.GetArtifact(sv)
+	assert.Error(err)
+
+	layout := &sous.BuildArtifact{Name: "/builds/wackadoo", Type: sous.ArtifactTypeOCILayout}
+	assert.NoError(nc.InsertArtifact(sv, layout))
+	assert.Error(nc.InsertArtifact(sv, &sous.BuildArtifact{Name: "x", Type: "rpm"}))
+
+	art, err := nc.GetArtifact(sv)
+	if assert.NoError(err) {
+		assert.Equal(layout, art)
+	}
+
+	assert.NoError(nc.Insert(sv, host+"/wackadoo:1.2.3", "", nil))
+	art, err = nc.GetArtifact(sv)
+	if assert.NoError(err) {
+		assert.Equal(host+"/wackadoo:1.2.3", art.Name, "an image should be preferred to the file")
+	}
+}
+
+func TestFileArtifactsRegistryError(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	// Image names in this registry can't be parsed, so harvesting it fails.
+	nc := NewNameCache("bad host", dc, inMemoryDB("file_artifacts_registry_error"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	layout := &sous.BuildArtifact{Name: "/builds/wackadoo", Type: sous.ArtifactTypeOCILayout}
+	assert.NoError(nc.InsertArtifact(sv, layout))
+
+	art, err := nc.GetArtifact(sv)
+	assert.Error(err, "a registry error should not be hidden by the file")
+	assert.Nil(art)
+}
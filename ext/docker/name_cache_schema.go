@@ -0,0 +1,393 @@
+package docker
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A schemaMigration changes the schema of the name cache database from
+	// one version to the next.
+	schemaMigration struct {
+		description string
+		statements  []string
+	}
+
+	// SchemaStatus describes the schema version of a name cache database.
+	SchemaStatus struct {
+		// Version is the number of migrations applied to the database, and
+		// Latest the number there are.
+		Version, Latest int
+		// Applied lists the migrations applied to the database, in order.
+		Applied []AppliedMigration
+		// Pending describes the migrations yet to be applied, in order.
+		Pending []string
+	}
+
+	// An AppliedMigration records a migration applied to the database.
+	AppliedMigration struct {
+		// Version is the schema version the migration upgraded the
+		// database to.
+		Version int
+		// Description says what the migration changed.
+		Description string
+		// Applied is when the migration was applied.
+		Applied string
+	}
+)
+
+// schemaMigrations are the changes to the name cache schema, in the order
+// they're applied: applying migration i to a version i database upgrades it to
+// version i+1. Migrations which have been released must never be changed, so
+// that databases created by earlier releases can be upgraded; add new
+// migrations to the end instead.
+var schemaMigrations = []schemaMigration{
+	{
+		description: "create the name cache",
+		statements: []string{
+			"pragma foreign_keys = ON;",
+
+			"create table _database_metadata_(" +
+				"name text not null unique on conflict replace" +
+				", value text" +
+				");",
+
+			"create table docker_repo_name(" +
+				"repo_name_id integer primary key autoincrement" +
+				", name text not null" +
+				", constraint upsertable unique (name)" +
+				");",
+
+			"create table docker_search_location(" +
+				"location_id integer primary key autoincrement" +
+				", repo text not null" +
+				", offset text not null" +
+				", constraint upsertable unique (repo, offset)" +
+				");",
+
+			"create table repo_through_location(" +
+				"repo_name_id references docker_repo_name" +
+				"    not null" +
+				", location_id references docker_search_location" +
+				"    not null" +
+				",  primary key (repo_name_id, location_id)" +
+				");",
+
+			"create table docker_search_metadata(" +
+				"metadata_id integer primary key autoincrement" +
+				", location_id references docker_search_location" +
+				"    not null" +
+				", etag text not null" +
+				", canonicalName text not null" +
+				", version text not null" +
+				", constraint upsertable unique (location_id, version)" +
+				", constraint canonical unique (canonicalName)" +
+				");",
+
+			"create table docker_search_name(" +
+				"name_id integer primary key autoincrement" +
+				", metadata_id references docker_search_metadata" +
+				"    on delete cascade not null" +
+				", name text not null unique" +
+				");",
+
+			// "qualities" includes advisories. assuming that assertions will also
+			// be represented here
+			"create table docker_image_qualities(" +
+				"assertion_id integer primary key autoincrement" +
+				", metadata_id references docker_search_metadata" +
+				"    not null" +
+				", quality text not null" +
+				", kind text not null" +
+				", constraint upsertable unique (metadata_id, quality, kind) on conflict ignore" +
+				");",
+		},
+	},
+	{
+		description: "record image digests, and the digests tags move to",
+		statements: []string{
+			"alter table docker_search_metadata add column digest text not null default '';",
+			"alter table docker_search_metadata add column moved_digest text not null default '';",
+		},
+	},
+	{
+		description: "record copies of images held by other registries",
+		statements: []string{
+			// copies of images held by registries other than the one they
+			// were found in, e.g. the mirrors clusters pull from
+			"create table docker_image_registries(" +
+				"metadata_id references docker_search_metadata" +
+				"    not null" +
+				", registry text not null" +
+				", name text not null" +
+				", constraint upsertable unique (metadata_id, registry) on conflict replace" +
+				");",
+		},
+	},
+	{
+		description: "record the provenance of images",
+		statements: []string{
+			"create table docker_image_provenance(" +
+				"metadata_id references docker_search_metadata" +
+				"    not null primary key on conflict replace" +
+				", build_user text not null" +
+				", build_host text not null" +
+				", buildpack text not null" +
+				", detected text not null" +
+				", base_image text not null" +
+				", dockerfile_hash text not null" +
+				", remote_url text not null" +
+				", revision text not null" +
+				", built text not null" +
+				");",
+		},
+	},
+	{
+		description: "record artifacts which are files, by type",
+		statements: []string{
+			// artifacts other than images in a registry, e.g. OCI image
+			// layouts, which are pushed into the registry when they're
+			// built
+			"create table artifact_files(" +
+				"location_id references docker_search_location" +
+				"    not null" +
+				", version text not null" +
+				", artifact_type text not null" +
+				", name text not null" +
+				", constraint upsertable unique (location_id, version, artifact_type) on conflict replace" +
+				");",
+		},
+	},
+}
+
+// legacyFingerprint is the fingerprint recorded in databases created before
+// schema migrations were introduced, whose schema is that created by the
+// first migration.
+var legacyFingerprint = fingerPrintSchema(schemaMigrations[0].statements)
+
+const createMigrationsTable = "create table if not exists _schema_migrations_(" +
+	"version integer primary key" +
+	", description text not null" +
+	", applied text not null" +
+	");"
+
+// GroomDatabase ensures that the database to back the cache is the correct
+// schema, by applying any pending migrations.
+func (nc *NameCache) GroomDatabase() error {
+	_, err := nc.Migrate()
+	return errors.Wrap(err, "groom DB")
+}
+
+// SchemaStatus reports the schema version of the database, without changing
+// it.
+func (nc *NameCache) SchemaStatus() (SchemaStatus, error) {
+	st := SchemaStatus{Latest: len(schemaMigrations)}
+	rows, err := nc.DB.Query("select version, description, applied " +
+		"from _schema_migrations_ order by version")
+	if err != nil {
+		// No migrations table: the database predates migrations, or is empty.
+		st.Version, err = legacyVersion(nc.DB)
+		if err != nil {
+			return st, err
+		}
+	} else {
+		defer rows.Close()
+		for rows.Next() {
+			var am AppliedMigration
+			if err := rows.Scan(&am.Version, &am.Description, &am.Applied); err != nil {
+				return st, errors.Wrap(err, "reading schema migrations")
+			}
+			st.Applied = append(st.Applied, am)
+			st.Version = am.Version
+		}
+		if err := rows.Err(); err != nil {
+			return st, errors.Wrap(err, "reading schema migrations")
+		}
+	}
+	for _, m := range schemaMigrations[st.Version:] {
+		st.Pending = append(st.Pending, m.description)
+	}
+	return st, nil
+}
+
+// legacyVersion returns the schema version of a database without a record
+// of the migrations applied to it: 0 if it's empty, and 1 if it has the
+// schema created before migrations were introduced. Otherwise, it returns an
+// error.
+func legacyVersion(db *sql.DB) (int, error) {
+	var fp string
+	err := db.QueryRow("select value from _database_metadata_ where name = 'fingerprint';").Scan(&fp)
+	if err != nil {
+		var tables int
+		if err := db.QueryRow("select count(*) from sqlite_master where type = 'table'").Scan(&tables); err != nil {
+			return 0, errors.Wrap(err, "inspecting name cache DB")
+		}
+		if tables == 0 {
+			return 0, nil
+		}
+		return 0, errors.New("name cache DB has an unrecognized schema")
+	}
+	if fp != legacyFingerprint {
+		return 0, errors.Errorf("name cache DB has an unrecognized schema (fingerprint %q)", fp)
+	}
+	return 1, nil
+}
+
+// Migrate applies the pending schema migrations to the database, each in its
+// own transaction, and reports the resulting schema version. A database whose
+// schema isn't recognized is rebuilt from scratch, and the repositories it
+// knew of are harvested again.
+func (nc *NameCache) Migrate() (SchemaStatus, error) {
+	repos, err := nc.migrate()
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	for _, r := range repos {
+		if err := nc.Warmup(r); err != nil {
+			return SchemaStatus{}, err
+		}
+	}
+	return nc.SchemaStatus()
+}
+
+// migrate applies the pending migrations, returning the repositories to
+// harvest again if the database had to be rebuilt.
+func (nc *NameCache) migrate() (repos []string, err error) {
+	nc.writes.Lock()
+	defer nc.writes.Unlock()
+
+	st, err := nc.SchemaStatus()
+	if err != nil {
+		Log.Warn.Printf("Rebuilding name cache DB: %v", err)
+		repos = captureRepos(nc.DB)
+		clobber(nc.DB)
+		st = SchemaStatus{}
+	}
+	if _, err := nc.DB.Exec(createMigrationsTable); err != nil {
+		return nil, errors.Wrap(err, "creating schema migrations table")
+	}
+	if st.Version == 1 && len(st.Applied) == 0 {
+		if err := recordMigration(nc.DB, 1, "before schema migrations"); err != nil {
+			return nil, err
+		}
+	}
+
+	for v := st.Version; v < len(schemaMigrations); v++ {
+		if err := applyMigration(nc.DB, v); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+func applyMigration(db *sql.DB, v int) error {
+	m := schemaMigrations[v]
+	Log.Debug.Printf("Migrating name cache DB to version %d: %s", v+1, m.description)
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrapf(err, "migrating to version %d", v+1)
+	}
+	for _, stmt := range m.statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "migrating to version %d: %s", v+1, stmt)
+		}
+	}
+	if _, err := tx.Exec("insert into _schema_migrations_ (version, description, applied) values ($1, $2, $3)",
+		v+1, m.description, time.Now().UTC().Format(time.UnixDate)); err != nil {
+		tx.Rollback()
+		return errors.Wrapf(err, "recording migration to version %d", v+1)
+	}
+	return errors.Wrapf(tx.Commit(), "migrating to version %d", v+1)
+}
+
+func recordMigration(db *sql.DB, v int, applied string) error {
+	_, err := db.Exec("insert into _schema_migrations_ (version, description, applied) values ($1, $2, $3)",
+		v, schemaMigrations[v-1].description, applied)
+	return errors.Wrapf(err, "recording migration to version %d", v)
+}
+
+// VerifySchema checks that the schema of the database is the same as that of
+// a new database with every migration applied.
+func (nc *NameCache) VerifySchema() error {
+	conn := InMemoryConnection(fmt.Sprintf("verify%d", time.Now().UnixNano()))
+	fresh, err := GetDatabase(&DBConfig{Driver: "sqlite3_sous", Connection: conn})
+	if err != nil {
+		return err
+	}
+	defer fresh.Close()
+	if _, err := fresh.Exec(createMigrationsTable); err != nil {
+		return errors.Wrap(err, "creating schema migrations table")
+	}
+	for v := range schemaMigrations {
+		if err := applyMigration(fresh, v); err != nil {
+			return err
+		}
+	}
+
+	want, err := describeSchema(fresh)
+	if err != nil {
+		return err
+	}
+	got, err := describeSchema(nc.DB)
+	if err != nil {
+		return err
+	}
+	var diffs []string
+	for table, cols := range want {
+		if got[table] != cols {
+			diffs = append(diffs, fmt.Sprintf("table %s: want columns (%s), got (%s)", table, cols, got[table]))
+		}
+	}
+	for table := range got {
+		if _, ok := want[table]; !ok && table != "_schema_migrations_" {
+			diffs = append(diffs, fmt.Sprintf("unexpected table %s", table))
+		}
+	}
+	if len(diffs) > 0 {
+		return errors.Errorf("name cache DB schema differs from the latest version:\n  %s", strings.Join(diffs, "\n  "))
+	}
+	return nil
+}
+
+// describeSchema returns a description of the columns of each table in db.
+func describeSchema(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query("select name from sqlite_master " +
+		"where type = 'table' and name not like 'sqlite_%'")
+	if err != nil {
+		return nil, errors.Wrap(err, "listing tables")
+	}
+	var tables []string
+	for rows.Next() {
+		var t string
+		rows.Scan(&t)
+		tables = append(tables, t)
+	}
+	rows.Close()
+
+	desc := map[string]string{}
+	for _, t := range tables {
+		cols, err := db.Query(fmt.Sprintf("pragma table_info(%s)", t))
+		if err != nil {
+			return nil, errors.Wrapf(err, "describing table %s", t)
+		}
+		var cs []string
+		for cols.Next() {
+			var cid, notNull, pk int
+			var name, typ string
+			var dflt sql.NullString
+			if err := cols.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+				cols.Close()
+				return nil, errors.Wrapf(err, "describing table %s", t)
+			}
+			cs = append(cs, fmt.Sprintf("%s %s notnull=%d default=%s pk=%d", name, typ, notNull, dflt.String, pk))
+		}
+		cols.Close()
+		desc[t] = strings.Join(cs, ", ")
+	}
+	return desc, nil
+}
@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+)
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// A database as created before schema migrations were introduced.
+	db := inMemoryDB("legacy")
+	for _, stmt := range schemaMigrations[0].statements {
+		_, err := db.Exec(stmt)
+		require.NoError(err)
+	}
+	_, err := db.Exec("insert into _database_metadata_ (name, value) values ('fingerprint', ?)", legacyFingerprint)
+	require.NoError(err)
+	for _, stmt := range []string{
+		"insert into docker_search_location (location_id, repo, offset) values (1, 'github.com/opentable/wackadoo', '')",
+		"insert into docker_search_metadata (metadata_id, location_id, etag, canonicalName, version) " +
+			"values (1, 1, 'etag', 'docker.repo.io/wackadoo:1.2.3', '1.2.3')",
+		"insert into docker_search_name (metadata_id, name) values (1, 'docker.repo.io/wackadoo:1.2.3')",
+		"insert into docker_image_qualities (metadata_id, quality, kind) values (1, 'integration-tested', 'assertion')",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(err)
+	}
+
+	dc := docker_registry.NewDummyClient()
+	nc := NewNameCache("docker.repo.io", dc, db)
+
+	st, err := nc.SchemaStatus()
+	require.NoError(err)
+	assert.Equal(len(schemaMigrations), st.Version)
+	assert.Equal(st.Latest, st.Version)
+	assert.Empty(st.Pending)
+	if assert.Len(st.Applied, len(schemaMigrations)) {
+		assert.Equal("before schema migrations", st.Applied[0].Applied)
+	}
+	assert.NoError(nc.VerifySchema())
+	assert.Empty(dc.CallsTo("AllTags"), "migrating should not harvest again")
+
+	art, err := nc.GetArtifact(sous.MustNewSourceID("github.com/opentable/wackadoo", "", "1.2.3"))
+	if assert.NoError(err) {
+		assert.Equal("docker.repo.io/wackadoo:1.2.3", art.Name)
+		assert.Equal([]sous.Quality{{Name: "integration-tested", Kind: "assertion"}}, art.Qualities)
+	}
+}
+
+func TestMigratePartialDatabase(t *testing.T) {
+	assert := assert.New(t)
+
+	db := inMemoryDB("partial")
+	_, err := db.Exec(createMigrationsTable)
+	assert.NoError(err)
+	assert.NoError(applyMigration(db, 0))
+
+	nc := &NameCache{DB: db, RegistryClient: docker_registry.NewDummyClient()}
+	st, err := nc.SchemaStatus()
+	assert.NoError(err)
+	assert.Equal(1, st.Version)
+	assert.Len(st.Pending, len(schemaMigrations)-1)
+	assert.Error(nc.VerifySchema())
+
+	st, err = nc.Migrate()
+	assert.NoError(err)
+	assert.Equal(st.Latest, st.Version)
+	assert.NoError(nc.VerifySchema())
+
+	_, err = db.Exec("drop table docker_image_registries")
+	assert.NoError(err)
+	assert.Error(nc.VerifySchema())
+}
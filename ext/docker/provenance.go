@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+// ProvenanceLabels computes the labels recording p on an image. Empty fields
+// are omitted.
+func ProvenanceLabels(p *sous.Provenance) map[string]string {
+	labels := map[string]string{}
+	if p == nil {
+		return labels
+	}
+	set := func(label, value string) {
+		if value != "" {
+			labels[label] = value
+		}
+	}
+	set(DockerBuildUserLabel, p.User)
+	set(DockerBuildHostLabel, p.Host)
+	set(DockerBuildpackLabel, p.Buildpack)
+	set(DockerBuildDetectedLabel, p.Detected)
+	set(DockerBaseImageLabel, p.BaseImage)
+	set(DockerDockerfileHashLabel, p.DockerfileHash)
+	set(DockerRemoteURLLabel, p.RemoteURL)
+	set(DockerFullRevisionLabel, p.Revision)
+	if !p.Built.IsZero() {
+		set(DockerBuiltLabel, p.Built.UTC().Format(time.RFC3339))
+	}
+	return labels
+}
+
+// ProvenanceFromLabels reads the provenance recorded in the labels of an
+// image, returning nil if there is none, as for images not built by Sous.
+func ProvenanceFromLabels(labels map[string]string) *sous.Provenance {
+	p := &sous.Provenance{
+		User:           labels[DockerBuildUserLabel],
+		Host:           labels[DockerBuildHostLabel],
+		Buildpack:      labels[DockerBuildpackLabel],
+		Detected:       labels[DockerBuildDetectedLabel],
+		BaseImage:      labels[DockerBaseImageLabel],
+		DockerfileHash: labels[DockerDockerfileHashLabel],
+		RemoteURL:      labels[DockerRemoteURLLabel],
+		Revision:       labels[DockerFullRevisionLabel],
+	}
+	if ts, ok := labels[DockerBuiltLabel]; ok {
+		built, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			Log.Debug.Printf("Ignoring unparseable build timestamp %q: %v", ts, err)
+		}
+		p.Built = built
+	}
+	if *p == (sous.Provenance{}) {
+		return nil
+	}
+	return p
+}
+
+// Provenance returns the provenance recorded for the image built for sid,
+// harvesting its repository if the image isn't cached yet. It returns nil
+// if the image was found but its provenance wasn't recorded.
+func (nc *NameCache) Provenance(sid sous.SourceID) (*sous.Provenance, error) {
+	if _, _, err := nc.getImageName(sid); err != nil {
+		return nil, err
+	}
+	id, err := nc.dbQueryMetadataID(sid)
+	if err != nil {
+		return nil, err
+	}
+	p, err := nc.dbQueryProvenance(id)
+	return p, errors.Wrapf(err, "provenance of %v", sid)
+}
+
+// recordProvenance records the provenance in the labels of the image with
+// canonical name cn, if there is any.
+func (nc *NameCache) recordProvenance(cn string, labels map[string]string) error {
+	p := ProvenanceFromLabels(labels)
+	if p == nil {
+		return nil
+	}
+	return nc.dbRecordProvenance(cn, p)
+}
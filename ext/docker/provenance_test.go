@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+)
+
+func testProvenance() *sous.Provenance {
+	return &sous.Provenance{
+		User:           "builder",
+		Host:           "build-1.example.com",
+		Buildpack:      "dockerfile",
+		Detected:       "Dockerfile Dockerfile, from golang:1.7",
+		BaseImage:      "golang@sha256:abcd",
+		DockerfileHash: "sha256:1234",
+		RemoteURL:      "github.com/opentable/wackadoo",
+		Revision:       "cabba9e5cabba9e5cabba9e5cabba9e5cabba9e5",
+		Built:          time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestProvenanceLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	p := testProvenance()
+	assert.Equal(p, ProvenanceFromLabels(ProvenanceLabels(p)))
+	assert.Nil(ProvenanceFromLabels(Labels(sous.MustParseSourceID("github.com/opentable/wackadoo,1.2.3"))),
+		"images without provenance labels should have no provenance")
+	assert.Empty(ProvenanceLabels(nil))
+}
+
+func TestInsertRecordsProvenance(t *testing.T) {
+	assert := assert.New(t)
+	dc := docker_registry.NewDummyClient()
+	host := "docker.repo.io"
+	nc := NewNameCache(host, dc, inMemoryDB("provenance"))
+
+	sv := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.3")
+	in := host + "/ot/wackadoo:version-1.2.3"
+	labels := Labels(sv)
+	for k, v := range ProvenanceLabels(testProvenance()) {
+		labels[k] = v
+	}
+	dc.FeedMetadata(docker_registry.Metadata{Registry: host, Labels: labels})
+
+	assert.NoError(nc.Insert(sv, in, "", nil))
+	p, err := nc.Provenance(sv)
+	if assert.NoError(err) {
+		assert.Equal(testProvenance(), p)
+	}
+
+	other := sous.MustNewSourceID("https://github.com/opentable/wackadoo", "nested/there", "1.2.4")
+	dc = docker_registry.NewDummyClient()
+	dc.FeedMetadata(docker_registry.Metadata{Registry: host, Labels: Labels(other)})
+	nc.RegistryClient = dc
+	assert.NoError(nc.Insert(other, host+"/ot/wackadoo:version-1.2.4", "", nil))
+	p, err = nc.Provenance(other)
+	assert.NoError(err)
+	assert.Nil(p, "images inserted without provenance labels should have none")
+}
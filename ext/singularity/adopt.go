@@ -0,0 +1,225 @@
+package singularity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// An Adopter finds the requests in Singularity that Sous doesn't manage -
+	// those whose IDs weren't made by MakeRequestID - and brings them under
+	// its management.
+	Adopter struct {
+		labeller sous.ImageLabeller
+		Client   rectificationClient
+		singFac  func(string) *singularity.Client
+		// DeployTimeout is how long Adopt waits for the deploy of the new
+		// request to become active, checking every PollInterval.
+		DeployTimeout, PollInterval time.Duration
+	}
+
+	// An Adoption is the deployment Sous would manage in place of an
+	// unmanaged Singularity request.
+	Adoption struct {
+		// RequestID is the ID of the unmanaged request.
+		RequestID string
+		// Deployment is what the request's current deploy amounts to. Its
+		// SourceID is recovered from the labels of the image deployed.
+		Deployment *sous.Deployment
+		// ImageName is the name of the image deployed.
+		ImageName string
+		// Err is the reason the request can't be adopted, if it can't.
+		Err error
+	}
+
+	adoptions []*Adoption
+)
+
+// NewAdopter creates an Adopter, which recovers SourceIDs with l, and changes
+// requests in Singularity with c.
+func NewAdopter(l sous.ImageLabeller, c rectificationClient) *Adopter {
+	return &Adopter{
+		labeller:      l,
+		Client:        c,
+		DeployTimeout: sous.SingularityDeployTimeout * time.Second,
+		PollInterval:  5 * time.Second,
+	}
+}
+
+// SetSingularityFactory sets the function the Adopter uses to make clients for
+// reading requests from Singularity.
+func (a *Adopter) SetSingularityFactory(fn func(string) *singularity.Client) {
+	a.singFac = fn
+}
+
+func (a *Adopter) buildSingClient(url string) *singularity.Client {
+	if a.singFac == nil {
+		return singularity.NewClient(url)
+	}
+	return a.singFac(url)
+}
+
+// Unmanaged lists the unmanaged requests in the Singularities of clusters,
+// ordered by request ID, with the deployments they'd be adopted as. Requests
+// that can't be adopted, e.g. because their image has no Sous labels, are
+// listed with the reason.
+func (a *Adopter) Unmanaged(clusters sous.Clusters) ([]*Adoption, error) {
+	byURL := map[string]sous.Clusters{}
+	for name, c := range clusters {
+		if byURL[c.BaseURL] == nil {
+			byURL[c.BaseURL] = sous.Clusters{}
+		}
+		byURL[c.BaseURL][name] = c
+	}
+
+	var ads adoptions
+	for url, cs := range byURL {
+		client := a.buildSingClient(url)
+		rps, err := client.GetRequests()
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting requests from %s", url)
+		}
+		for _, rp := range rps {
+			if rp.Request == nil {
+				continue
+			}
+			if _, err := ParseRequestID(rp.Request.Id); err == nil {
+				continue
+			}
+			ads = append(ads, a.propose(cs, SingReq{url, client, rp}))
+		}
+	}
+	sort.Sort(ads)
+	return ads, nil
+}
+
+// propose works out the deployment Sous would adopt req as.
+func (a *Adopter) propose(clusters sous.Clusters, req SingReq) *Adoption {
+	db := deploymentBuilder{registry: a.labeller, clusters: clusters, req: req}
+	db.Target.Cluster = &sous.Cluster{BaseURL: req.SourceURL}
+	db.request = req.ReqParent.Request
+	ad := &Adoption{RequestID: db.request.Id, Err: db.completeConstruction()}
+	if c, ok := clusters[db.Target.ClusterName]; ok {
+		db.Target.Cluster = c
+	}
+	ad.Deployment = &db.Target.Deployment
+	ad.ImageName = db.imageName
+	return ad
+}
+
+// Manifest returns the manifest that ad's deployment would be recorded in
+// the GDM as.
+func (ad *Adoption) Manifest(defs sous.Defs) (*sous.Manifest, error) {
+	if ad.Err != nil {
+		return nil, ad.Err
+	}
+	ms, err := sous.NewDeployments(ad.Deployment.Clone()).Manifests(defs)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := ms.Get(ad.Deployment.ManifestID())
+	if !ok {
+		return nil, errors.Errorf("no manifest for %s", ad.RequestID)
+	}
+	return m, nil
+}
+
+// Adopt brings the request of ad under Sous' management. As Singularity
+// can't rename a request, it creates and deploys the request Sous would have
+// made for the deployment, waits for the deploy to become active, then calls
+// record, which should add the deployment to the GDM, and only then deletes
+// the unmanaged request, so that the service keeps running throughout. If the
+// deploy fails, or record does, the new request is deleted again.
+func (a *Adopter) Adopt(ad *Adoption, record func() error) error {
+	if ad.Err != nil {
+		return errors.Wrapf(ad.Err, "can't adopt %s", ad.RequestID)
+	}
+	d := sous.Deployable{
+		Deployment:    ad.Deployment,
+		BuildArtifact: sous.NewBuildArtifact(ad.ImageName, nil),
+	}
+	reqID := MakeRequestID(d.ID())
+	if reqID == ad.RequestID {
+		return errors.Errorf("%s is already managed by Sous", ad.RequestID)
+	}
+	Log.Info.Printf("Adopting %s as %s", ad.RequestID, reqID)
+	if err := a.Client.PostRequest(d, reqID); err != nil {
+		return errors.Wrapf(err, "creating request %s", reqID)
+	}
+	err := a.Client.Deploy(d, reqID)
+	if err != nil {
+		err = errors.Wrapf(err, "deploying request %s", reqID)
+	} else if err = a.awaitActive(d.Cluster.BaseURL, reqID); err == nil {
+		if err = record(); err != nil {
+			err = errors.Wrapf(err, "recording %s", reqID)
+		}
+	}
+	if err != nil {
+		return a.rollBack(d.Cluster.BaseURL, reqID, ad.RequestID, err)
+	}
+	return errors.Wrapf(a.Client.DeleteRequest(d.Cluster.BaseURL, ad.RequestID, "adopted as "+reqID),
+		"deleting request %s", ad.RequestID)
+}
+
+// awaitActive polls the deploy history of reqID until its latest deploy
+// succeeds. It returns an error if the deploy fails, or hasn't succeeded
+// within DeployTimeout.
+func (a *Adopter) awaitActive(url, reqID string) error {
+	client := a.buildSingClient(url)
+	deadline := time.Now().Add(a.DeployTimeout)
+	for {
+		result, err := latestDeployResult(client, reqID)
+		if err == nil && result != nil {
+			if result.DeployState == dtos.SingularityDeployResultDeployStateSUCCEEDED {
+				return nil
+			}
+			if result.Message != "" {
+				return errors.Errorf("deploy of %s was %s: %s", reqID, result.DeployState, result.Message)
+			}
+			return errors.Errorf("deploy of %s was %s", reqID, result.DeployState)
+		}
+		if !time.Now().Before(deadline) {
+			if err != nil {
+				return errors.Wrapf(err, "checking deploy of %s", reqID)
+			}
+			return errors.Errorf("deploy of %s wasn't active after %s", reqID, a.DeployTimeout)
+		}
+		time.Sleep(a.PollInterval)
+	}
+}
+
+// latestDeployResult returns the result of the latest deploy of reqID, or
+// nil if it hasn't finished yet.
+func latestDeployResult(client *singularity.Client, reqID string) (*dtos.SingularityDeployResult, error) {
+	history, err := client.GetDeploys(reqID, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 || history[0].DeployMarker == nil {
+		return nil, nil
+	}
+	dh, err := client.GetDeploy(reqID, history[0].DeployMarker.DeployId)
+	if err != nil {
+		return nil, err
+	}
+	return dh.DeployResult, nil
+}
+
+// rollBack deletes the request reqID made to adopt original, which is left
+// running, and returns cause, the reason for rolling back.
+func (a *Adopter) rollBack(url, reqID, original string, cause error) error {
+	Log.Warn.Printf("Not adopting %s: %v", original, cause)
+	if err := a.Client.DeleteRequest(url, reqID, "adopting "+original+" failed"); err != nil {
+		return errors.Errorf("adopting %s: %v; deleting %s failed too: %v", original, cause, reqID, err)
+	}
+	return errors.Wrapf(cause, "adopting %s, so deleted %s", original, reqID)
+}
+
+func (ads adoptions) Len() int           { return len(ads) }
+func (ads adoptions) Swap(i, j int)      { ads[i], ads[j] = ads[j], ads[i] }
+func (ads adoptions) Less(i, j int) bool { return ads[i].RequestID < ads[j].RequestID }
@@ -44,6 +44,15 @@ type (
 
 		// DeleteRequest instructs Singularity to delete a particular request
 		DeleteRequest(cluster, reqID, message string) error
+
+		// Scale changes the number of instances of a particular request
+		Scale(cluster, reqID string, instanceCount int, message string) error
+
+		// Pause pauses a particular request, killing its tasks
+		Pause(cluster, reqID, message string) error
+
+		// Unpause resumes a particular paused request
+		Unpause(cluster, reqID, message string) error
 	}
 
 	// DTOMap is shorthand for map[string]interface{}
@@ -106,7 +115,15 @@ func (r *deployer) RectifySingleCreate(d *sous.Deployable) (err error) {
 	if err = r.Client.PostRequest(*d, reqID); err != nil {
 		return err
 	}
-	return r.Client.Deploy(*d, reqID)
+	// Singularity doesn't deploy to paused requests, so a deployment created
+	// paused is deployed first, and then paused.
+	if err = r.Client.Deploy(*d, reqID); err != nil {
+		return err
+	}
+	if d.Paused {
+		return r.Client.Pause(d.Cluster.BaseURL, reqID, "created paused")
+	}
+	return nil
 }
 
 func (r *deployer) RectifyDeletes(dc <-chan *sous.Deployable, errs chan<- sous.DiffResolution) {
@@ -155,6 +172,21 @@ func (r *deployer) RectifyModifies(
 func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err error) {
 	Log.Debug.Printf("Rectifying modified %q: \n  %# v \n    =>  \n  %# v", pair.ID(), pair.Prior.Deployment, pair.Post.Deployment)
 	defer rectifyRecover(pair, "RectifySingleModification", &err)
+	// Singularity doesn't deploy to paused requests, so changes to a paused
+	// deployment wait until it's resumed.
+	if pair.Post.Paused {
+		if pair.Prior.Paused {
+			return nil
+		}
+		Log.Debug.Printf("Pausing...")
+		return r.Client.Pause(pair.Post.Cluster.BaseURL, computeRequestID(pair.Prior), "paused in the GDM")
+	}
+	if pair.Prior.Paused {
+		Log.Debug.Printf("Resuming...")
+		if err := r.Client.Unpause(pair.Post.Cluster.BaseURL, computeRequestID(pair.Prior), "resumed in the GDM"); err != nil {
+			return err
+		}
+	}
 	if r.changesReq(pair) {
 		Log.Debug.Printf("Updating Request...")
 		if err := r.Client.PostRequest(*pair.Post, computeRequestID(pair.Post)); err != nil {
@@ -172,6 +204,12 @@ func (r *deployer) RectifySingleModification(pair *sous.DeployablePair) (err err
 	return nil
 }
 
+// Scale implements sous.Scaler on deployer.
+func (r *deployer) Scale(d *sous.Deployment, instances int, message string) error {
+	reqID := computeRequestID(&sous.Deployable{Deployment: d})
+	return r.Client.Scale(d.Cluster.BaseURL, reqID, instances, message)
+}
+
 func (r deployer) changesReq(pair *sous.DeployablePair) bool {
 	return pair.Prior.NumInstances != pair.Post.NumInstances
 }
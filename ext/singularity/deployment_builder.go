@@ -90,6 +90,7 @@ func (db *deploymentBuilder) completeConstruction() error {
 		db.assignClusterName,
 		db.unpackDeployConfig,
 		db.determineManifestKind,
+		db.determinePaused,
 	)
 }
 
@@ -328,6 +329,13 @@ func (db *deploymentBuilder) unpackDeployConfig() error {
 	return nil
 }
 
+// determinePaused marks the deployment paused if its request is, so that
+// the resolver leaves it paused while the GDM says it should be.
+func (db *deploymentBuilder) determinePaused() error {
+	db.Target.Paused = db.req.ReqParent.State == dtos.SingularityRequestParentRequestStatePAUSED
+	return nil
+}
+
 func (db *deploymentBuilder) determineManifestKind() error {
 	switch db.request.RequestType {
 	default:
@@ -177,6 +177,12 @@ func TestBuildDeployment(t *testing.T) {
 
 	assert.Equal(t, actual.ClusterName, expected.ClusterName)
 	assert.Equal(t, actual.Status, expected.Status)
+	assert.False(t, actual.Paused)
+
+	req.ReqParent.State = dtos.SingularityRequestParentRequestStatePAUSED
+	actual, err = BuildDeployment(fakeReg, testClusters, req)
+	assert.NoError(t, err)
+	assert.True(t, actual.Paused)
 }
 
 func TestBuildDeployment_failed_deploy(t *testing.T) {
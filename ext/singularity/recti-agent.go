@@ -60,7 +60,13 @@ func (ra *RectiAgent) Deploy(d sous.Deployable, reqID string) error {
 	if d.BuildArtifact == nil {
 		return &sous.MissingImageNameError{Cause: fmt.Errorf("Missing BuildArtifact on Deployable")}
 	}
+	if t := d.BuildArtifact.Type; t != "" && t != sous.ArtifactTypeDocker {
+		return fmt.Errorf("Singularity can only deploy Docker images, not %s artifacts like %s", t, d.BuildArtifact.Name)
+	}
 	dockerImage := d.BuildArtifact.Name
+	if !strings.Contains(dockerImage, "@") {
+		Log.Warn.Printf("Deploying %q by tag: its digest is unknown, so pushing the tag again will change what runs", dockerImage)
+	}
 	clusterURI := d.Deployment.Cluster.BaseURL
 	labels, err := ra.labeller.ImageLabels(dockerImage)
 	if err != nil {
@@ -226,6 +232,35 @@ func (ra *RectiAgent) Scale(cluster, reqID string, instanceCount int, message st
 	return err
 }
 
+// Pause sends a request to Singularity to pause a request, which kills its
+// tasks, but keeps the request and its history.
+func (ra *RectiAgent) Pause(cluster, reqID, message string) error {
+	Log.Debug.Printf("Pausing %s %s %s", cluster, reqID, message)
+	pr, err := swaggering.LoadMap(&dtos.SingularityPauseRequest{}, dtoMap{
+		"ActionId": "SOUS_RECTIFY_" + StripDeployID(uuid.NewV4().String()),
+		"Message":  "Sous: " + message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).Pause(reqID, pr.(*dtos.SingularityPauseRequest))
+	return err
+}
+
+// Unpause sends a request to Singularity to resume a paused request.
+func (ra *RectiAgent) Unpause(cluster, reqID, message string) error {
+	Log.Debug.Printf("Unpausing %s %s %s", cluster, reqID, message)
+	ur, err := swaggering.LoadMap(&dtos.SingularityUnpauseRequest{}, dtoMap{
+		"ActionId": "SOUS_RECTIFY_" + StripDeployID(uuid.NewV4().String()),
+		"Message":  "Sous: " + message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ra.singularityClient(cluster).Unpause(reqID, ur.(*dtos.SingularityUnpauseRequest))
+	return err
+}
+
 func (ra *RectiAgent) getSingularityClient(url string) (*singularity.Client, bool) {
 	ra.RLock()
 	defer ra.RUnlock()
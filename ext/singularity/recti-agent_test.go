@@ -98,3 +98,14 @@ func TestFailOnNilBuildArtifact(t *testing.T) {
 		t.Fatal("Deploy did not return an error when given a sous.Deployable with an empty BuildArtifact")
 	}
 }
+
+func TestFailOnFileArtifact(t *testing.T) {
+	r := sous.NewDummyRegistry()
+	d := sous.Deployable{
+		BuildArtifact: &sous.BuildArtifact{Name: "/tmp/image.tar", Type: sous.ArtifactTypeDockerArchive},
+	}
+	ra := NewRectiAgent(r)
+	if err := ra.Deploy(d, "testReq"); err == nil {
+		t.Fatal("Deploy did not return an error when given a sous.Deployable with a docker-archive artifact")
+	}
+}
@@ -235,6 +235,68 @@ func TestModifyResources(t *testing.T) {
 	}
 }
 
+func TestModifyPause(t *testing.T) {
+	assert := assert.New(t)
+
+	pair := baseDeployablePair()
+	pair.Post.Deployment.Paused = true
+	pair.Post.Deployment.DeployConfig.NumInstances = 24
+
+	mods := make(chan *sous.DeployablePair, 1)
+	log := make(chan sous.DiffResolution, 10)
+
+	client := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(client)
+
+	mods <- pair
+	close(mods)
+	deployer.RectifyModifies(mods, log)
+	close(log)
+
+	for e := range log {
+		if e.Error != nil {
+			t.Error(e)
+		}
+	}
+
+	assert.Len(client.Paused, 1)
+	assert.Len(client.Unpaused, 0)
+	assert.Len(client.Created, 0, "changes wait until it's resumed")
+	assert.Len(client.Deployed, 0)
+}
+
+func TestModifyResume(t *testing.T) {
+	assert := assert.New(t)
+
+	pair := baseDeployablePair()
+	pair.Prior.Deployment.Paused = true
+	pair.Prior.Deployment.DeployConfig.NumInstances = 12
+	pair.Post.Deployment.DeployConfig.NumInstances = 24
+
+	mods := make(chan *sous.DeployablePair, 1)
+	log := make(chan sous.DiffResolution, 10)
+
+	client := sous.NewDummyRectificationClient()
+	deployer := NewDeployer(client)
+
+	mods <- pair
+	close(mods)
+	deployer.RectifyModifies(mods, log)
+	close(log)
+
+	for e := range log {
+		if e.Error != nil {
+			t.Error(e)
+		}
+	}
+
+	assert.Len(client.Paused, 0)
+	assert.Len(client.Unpaused, 1)
+	if assert.Len(client.Created, 1) {
+		assert.Equal(24, client.Created[0].Deployment.DeployConfig.NumInstances)
+	}
+}
+
 func TestModify(t *testing.T) {
 	assert := assert.New(t)
 	before := "1.2.3-test"
@@ -384,3 +446,69 @@ func TestCreates(t *testing.T) {
 		assert.Equal(12, req.Deployment.DeployConfig.NumInstances)
 	}
 }
+
+// callOrderClient records the order in which a deployer calls the client.
+type callOrderClient struct {
+	*sous.DummyRectificationClient
+	calls []string
+}
+
+func (c *callOrderClient) PostRequest(d sous.Deployable, reqID string) error {
+	c.calls = append(c.calls, "create")
+	return c.DummyRectificationClient.PostRequest(d, reqID)
+}
+
+func (c *callOrderClient) Pause(cluster, reqID, message string) error {
+	c.calls = append(c.calls, "pause")
+	return c.DummyRectificationClient.Pause(cluster, reqID, message)
+}
+
+func (c *callOrderClient) Deploy(d sous.Deployable, reqID string) error {
+	c.calls = append(c.calls, "deploy")
+	return c.DummyRectificationClient.Deploy(d, reqID)
+}
+
+func TestCreatesPaused(t *testing.T) {
+	created := &sous.Deployable{
+		BuildArtifact: &sous.BuildArtifact{
+			Type: "docker",
+			Name: "reqid,0.0.0",
+		},
+		Deployment: &sous.Deployment{
+			SourceID: sous.SourceID{
+				Location: sous.SourceLocation{
+					Repo: "reqid",
+				},
+			},
+			DeployConfig: sous.DeployConfig{
+				NumInstances: 12,
+			},
+			Cluster:     &sous.Cluster{BaseURL: "cluster"},
+			ClusterName: "nick",
+			Paused:      true,
+		},
+	}
+
+	crts := make(chan *sous.Deployable, 1)
+	log := make(chan sous.DiffResolution, 10)
+
+	client := &callOrderClient{DummyRectificationClient: sous.NewDummyRectificationClient()}
+	deployer := NewDeployer(client)
+
+	crts <- created
+	close(crts)
+	deployer.RectifyCreates(crts, log)
+	close(log)
+
+	for e := range log {
+		if e.Error != nil {
+			t.Error(e)
+		}
+	}
+
+	assert.Equal(t, []string{"create", "deploy", "pause"}, client.calls,
+		"Singularity doesn't deploy to paused requests")
+	if assert.Len(t, client.Paused, 1) {
+		assert.Equal(t, "reqid::nick", client.Paused[0].Reqid)
+	}
+}
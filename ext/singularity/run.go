@@ -0,0 +1,146 @@
+package singularity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/swaggering"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Runner triggers runs of on-demand and run-once deployments, and
+	// reports on the tasks they start.
+	Runner struct {
+		*TaskReader
+	}
+
+	// A RunRequest asks for a run of a deployment.
+	RunRequest struct {
+		// ID identifies the run. It's chosen by whoever asks for the run, so
+		// that they can find its task afterwards.
+		ID string
+		// Args, if not empty, replace the deployment's Args for this run.
+		Args []string
+		// Env is added to the deployment's Env for this run.
+		Env map[string]string
+	}
+
+	// runNowRequest is a SingularityRunNowRequest. The vendored DTO of that
+	// name lacks envOverrides.
+	runNowRequest struct {
+		RunID           string            `json:"runId"`
+		Message         string            `json:"message,omitempty"`
+		CommandLineArgs []string          `json:"commandLineArgs,omitempty"`
+		EnvOverrides    map[string]string `json:"envOverrides,omitempty"`
+	}
+)
+
+// NewRunner creates a Runner, which reads tasks with tr.
+func NewRunner(tr *TaskReader) *Runner {
+	return &Runner{TaskReader: tr}
+}
+
+// Runnable returns an error unless m is of a kind that's run on demand, and is
+// deployed to the cluster named.
+func Runnable(m *sous.Manifest, cluster string) error {
+	if m.Kind != sous.ManifestKindOnDemand && m.Kind != sous.ManifestKindOnce {
+		return errors.Errorf("%v is a %s: only %s and %s manifests can be run",
+			m.ID(), m.Kind, sous.ManifestKindOnDemand, sous.ManifestKindOnce)
+	}
+	if _, ok := m.Deployments[cluster]; !ok {
+		return errors.Errorf("%v isn't deployed to %q", m.ID(), cluster)
+	}
+	return nil
+}
+
+// Trigger asks Singularity to run the deployment of m in cluster now, as by
+// is recorded as having asked.
+func (r *Runner) Trigger(clusters sous.Clusters, m *sous.Manifest, cluster string, rr RunRequest, by sous.User) error {
+	if err := Runnable(m, cluster); err != nil {
+		return err
+	}
+	if rr.ID == "" {
+		return errors.Errorf("no run ID given")
+	}
+	did := sous.DeployID{ManifestID: m.ID(), Cluster: cluster}
+	baseURL, err := clusterURL(clusters, did)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(runNowRequest{
+		RunID:           rr.ID,
+		Message:         fmt.Sprintf("Triggered by %s with sous", by),
+		CommandLineArgs: rr.Args,
+		EnvOverrides:    rr.Env,
+	})
+	if err != nil {
+		return err
+	}
+	reqID := MakeRequestID(did)
+	// This doesn't use the client's ScheduleImmediately, whose request can't
+	// carry env.
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return errors.Wrapf(err, "parsing Singularity URL %q", baseURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/api/requests/request/" + reqID + "/run"
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Post(u.String(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "running %s", reqID)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return errors.Errorf("running %s: %s: %s", reqID, res.Status, msg)
+	}
+	return nil
+}
+
+// RunTask returns the task of the deployment did's run runID, or nil if
+// Singularity hasn't launched it yet.
+func (r *Runner) RunTask(clusters sous.Clusters, did sous.DeployID, runID string) (*Task, error) {
+	baseURL, err := clusterURL(clusters, did)
+	if err != nil {
+		return nil, err
+	}
+	reqID := MakeRequestID(did)
+	client := r.buildSingClient(baseURL)
+	h, err := client.GetTaskHistoryForRequestAndRunId(reqID, runID)
+	if re, ok := errors.Cause(err).(*swaggering.ReqError); ok && re.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding run %s of %s", runID, reqID)
+	}
+	t, err := taskFromHistory(client, h, true)
+	if err != nil {
+		return nil, err
+	}
+	t.Active = !t.Finished()
+	return &t, nil
+}
+
+// Finished returns true if t's last state is one tasks end in.
+func (t Task) Finished() bool {
+	switch t.State {
+	case "TASK_FINISHED", "TASK_FAILED", "TASK_KILLED", "TASK_LOST", "TASK_ERROR":
+		return true
+	}
+	return false
+}
+
+// Succeeded returns true if t finished by exiting with status 0.
+func (t Task) Succeeded() bool {
+	return t.State == "TASK_FINISHED"
+}
@@ -0,0 +1,69 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/swaggering"
+)
+
+func TestRunner(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := fake_singularity.NewServer()
+	defer s.Close()
+	clusters := sous.Clusters{"test": {Name: "test", BaseURL: s.URL}}
+	m := &sous.Manifest{
+		Source:      sous.SourceLocation{Repo: "github.com/user/job"},
+		Kind:        sous.ManifestKindOnDemand,
+		Deployments: sous.DeploySpecs{"test": {}},
+	}
+	did := sous.DeployID{ManifestID: m.ID(), Cluster: "test"}
+	reqID := MakeRequestID(did)
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(err)
+	r := NewRunner(NewTaskReader())
+	_, err = r.buildSingClient(s.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(err)
+
+	task, err := r.RunTask(clusters, did, "run1")
+	require.NoError(err)
+	assert.Nil(task, "not run yet")
+
+	user := sous.User{Name: "Judson", Email: "jdl@example.com"}
+	rr := RunRequest{ID: "run1", Args: []string{"-v", "x"}, Env: map[string]string{"A": "1"}}
+	require.NoError(r.Trigger(clusters, m, "test", rr, user))
+
+	taskID := s.RunTaskID(reqID, "run1")
+	require.NotEmpty(taskID)
+	s.UpdateTask(taskID, func(t *fake_singularity.Task) {
+		assert.Equal([]string{"-v", "x"}, t.Args)
+		assert.Equal(map[string]string{"A": "1"}, t.Env)
+		assert.Contains(t.RunMessage, "jdl@example.com")
+	})
+
+	task, err = r.RunTask(clusters, did, "run1")
+	require.NoError(err)
+	require.NotNil(task)
+	assert.Equal(taskID, task.ID)
+	assert.True(task.Active)
+	assert.False(task.Finished())
+
+	s.UpdateTask(taskID, func(t *fake_singularity.Task) { t.Active, t.State = false, "TASK_FAILED" })
+	task, err = r.RunTask(clusters, did, "run1")
+	require.NoError(err)
+	assert.True(task.Finished())
+	assert.False(task.Succeeded())
+	assert.False(task.Active)
+
+	assert.Error(r.Trigger(clusters, m, "test", rr, user), "the same run ID again")
+	assert.Error(r.Trigger(clusters, m, "test", RunRequest{}, user), "no run ID")
+	assert.Error(r.Trigger(clusters, m, "other", RunRequest{ID: "run2"}, user), "not deployed there")
+	m.Kind = sous.ManifestKindService
+	assert.Error(r.Trigger(clusters, m, "test", RunRequest{ID: "run2"}, user), "services aren't run")
+}
@@ -0,0 +1,230 @@
+package singularity
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A TaskReader lists the Singularity tasks of deployments, and reads
+	// their logs, so that failed deploys can be investigated without going to
+	// the Singularity UI.
+	TaskReader struct {
+		// HTTPClient reads task logs. It defaults to http.DefaultClient.
+		HTTPClient *http.Client
+		singFac    func(string) *singularity.Client
+	}
+
+	// A Task is one run of a deployment's container, on one host.
+	Task struct {
+		ID   string
+		Host string
+		// Started is when Singularity launched the task.
+		Started time.Time
+		// Active is true if the task hasn't finished yet.
+		Active bool
+		// State is the state of the task's last update, e.g. TASK_RUNNING or
+		// TASK_FAILED.
+		State string
+		// Message is the status message of the task's last update, which
+		// gives the exit status of finished tasks.
+		Message string
+	}
+
+	// A LogChunk is a piece of a task's log, starting Offset bytes in.
+	LogChunk struct {
+		Data string
+		// Offset is the offset of Data in the log, and NextOffset the offset
+		// of the byte after it, from which to read the next chunk.
+		Offset, NextOffset int64
+	}
+)
+
+// TaskLogFiles are the logs that ReadLog can read.
+var TaskLogFiles = []string{"stdout", "stderr"}
+
+// logChunkLength is the most ReadLog asks Singularity for at once.
+const logChunkLength = 65536
+
+// NewTaskReader creates a TaskReader.
+func NewTaskReader() *TaskReader {
+	return &TaskReader{}
+}
+
+// SetSingularityFactory sets the function the TaskReader uses to make clients
+// for reading task histories from Singularity.
+func (tr *TaskReader) SetSingularityFactory(fn func(string) *singularity.Client) {
+	tr.singFac = fn
+}
+
+func (tr *TaskReader) buildSingClient(url string) *singularity.Client {
+	if tr.singFac == nil {
+		return singularity.NewClient(url)
+	}
+	return tr.singFac(url)
+}
+
+// DeploymentTasks lists the active tasks of the deployment did, followed by
+// at most recent of its finished ones, newest first.
+func (tr *TaskReader) DeploymentTasks(clusters sous.Clusters, did sous.DeployID, recent int) ([]Task, error) {
+	baseURL, err := clusterURL(clusters, did)
+	if err != nil {
+		return nil, err
+	}
+	return tr.Tasks(baseURL, MakeRequestID(did), recent)
+}
+
+// DeploymentLog reads a chunk of the log file, one of TaskLogFiles, of the
+// deployment did's task taskID, from offset on.
+func (tr *TaskReader) DeploymentLog(clusters sous.Clusters, did sous.DeployID, taskID, file string, offset int64) (LogChunk, error) {
+	baseURL, err := clusterURL(clusters, did)
+	if err != nil {
+		return LogChunk{}, err
+	}
+	// Singularity task IDs begin with the ID of their request, so this stops
+	// the logs of other deployments being read in the name of this one.
+	if reqID := MakeRequestID(did); !strings.HasPrefix(taskID, reqID+"-") {
+		return LogChunk{}, errors.Errorf("task %q isn't one of %v's, which begin %q", taskID, did, reqID)
+	}
+	return tr.ReadLog(baseURL, taskID, file, offset)
+}
+
+func clusterURL(clusters sous.Clusters, did sous.DeployID) (string, error) {
+	cluster, ok := clusters[did.Cluster]
+	if !ok {
+		return "", errors.Errorf("no cluster named %q", did.Cluster)
+	}
+	return cluster.BaseURL, nil
+}
+
+// Tasks lists the active tasks of the request requestID in the Singularity at
+// baseURL, followed by at most recent of its finished ones, newest first.
+func (tr *TaskReader) Tasks(baseURL, requestID string, recent int) ([]Task, error) {
+	client := tr.buildSingClient(baseURL)
+	active, err := client.GetTaskHistoryForActiveRequest(requestID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing active tasks of %s", requestID)
+	}
+	tasks := []Task{}
+	seen := map[string]bool{}
+	for _, h := range active {
+		t, err := taskFromHistory(client, h, true)
+		if err != nil {
+			return nil, err
+		}
+		seen[t.ID] = true
+		tasks = append(tasks, t)
+	}
+	if recent <= 0 {
+		return tasks, nil
+	}
+
+	// The vendored client doesn't send query parameters, so Singularity
+	// chooses how many tasks to list, and they're trimmed here.
+	inactive, err := client.GetTaskHistoryForRequest(requestID, "", "", "", 0, 0, "DESC", int32(recent), 1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing tasks of %s", requestID)
+	}
+	for _, h := range inactive {
+		if len(tasks)-len(seen) == recent {
+			break
+		}
+		if h.TaskId == nil || seen[h.TaskId.Id] {
+			continue
+		}
+		t, err := taskFromHistory(client, h, false)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// taskFromHistory describes the task h, with the state of its latest update.
+func taskFromHistory(client *singularity.Client, h *dtos.SingularityTaskIdHistory, active bool) (Task, error) {
+	if h.TaskId == nil {
+		return Task{}, errors.Errorf("task history %+v has no task ID", h)
+	}
+	t := Task{
+		ID:      h.TaskId.Id,
+		Host:    h.TaskId.Host,
+		Started: time.Unix(0, h.TaskId.StartedAt*int64(time.Millisecond)),
+		Active:  active,
+	}
+	history, err := client.GetHistoryForTask(t.ID)
+	if err != nil {
+		return Task{}, errors.Wrapf(err, "getting history of task %s", t.ID)
+	}
+	var last *dtos.SingularityTaskHistoryUpdate
+	for _, u := range history.TaskUpdates {
+		if last == nil || u.Timestamp >= last.Timestamp {
+			last = u
+		}
+	}
+	if last != nil {
+		t.State, t.Message = string(last.TaskState), last.StatusMessage
+	}
+	return t, nil
+}
+
+// ReadLog reads a chunk of the log file, one of TaskLogFiles, of the task
+// taskID in the Singularity at baseURL, from offset on. The chunk is empty if
+// there's nothing more to read yet.
+func (tr *TaskReader) ReadLog(baseURL, taskID, file string, offset int64) (LogChunk, error) {
+	if !isTaskLogFile(file) {
+		return LogChunk{}, errors.Errorf("can't read %q, only %s", file, strings.Join(TaskLogFiles, " or "))
+	}
+	// This doesn't use the client's Read, because the vendored client doesn't
+	// send query parameters, and Singularity needs them to find the file.
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return LogChunk{}, errors.Wrapf(err, "parsing Singularity URL %q", baseURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/api/sandbox/" + taskID + "/read"
+	u.RawQuery = url.Values{
+		"path":   {file},
+		"offset": {strconv.FormatInt(offset, 10)},
+		"length": {strconv.Itoa(logChunkLength)},
+	}.Encode()
+	client := tr.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Get(u.String())
+	if err != nil {
+		return LogChunk{}, errors.Wrapf(err, "reading %s of task %s", file, taskID)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return LogChunk{}, errors.Errorf("reading %s of task %s: %s", file, taskID, res.Status)
+	}
+	chunk := dtos.MesosFileChunkObject{}
+	if err := json.NewDecoder(res.Body).Decode(&chunk); err != nil {
+		return LogChunk{}, errors.Wrapf(err, "reading %s of task %s", file, taskID)
+	}
+	next := chunk.NextOffset
+	if next == 0 {
+		// Older Singularities leave nextOffset out.
+		next = chunk.Offset + int64(len(chunk.Data))
+	}
+	return LogChunk{Data: chunk.Data, Offset: chunk.Offset, NextOffset: next}, nil
+}
+
+func isTaskLogFile(file string) bool {
+	for _, f := range TaskLogFiles {
+		if f == file {
+			return true
+		}
+	}
+	return false
+}
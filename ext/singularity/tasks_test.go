@@ -0,0 +1,79 @@
+package singularity
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/go-singularity/dtos"
+	sous "github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/swaggering"
+)
+
+func TestTaskReader(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := fake_singularity.NewServer()
+	defer s.Close()
+	clusters := sous.Clusters{"test": {Name: "test", BaseURL: s.URL}}
+	did := sous.DeployID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/user/repo"}},
+		Cluster:    "test",
+	}
+	reqID := MakeRequestID(did)
+
+	tr := NewTaskReader()
+	tasks, err := tr.DeploymentTasks(clusters, did, 5)
+	require.NoError(err)
+	assert.Empty(tasks)
+
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(err)
+	_, err = tr.buildSingClient(s.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(err)
+	failed, err := s.AddTask(reqID, fake_singularity.Task{
+		Host: "host1", State: "TASK_FAILED", Message: "Exited with status 1",
+		Stdout: "starting\n", Stderr: "boom\n",
+	})
+	require.NoError(err)
+	_, err = s.AddTask(reqID, fake_singularity.Task{Host: "host2", State: "TASK_FAILED"})
+	require.NoError(err)
+	running, err := s.AddTask(reqID, fake_singularity.Task{Host: "host3", Active: true, State: "TASK_RUNNING"})
+	require.NoError(err)
+
+	tasks, err = tr.DeploymentTasks(clusters, did, 5)
+	require.NoError(err)
+	require.Len(tasks, 3)
+	assert.Equal(running, tasks[0].ID)
+	assert.True(tasks[0].Active)
+	assert.Equal("TASK_RUNNING", tasks[0].State)
+	assert.Equal("host2", tasks[1].Host)
+	assert.Equal(failed, tasks[2].ID)
+	assert.False(tasks[2].Active)
+	assert.Equal("Exited with status 1", tasks[2].Message)
+	assert.False(tasks[2].Started.IsZero())
+
+	tasks, err = tr.DeploymentTasks(clusters, did, 1)
+	require.NoError(err)
+	assert.Len(tasks, 2, "the running task and the latest finished one")
+
+	chunk, err := tr.DeploymentLog(clusters, did, failed, "stderr", 0)
+	require.NoError(err)
+	assert.Equal(LogChunk{Data: "boom\n", NextOffset: 5}, chunk)
+	chunk, err = tr.DeploymentLog(clusters, did, failed, "stdout", 3)
+	require.NoError(err)
+	assert.Equal(LogChunk{Data: "rting\n", Offset: 3, NextOffset: 9}, chunk)
+
+	_, err = tr.DeploymentLog(clusters, did, failed, "/etc/passwd", 0)
+	assert.Error(err)
+	other := did
+	other.Cluster = "other"
+	_, err = tr.DeploymentLog(clusters, other, failed, "stdout", 0)
+	assert.Error(err, "no such cluster")
+	other.ManifestID.Flavor = "f"
+	other.Cluster = "test"
+	_, err = tr.DeploymentLog(clusters, other, failed, "stdout", 0)
+	assert.Error(err, "task of another deployment")
+}
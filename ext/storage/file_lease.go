@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+// FileLeaseBackend implements sous.LeaseBackend by storing the lease as JSON
+// in a file, which must be on a filesystem shared by all the servers
+// campaigning for it. The file is locked with flock(2) while it is updated.
+type FileLeaseBackend struct {
+	Path string
+}
+
+// NewFileLeaseBackend creates a FileLeaseBackend storing its lease at path.
+func NewFileLeaseBackend(path string) *FileLeaseBackend {
+	return &FileLeaseBackend{Path: path}
+}
+
+// Acquire implements sous.LeaseBackend on FileLeaseBackend.
+func (fb *FileLeaseBackend) Acquire(holder string, now, expires time.Time) (sous.Lease, error) {
+	var lease sous.Lease
+	err := fb.update(func(current sous.Lease) sous.Lease {
+		lease = sous.AcquireLease(current, holder, now, expires)
+		return lease
+	})
+	return lease, err
+}
+
+// Release implements sous.LeaseBackend on FileLeaseBackend.
+func (fb *FileLeaseBackend) Release(holder string) error {
+	return fb.update(func(current sous.Lease) sous.Lease {
+		if current.Holder == holder {
+			return sous.Lease{}
+		}
+		return current
+	})
+}
+
+// update replaces the stored lease with the result of f, holding an
+// exclusive lock on the lease file while it does so.
+func (fb *FileLeaseBackend) update(f func(sous.Lease) sous.Lease) error {
+	file, err := os.OpenFile(fb.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "opening lease file %q", fb.Path)
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return errors.Wrapf(err, "locking lease file %q", fb.Path)
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		return errors.Wrapf(err, "reading lease file %q", fb.Path)
+	}
+	var current sous.Lease
+	if len(b) != 0 {
+		if err := json.Unmarshal(b, &current); err != nil {
+			return errors.Wrapf(err, "parsing lease file %q", fb.Path)
+		}
+	}
+	next := f(current)
+	if next == current {
+		return nil
+	}
+	if b, err = json.Marshal(next); err != nil {
+		return err
+	}
+	if err := file.Truncate(0); err != nil {
+		return errors.Wrapf(err, "truncating lease file %q", fb.Path)
+	}
+	if _, err := file.WriteAt(b, 0); err != nil {
+		return errors.Wrapf(err, "writing lease file %q", fb.Path)
+	}
+	return nil
+}
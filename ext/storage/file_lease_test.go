@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLeaseBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "lease.json")
+	left, right := NewFileLeaseBackend(path), NewFileLeaseBackend(path)
+	now := time.Unix(1000000, 0).UTC()
+
+	lease, err := left.Acquire("left", now, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Holder != "left" {
+		t.Errorf("got lease for %q, want left", lease.Holder)
+	}
+
+	lease, err = right.Acquire("right", now.Add(time.Second), now.Add(time.Minute+time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Holder != "left" {
+		t.Errorf("lease held by %q was taken by %q", "left", lease.Holder)
+	}
+
+	if err := left.Release("left"); err != nil {
+		t.Fatal(err)
+	}
+	lease, err = right.Acquire("right", now.Add(2*time.Second), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lease.Holder != "right" {
+		t.Errorf("released lease not acquired: held by %q", lease.Holder)
+	}
+}
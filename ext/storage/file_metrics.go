@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"io/ioutil"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+// FileMetricsSource implements sous.MetricsSource by reading metrics from a
+// YAML file, mapping manifest IDs to cluster names to metric names to values:
+//
+//     github.com/opentable/example~canary:
+//         cluster-1:
+//             cpu: 0.8
+//
+// The file is re-read every time a metric is requested, so it can be edited
+// to simulate changing load. It's intended for testing autoscaling policies.
+type FileMetricsSource struct {
+	Path string
+}
+
+// NewFileMetricsSource creates a FileMetricsSource reading metrics from path.
+func NewFileMetricsSource(path string) *FileMetricsSource {
+	return &FileMetricsSource{Path: path}
+}
+
+// Metric implements sous.MetricsSource on FileMetricsSource.
+func (fm *FileMetricsSource) Metric(id sous.DeployID, name string) (float64, error) {
+	b, err := ioutil.ReadFile(fm.Path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading metrics file %q", fm.Path)
+	}
+	metrics := map[string]map[string]map[string]float64{}
+	if err := yaml.Unmarshal(b, &metrics); err != nil {
+		return 0, errors.Wrapf(err, "parsing metrics file %q", fm.Path)
+	}
+	v, ok := metrics[id.ManifestID.String()][id.Cluster][name]
+	if !ok {
+		return 0, errors.Errorf("no value for metric %q of %v in %q", name, id, fm.Path)
+	}
+	return v, nil
+}
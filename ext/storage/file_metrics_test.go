@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentable/sous/lib"
+)
+
+func TestFileMetricsSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "metrics.yaml")
+	contents := "github.com/example/app~canary:\n  cluster-1:\n    cpu: 0.8\n"
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fm := NewFileMetricsSource(path)
+	id := sous.DeployID{
+		ManifestID: sous.MustParseManifestID("github.com/example/app~canary"),
+		Cluster:    "cluster-1",
+	}
+	v, err := fm.Metric(id, "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0.8 {
+		t.Errorf("got cpu %v; want 0.8", v)
+	}
+	if _, err := fm.Metric(id, "memory"); err == nil {
+		t.Errorf("missing metric returned nil error")
+	}
+	id.Cluster = "cluster-2"
+	if _, err := fm.Metric(id, "cpu"); err == nil {
+		t.Errorf("missing cluster returned nil error")
+	}
+}
@@ -1,12 +1,15 @@
 package graph
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log" //ok
 	"os"
 	"os/user"
+	"strings"
+	"time"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/ext/docker"
@@ -17,6 +20,7 @@ import (
 	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/cmdr"
 	"github.com/opentable/sous/util/docker_registry"
+	"github.com/opentable/sous/util/restful"
 	"github.com/opentable/sous/util/shell"
 	"github.com/pkg/errors"
 	"github.com/samsalisbury/psyringe"
@@ -89,6 +93,9 @@ type (
 	// TargetManifestID is the manifest ID being targeted, after resolving all
 	// context and flags.
 	TargetManifestID sous.ManifestID
+	// ServerTLSConfig is the TLS configuration the Sous server listens with.
+	// If its Config is nil, the server listens without TLS.
+	ServerTLSConfig struct{ *tls.Config }
 	// DryrunOption specifies components that should be faked in an execution.
 	DryrunOption string
 	// SourceContextDiscovery captures the possiblity of not finding a SourceContext
@@ -203,6 +210,8 @@ func AddDocker(graph adder) {
 		newDockerRegistry,
 		newDockerBuilder,
 		newSelector,
+		newHarvester,
+		newGarbageCollector,
 	)
 }
 
@@ -210,6 +219,9 @@ func AddDocker(graph adder) {
 func AddSingularity(graph adder) {
 	graph.Add(
 		newDeployer,
+		newAdopter,
+		newTaskReader,
+		newRunner,
 	)
 }
 
@@ -248,8 +260,12 @@ func AddInternals(graph adder) {
 		newResolveFilter,
 		newResolver,
 		newAutoResolver,
+		newLeadership,
+		newAutoscaler,
 		newInserter,
 		newStatusPoller,
+		newAuthenticator,
+		newServerTLSConfig,
 	)
 }
 
@@ -257,12 +273,44 @@ func newResolveFilter(sf *config.DeployFilterFlags, shc sous.SourceHostChooser)
 	return sf.BuildFilter(shc.ParseSourceLocation)
 }
 
-func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry) *sous.Resolver {
-	return sous.NewResolver(d, r, filter)
+func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry, as *sous.Autoscaler) *sous.Resolver {
+	rez := sous.NewResolver(d, r, filter)
+	rez.Autoscaler = as
+	return rez
 }
 
-func newAutoResolver(rez *sous.Resolver, sr StateReader, ls *sous.LogSet) *sous.AutoResolver {
-	return sous.NewAutoResolver(rez, sr, ls)
+func newAutoResolver(rez *sous.Resolver, sr StateReader, ls *sous.LogSet, l *sous.Leadership, as *sous.Autoscaler) *sous.AutoResolver {
+	ar := sous.NewAutoResolver(rez, sr, ls)
+	ar.Leadership = l
+	ar.Autoscaler = as
+	return ar
+}
+
+// newAutoscaler returns the *sous.Autoscaler applying autoscaling policies,
+// or nil if no metrics are configured or the deployer cannot scale.
+func newAutoscaler(cfg LocalSousConfig, d sous.Deployer) *sous.Autoscaler {
+	if cfg.Autoscale.MetricsFile == "" {
+		return nil
+	}
+	scaler, ok := d.(sous.Scaler)
+	if !ok {
+		return nil
+	}
+	return sous.NewAutoscaler(storage.NewFileMetricsSource(cfg.Autoscale.MetricsFile), scaler)
+}
+
+// newLeadership returns the *sous.Leadership this server campaigns with, or
+// nil if leader election is not configured.
+func newLeadership(cfg LocalSousConfig) *sous.Leadership {
+	lc := cfg.Leader
+	if lc.LeaseFile == "" {
+		return nil
+	}
+	ttl := 180 * time.Second
+	if lc.LeaseSeconds > 0 {
+		ttl = time.Duration(lc.LeaseSeconds) * time.Second
+	}
+	return sous.NewLeadership(storage.NewFileLeaseBackend(lc.LeaseFile), lc.AdvertiseURL, ttl)
 }
 
 func newSourceHostChooser() sous.SourceHostChooser {
@@ -273,8 +321,13 @@ func newSourceHostChooser() sous.SourceHostChooser {
 	}
 }
 
-func newRegistryDumper(r sous.Registry) *sous.RegistryDumper {
-	return sous.NewRegistryDumper(r)
+// newRegistryDumper returns a RegistryDumper which asks the server for
+// artifacts if one is configured, and reads r otherwise.
+func newRegistryDumper(r sous.Registry, cl HTTPClient, user sous.User) *sous.RegistryDumper {
+	if cl.HTTPClient == nil {
+		return sous.NewRegistryDumper(r)
+	}
+	return sous.NewHTTPRegistryDumper(cl.HTTPClient, user)
 }
 
 func newLogSet(v *config.Verbosity, err ErrWriter) *sous.LogSet { // XXX temporary until we settle on logging
@@ -334,10 +387,17 @@ func (scd *SourceContextDiscovery) GetContext() *sous.SourceContext {
 	return scd.SourceContext
 }
 
-func newBuildContext(wd LocalWorkDirShell, c *sous.SourceContext) *sous.BuildContext {
+func newBuildContext(wd LocalWorkDirShell, c *sous.SourceContext, u config.LocalUser) *sous.BuildContext {
 	sh := wd.Sh.Clone()
 	sh.LongRunning(true)
-	return &sous.BuildContext{Sh: sh, Source: *c}
+	bc := &sous.BuildContext{Sh: sh, Source: *c}
+	if u.User != nil {
+		bc.User = *u.User
+	}
+	if host, err := os.Hostname(); err == nil {
+		bc.Machine = sous.Machine{Host: strings.SplitN(host, ".", 2)[0], FullHost: host}
+	}
+	return bc
 }
 
 func newBuildConfig(f *config.DeployFilterFlags, p *config.PolicyFlags, bc *sous.BuildContext) *sous.BuildConfig {
@@ -413,9 +473,7 @@ func newLocalGitRepo(c LocalGitClient) (v LocalGitRepo, err error) {
 
 func newSelector() sous.Selector {
 	return &sous.EchoSelector{
-		Factory: func(*sous.BuildContext) (sous.Buildpack, error) {
-			return docker.NewDockerfileBuildpack(), nil
-		},
+		Factory: docker.SelectBuildpack,
 	}
 }
 
@@ -423,7 +481,12 @@ func newDockerBuilder(cfg LocalSousConfig, nc *docker.NameCache, ctx *sous.Sourc
 	drh := cfg.Docker.RegistryHost
 	source.Sh = source.Sh.Clone().(*shell.Sh)
 	source.Sh.LongRunning(true)
-	return docker.NewBuilder(nc, drh, source.Sh, scratch.Sh)
+	b, err := docker.NewBuilder(nc, drh, source.Sh, scratch.Sh)
+	if err != nil {
+		return nil, err
+	}
+	b.RegistryClient = nc.RegistryClient
+	return b, nil
 }
 
 func newLabeller(db *docker.Builder) sous.Labeller {
@@ -451,6 +514,18 @@ func newDeployer(dryrun DryrunOption, nc *docker.NameCache) sous.Deployer {
 	return singularity.NewDeployer(singularity.NewRectiAgent(nc))
 }
 
+func newAdopter(nc *docker.NameCache) *singularity.Adopter {
+	return singularity.NewAdopter(nc, singularity.NewRectiAgent(nc))
+}
+
+func newTaskReader() *singularity.TaskReader {
+	return singularity.NewTaskReader()
+}
+
+func newRunner(tr *singularity.TaskReader) *singularity.Runner {
+	return singularity.NewRunner(tr)
+}
+
 func newDockerClient() LocalDockerClient {
 	return LocalDockerClient{docker_registry.NewClient()}
 }
@@ -465,7 +540,18 @@ func newHTTPClient(c LocalSousConfig, user sous.User) (HTTPClient, error) {
 	}
 	sous.Log.Debug.Printf("Using server at %s", c.Server)
 	cl, err := sous.NewClient(c.Server)
-	return HTTPClient{HTTPClient: cl}, err
+	if err != nil {
+		return HTTPClient{}, err
+	}
+	cl.AuthToken = c.AuthToken
+	tc, err := c.TLS.ClientConfig()
+	if err != nil {
+		return HTTPClient{}, initErr(err, "configuring TLS")
+	}
+	if tc != nil {
+		cl.SetTLSConfig(tc)
+	}
+	return HTTPClient{HTTPClient: cl}, nil
 }
 
 // newStateManager returns a wrapped sous.HTTPStateManager if cl is not nil.
@@ -540,11 +626,62 @@ func newDockerRegistry(cfg LocalSousConfig, cl LocalDockerClient) (*docker.NameC
 	return docker.NewNameCache(drh, cl.Client, db), nil
 }
 
-func newInserter(cfg LocalSousConfig, cl LocalDockerClient) (sous.Inserter, error) {
+// newHarvester creates the *docker.Harvester the server uses to harvest the
+// whole registry catalog.
+func newHarvester(cfg LocalSousConfig, nc *docker.NameCache) *docker.Harvester {
+	interval := time.Duration(cfg.Docker.HarvestIntervalSeconds) * time.Second
+	return docker.NewHarvester(nc, interval, cfg.Docker.HarvestConcurrency)
+}
+
+// newGarbageCollector creates the *docker.GarbageCollector used by `sous gc`,
+// and by the server to collect garbage every Docker.GCIntervalSeconds, when
+// it is the leader.
+func newGarbageCollector(cfg LocalSousConfig, nc *docker.NameCache, l *sous.Leadership) *docker.GarbageCollector {
+	interval := time.Duration(cfg.Docker.GCIntervalSeconds) * time.Second
+	gc := docker.NewGarbageCollector(nc, cfg.Docker.GCRetainVersions, interval)
+	gc.Leadership = l
+	return gc
+}
+
+// newInserter returns the local name cache if no server is configured.
+// Otherwise it returns an HTTPNameInserter which sends its inserts with hc,
+// so that they carry the same credentials and TLS configuration as every
+// other request to the server.
+func newInserter(cfg LocalSousConfig, cl LocalDockerClient, hc HTTPClient, user sous.User) (sous.Inserter, error) {
 	if cfg.Server == "" {
 		return newDockerRegistry(cfg, cl)
 	}
-	return sous.NewHTTPNameInserter(cfg.Server)
+	live, ok := hc.HTTPClient.(*sous.LiveHTTPClient)
+	if !ok {
+		return nil, errors.Errorf("no HTTP client for server %q to record artifacts with", cfg.Server)
+	}
+	return sous.NewHTTPNameInserter(live, user), nil
+}
+
+// newAuthenticator returns the restful.Authenticator used by the Sous server
+// to verify its clients. If no users file is configured, it returns nil and
+// the server accepts unauthenticated requests.
+func newAuthenticator(cfg LocalSousConfig) (restful.Authenticator, error) {
+	if cfg.Auth.UsersFile == "" {
+		return nil, nil
+	}
+	users, err := restful.LoadUserFile(cfg.Auth.UsersFile)
+	if err != nil {
+		return nil, initErr(err, "loading server users")
+	}
+	auth := restful.MultiAuthenticator{&restful.StaticTokenAuthenticator{Users: users}}
+	if cfg.Auth.SigningKey != "" {
+		auth = append(auth, &restful.SignedTokenAuthenticator{
+			Key:   []byte(cfg.Auth.SigningKey),
+			Users: users,
+		})
+	}
+	return auth, nil
+}
+
+func newServerTLSConfig(cfg LocalSousConfig) (ServerTLSConfig, error) {
+	tc, err := cfg.TLS.ServerConfig()
+	return ServerTLSConfig{Config: tc}, initErr(err, "configuring server TLS")
 }
 
 // initErr returns nil if error is nil, otherwise an initialisation error.
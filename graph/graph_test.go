@@ -66,13 +66,21 @@ func TestStateManagerSelectsGit(t *testing.T) {
 }
 
 func testBuildInserter(t *testing.T, serverStr string) sous.Inserter {
+	var hc HTTPClient
+	if serverStr != "" {
+		cl, err := sous.NewClient(serverStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hc.HTTPClient = cl
+	}
 	ins, err := newInserter(LocalSousConfig{Config: &config.Config{
 		Server: serverStr,
 		Docker: docker.Config{
 			DatabaseDriver:     "sqlite3_sous",
 			DatabaseConnection: docker.InMemory,
 		},
-	}}, LocalDockerClient{})
+	}}, LocalDockerClient{}, hc, sous.User{})
 	if err != nil {
 		t.Fatal(err)
 	}
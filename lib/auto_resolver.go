@@ -23,6 +23,13 @@ type (
 		GDM Deployments
 		*Resolver
 		*LogSet
+		// Leadership, if not nil, decides whether this server is the leader,
+		// and so whether it should resolve at all.
+		*Leadership
+		// Autoscaler, if not nil, scales autoscaled deployments after each
+		// resolution. The Resolver's Autoscaler supplies their effective
+		// instance counts to the next.
+		*Autoscaler
 		listeners []autoResolveListener
 		sync.RWMutex
 		stableStatus, liveStatus *ResolveStatus
@@ -55,6 +62,9 @@ func (ar *AutoResolver) StandardListeners() {
 	ar.addListener(func(trigger, done TriggerChannel, ch announceChannel) {
 		ar.errorLogging(trigger, done, ch)
 	})
+	ar.addListener(func(trigger, done TriggerChannel, ch announceChannel) {
+		ar.autoscaling(trigger, done, ch)
+	})
 }
 
 func (ar *AutoResolver) addListener(f autoResolveListener) {
@@ -69,6 +79,13 @@ func (ar *AutoResolver) Kickoff() TriggerChannel {
 
 	var fanout []announceChannel
 
+	if ar.Leadership != nil {
+		if _, err := ar.Leadership.Campaign(); err != nil {
+			ar.LogSet.Warn.Print(err)
+		}
+		go ar.Leadership.maintain(done, ar.LogSet)
+	}
+
 	go loopTilDone(func() {
 		ar.resolveLoop(trigger, done, announce)
 	}, done)
@@ -163,16 +180,25 @@ func (ar *AutoResolver) resolveOnce(ac announceChannel) {
 		ac <- err
 		return
 	}
-	ar.GDM, err = state.Deployments()
+	gdm, err := state.Deployments()
 	ar.LogSet.Debug.Printf("Reading GDM from state: err: %v", err)
 
 	if err != nil {
 		ac <- err
 		return
 	}
+	ar.write(func() {
+		ar.GDM = gdm
+	})
+
+	if !ar.Leadership.IsLeader() {
+		ar.LogSet.Debug.Printf("Not resolving: %q is the leader", ar.Leadership.Leader().Holder)
+		ac <- nil
+		return
+	}
 
 	ar.write(func() {
-		ar.currentRecorder = ar.Resolver.Begin(ar.GDM, state.Defs.Clusters)
+		ar.currentRecorder = ar.Resolver.Begin(gdm, state.Defs.Clusters)
 	})
 	defer ar.write(func() {
 		ar.currentRecorder = nil
@@ -212,6 +238,23 @@ func (ar *AutoResolver) errorLogging(tc, done TriggerChannel, errs announceChann
 	}
 }
 
+// autoscaling evaluates the autoscaling policies of the GDM once each
+// resolution is complete.
+func (ar *AutoResolver) autoscaling(tc, done TriggerChannel, ac announceChannel) {
+	select {
+	case <-done:
+		return
+	case <-ac:
+	}
+	if ar.Autoscaler == nil || !ar.Leadership.IsLeader() {
+		return
+	}
+	ar.RLock()
+	gdm := ar.GDM
+	ar.RUnlock()
+	ar.Autoscaler.evaluate(gdm, ar.LogSet)
+}
+
 func (ar *AutoResolver) multicast(done TriggerChannel, ac announceChannel, fo []announceChannel) {
 	select {
 	case <-done:
@@ -98,3 +98,26 @@ func TestResolveLoop(t *testing.T) {
 		t.Error("Should have announced a result")
 	}
 }
+
+func TestAutoResolver_FollowerDoesNotResolve(t *testing.T) {
+	assert := assert.New(t)
+	ar := setupAR()
+
+	backend := &MemoryLeaseBackend{}
+	_, err := NewLeadership(backend, "http://leader", time.Minute).Campaign()
+	assert.NoError(err)
+	ar.Leadership = NewLeadership(backend, "http://follower", time.Minute)
+	_, err = ar.Leadership.Campaign()
+	assert.NoError(err)
+
+	tc := make(TriggerChannel, 10)
+	ac := make(announceChannel, 1)
+	done := make(TriggerChannel)
+	tc.trigger()
+
+	ar.resolveLoop(tc, done, ac)
+	assert.NoError(<-ac)
+	stable, live := ar.Statuses()
+	assert.Nil(stable)
+	assert.Nil(live)
+}
@@ -0,0 +1,278 @@
+package sous
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// An AutoscalePolicy tells Sous to vary the number of instances of a
+	// deployment between MinInstances and MaxInstances, aiming to keep Metric
+	// at Target per instance.
+	AutoscalePolicy struct {
+		// MinInstances and MaxInstances bound the number of instances.
+		MinInstances, MaxInstances int
+		// Metric names the metric to track, as understood by the
+		// MetricsSource, e.g. "cpu".
+		Metric string
+		// Target is the value of Metric, averaged over instances, that Sous
+		// scales the deployment to maintain.
+		Target float64
+		// CooldownSeconds is the least time Sous waits after scaling a
+		// deployment before scaling it again.
+		CooldownSeconds int `yaml:",omitempty"`
+	}
+
+	// A MetricsSource reports the current load on deployments.
+	MetricsSource interface {
+		// Metric returns the current value of the named metric for the
+		// deployment, averaged over its instances.
+		Metric(id DeployID, name string) (float64, error)
+	}
+
+	// A Scaler changes the number of instances of a running deployment,
+	// without redeploying it.
+	Scaler interface {
+		Scale(d *Deployment, instances int, message string) error
+	}
+
+	// An Autoscaler applies the AutoscalePolicies of deployments. It records
+	// the effective number of instances of each deployment it scales, which
+	// takes precedence over NumInstances in the GDM, so that the GDM need not
+	// be rewritten every time a deployment is scaled. The effective counts
+	// start from the instances found running, so that they survive restarts
+	// and changes of leader.
+	Autoscaler struct {
+		Metrics MetricsSource
+		Scaler
+		effective map[DeployID]int
+		scaledAt  map[DeployID]time.Time
+		now       func() time.Time
+		sync.RWMutex
+	}
+
+	// StaticMetricsSource is a MetricsSource reporting fixed values, useful
+	// for testing.
+	StaticMetricsSource struct {
+		values map[DeployID]map[string]float64
+		sync.RWMutex
+	}
+)
+
+// Validate returns a slice of Flaws.
+func (p *AutoscalePolicy) Validate() []Flaw {
+	var flaws []Flaw
+	flaw := func(format string, a ...interface{}) {
+		desc := fmt.Sprintf(format, a...)
+		flaws = append(flaws, NewFlaw(desc, func() error {
+			return errors.Errorf("%s: cannot be repaired", desc)
+		}))
+	}
+	if p.MinInstances < 1 {
+		flaw("autoscale MinInstances must be at least 1, not %d", p.MinInstances)
+	}
+	if p.MaxInstances < p.MinInstances {
+		flaw("autoscale MaxInstances (%d) is less than MinInstances (%d)", p.MaxInstances, p.MinInstances)
+	}
+	if p.Metric == "" {
+		flaw("autoscale Metric is empty")
+	}
+	if p.Target <= 0 {
+		flaw("autoscale Target must be positive, not %v", p.Target)
+	}
+	if p.CooldownSeconds < 0 {
+		flaw("autoscale CooldownSeconds must not be negative, not %d", p.CooldownSeconds)
+	}
+	return flaws
+}
+
+// Equal returns true if p and o are the same policy. Two nil policies are
+// equal.
+func (p *AutoscalePolicy) Equal(o *AutoscalePolicy) bool {
+	if p == nil || o == nil {
+		return p == o
+	}
+	return *p == *o
+}
+
+// Clone returns a copy of p.
+func (p *AutoscalePolicy) Clone() *AutoscalePolicy {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// clamp returns n limited to the bounds of the policy.
+func (p *AutoscalePolicy) clamp(n int) int {
+	if n < p.MinInstances {
+		return p.MinInstances
+	}
+	if n > p.MaxInstances {
+		return p.MaxInstances
+	}
+	return n
+}
+
+// desired returns the number of instances needed to bring value, currently
+// averaged over current instances, to the target of the policy.
+func (p *AutoscalePolicy) desired(current int, value float64) int {
+	if current < 1 {
+		return p.MinInstances
+	}
+	return p.clamp(int(math.Ceil(float64(current) * value / p.Target)))
+}
+
+// NewAutoscaler creates an Autoscaler which reads load from ms and scales
+// deployments using s.
+func NewAutoscaler(ms MetricsSource, s Scaler) *Autoscaler {
+	return &Autoscaler{
+		Metrics:   ms,
+		Scaler:    s,
+		effective: map[DeployID]int{},
+		scaledAt:  map[DeployID]time.Time{},
+		now:       time.Now,
+	}
+}
+
+// Effective returns the number of instances the Autoscaler has settled on for
+// the deployment, and true, if the deployment is autoscaled.
+func (as *Autoscaler) Effective(id DeployID) (int, bool) {
+	if as == nil {
+		return 0, false
+	}
+	as.RLock()
+	defer as.RUnlock()
+	n, ok := as.effective[id]
+	return n, ok
+}
+
+// Apply returns a copy of gdm with the NumInstances of autoscaled deployments
+// replaced by their effective instance counts. A deployment without one yet
+// takes the number of instances running, as found in running, or if it isn't
+// running its NumInstances, limited to the bounds of its policy. A nil
+// *Autoscaler returns gdm unchanged.
+func (as *Autoscaler) Apply(gdm Deployments, running DeployStates) Deployments {
+	if as == nil {
+		return gdm
+	}
+	applied := NewDeployments()
+	for id, d := range gdm.Snapshot() {
+		if policy := d.DeployConfig.Autoscale; policy != nil {
+			d = d.Clone()
+			d.NumInstances = as.seed(id, d, policy, running)
+		}
+		applied.Add(d)
+	}
+	return applied
+}
+
+// seed returns the effective number of instances of d, first recording it
+// from running if there isn't one yet.
+func (as *Autoscaler) seed(id DeployID, d *Deployment, policy *AutoscalePolicy, running DeployStates) int {
+	as.Lock()
+	defer as.Unlock()
+	if n, ok := as.effective[id]; ok {
+		return n
+	}
+	n := d.NumInstances
+	if ds, ok := running.Get(id); ok {
+		n = ds.NumInstances
+	}
+	n = policy.clamp(n)
+	as.effective[id] = n
+	return n
+}
+
+// evaluate checks the load on each autoscaled deployment in gdm, and scales
+// those which are outside their targets and not cooling down from a previous
+// scale.
+func (as *Autoscaler) evaluate(gdm Deployments, ls *LogSet) {
+	if as == nil {
+		return
+	}
+	seen := map[DeployID]struct{}{}
+	for id, d := range gdm.Snapshot() {
+		policy := d.DeployConfig.Autoscale
+		if policy == nil {
+			continue
+		}
+		seen[id] = struct{}{}
+		if err := as.scale(id, d, policy); err != nil {
+			ls.Warn.Printf("autoscaling %v: %v", id, err)
+		}
+	}
+	as.Lock()
+	defer as.Unlock()
+	for id := range as.effective {
+		if _, ok := seen[id]; !ok {
+			delete(as.effective, id)
+			delete(as.scaledAt, id)
+		}
+	}
+}
+
+func (as *Autoscaler) scale(id DeployID, d *Deployment, policy *AutoscalePolicy) error {
+	as.Lock()
+	current, ok := as.effective[id]
+	if !ok {
+		current = policy.clamp(d.NumInstances)
+		as.effective[id] = current
+	}
+	cooldown := time.Duration(policy.CooldownSeconds) * time.Second
+	cooling := as.now().Sub(as.scaledAt[id]) < cooldown
+	as.Unlock()
+	if cooling {
+		return nil
+	}
+
+	value, err := as.Metrics.Metric(id, policy.Metric)
+	if err != nil {
+		return err
+	}
+	want := policy.desired(current, value)
+	if want == current {
+		return nil
+	}
+	msg := fmt.Sprintf("autoscaling from %d to %d instances (%s: %v, target %v)",
+		current, want, policy.Metric, value, policy.Target)
+	if err := as.Scaler.Scale(d, want, msg); err != nil {
+		return err
+	}
+	as.Lock()
+	as.effective[id] = want
+	as.scaledAt[id] = as.now()
+	as.Unlock()
+	return nil
+}
+
+// NewStaticMetricsSource creates an empty StaticMetricsSource.
+func NewStaticMetricsSource() *StaticMetricsSource {
+	return &StaticMetricsSource{values: map[DeployID]map[string]float64{}}
+}
+
+// Set records value as the current value of the named metric for id.
+func (sm *StaticMetricsSource) Set(id DeployID, name string, value float64) {
+	sm.Lock()
+	defer sm.Unlock()
+	if sm.values[id] == nil {
+		sm.values[id] = map[string]float64{}
+	}
+	sm.values[id][name] = value
+}
+
+// Metric implements MetricsSource on StaticMetricsSource.
+func (sm *StaticMetricsSource) Metric(id DeployID, name string) (float64, error) {
+	sm.RLock()
+	defer sm.RUnlock()
+	v, ok := sm.values[id][name]
+	if !ok {
+		return 0, errors.Errorf("no value for metric %q of %v", name, id)
+	}
+	return v, nil
+}
@@ -0,0 +1,167 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/pkg/errors"
+)
+
+type recordingScaler struct {
+	scaled []int
+	err    error
+}
+
+func (rs *recordingScaler) Scale(d *Deployment, instances int, message string) error {
+	if rs.err != nil {
+		return rs.err
+	}
+	rs.scaled = append(rs.scaled, instances)
+	return nil
+}
+
+func autoscaledDeployment(n int, policy *AutoscalePolicy) *Deployment {
+	return &Deployment{
+		SourceID:     MustParseSourceID("github.com/example/app,1.0.0"),
+		ClusterName:  "cluster-1",
+		Cluster:      &Cluster{Name: "cluster-1", BaseURL: "http://cluster-1.example.com"},
+		DeployConfig: DeployConfig{NumInstances: n, Autoscale: policy},
+	}
+}
+
+func TestAutoscaler_Evaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	d := autoscaledDeployment(2, &AutoscalePolicy{
+		MinInstances:    1,
+		MaxInstances:    5,
+		Metric:          "cpu",
+		Target:          0.5,
+		CooldownSeconds: 60,
+	})
+	gdm := NewDeployments(d)
+	metrics := NewStaticMetricsSource()
+	scaler := &recordingScaler{}
+	as := NewAutoscaler(metrics, scaler)
+	now := time.Now()
+	as.now = func() time.Time { return now }
+
+	metrics.Set(d.ID(), "cpu", 0.75)
+	as.evaluate(gdm, SilentLogSet())
+	assert.Equal([]int{3}, scaler.scaled)
+	n, ok := as.Effective(d.ID())
+	assert.True(ok)
+	assert.Equal(3, n)
+
+	applied, _ := as.Apply(gdm, NewDeployStates()).Get(d.ID())
+	assert.Equal(3, applied.NumInstances)
+	assert.Equal(2, d.NumInstances, "Apply should not modify the GDM")
+
+	metrics.Set(d.ID(), "cpu", 0.1)
+	as.evaluate(gdm, SilentLogSet())
+	assert.Equal([]int{3}, scaler.scaled, "should not scale during cooldown")
+
+	now = now.Add(time.Minute)
+	as.evaluate(gdm, SilentLogSet())
+	assert.Equal([]int{3, 1}, scaler.scaled)
+
+	metrics.Set(d.ID(), "cpu", 10)
+	now = now.Add(time.Minute)
+	as.evaluate(gdm, SilentLogSet())
+	assert.Equal([]int{3, 1, 5}, scaler.scaled, "should not scale beyond MaxInstances")
+}
+
+func TestAutoscaler_Evaluate_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	d := autoscaledDeployment(2, &AutoscalePolicy{MinInstances: 1, MaxInstances: 5, Metric: "cpu", Target: 0.5})
+	gdm := NewDeployments(d)
+	metrics := NewStaticMetricsSource()
+	scaler := &recordingScaler{err: errors.New("scheduler unavailable")}
+	as := NewAutoscaler(metrics, scaler)
+
+	as.evaluate(gdm, SilentLogSet())
+	n, _ := as.Effective(d.ID())
+	assert.Equal(2, n, "missing metrics should leave the instance count alone")
+
+	metrics.Set(d.ID(), "cpu", 1)
+	as.evaluate(gdm, SilentLogSet())
+	n, _ = as.Effective(d.ID())
+	assert.Equal(2, n, "failed scales should not be recorded")
+
+	d.Autoscale = nil
+	as.evaluate(gdm, SilentLogSet())
+	_, ok := as.Effective(d.ID())
+	assert.False(ok, "deployments without a policy should not be autoscaled")
+	applied, _ := as.Apply(gdm, NewDeployStates()).Get(d.ID())
+	assert.Equal(2, applied.NumInstances)
+}
+
+func TestAutoscaler_Apply_Seeds(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := &AutoscalePolicy{MinInstances: 2, MaxInstances: 6, Metric: "cpu", Target: 0.5}
+	d := autoscaledDeployment(1, policy)
+	gdm := NewDeployments(d)
+	metrics := NewStaticMetricsSource()
+	scaler := &recordingScaler{}
+
+	as := NewAutoscaler(metrics, scaler)
+	applied, _ := as.Apply(gdm, NewDeployStates()).Get(d.ID())
+	assert.Equal(2, applied.NumInstances, "a GDM count outside the policy should be clamped")
+
+	// A new Autoscaler, as after a restart, starts from what's running.
+	as = NewAutoscaler(metrics, scaler)
+	running := NewDeployStates(&DeployState{Deployment: *autoscaledDeployment(5, policy), Status: DeployStatusActive})
+	applied, _ = as.Apply(gdm, running).Get(d.ID())
+	assert.Equal(5, applied.NumInstances)
+	n, ok := as.Effective(d.ID())
+	assert.True(ok)
+	assert.Equal(5, n)
+
+	metrics.Set(d.ID(), "cpu", 0.6)
+	as.evaluate(gdm, SilentLogSet())
+	assert.Equal([]int{6}, scaler.scaled, "scaling should start from the running count")
+}
+
+func TestAutoscaler_Nil(t *testing.T) {
+	var as *Autoscaler
+	gdm := NewDeployments()
+	assert.Equal(t, gdm, as.Apply(gdm, NewDeployStates()))
+	as.evaluate(gdm, SilentLogSet())
+}
+
+func TestAutoscalePolicy_Validate(t *testing.T) {
+	bad := []AutoscalePolicy{
+		{MinInstances: 0, MaxInstances: 2, Metric: "cpu", Target: 1},
+		{MinInstances: 3, MaxInstances: 2, Metric: "cpu", Target: 1},
+		{MinInstances: 1, MaxInstances: 2, Target: 1},
+		{MinInstances: 1, MaxInstances: 2, Metric: "cpu"},
+		{MinInstances: 1, MaxInstances: 2, Metric: "cpu", Target: 1, CooldownSeconds: -1},
+	}
+	for _, p := range bad {
+		if len(p.Validate()) != 1 {
+			t.Errorf("%+v should have exactly one flaw, got %v", p, p.Validate())
+		}
+	}
+	good := AutoscalePolicy{MinInstances: 1, MaxInstances: 2, Metric: "cpu", Target: 1}
+	if fs := good.Validate(); len(fs) != 0 {
+		t.Errorf("%+v should have no flaws, got %v", good, fs)
+	}
+}
+
+func TestDeploySpec_Diff_Autoscale(t *testing.T) {
+	policy := &AutoscalePolicy{MinInstances: 1, MaxInstances: 2, Metric: "cpu", Target: 1}
+	a := DeploySpec{DeployConfig: DeployConfig{Autoscale: policy}}
+	b := DeploySpec{}
+	if !a.Clone().Equal(a) {
+		t.Errorf("cloned spec should equal the original")
+	}
+	if different, _ := a.Diff(b); !different {
+		t.Errorf("specs with different autoscale policies should differ")
+	}
+	if _, diffs := a.DeployConfig.Diff(b.DeployConfig); len(diffs) != 0 {
+		t.Errorf("DeployConfig.Diff should ignore autoscale policies")
+	}
+}
@@ -38,6 +38,7 @@ func (m *BuildManager) Build() (*BuildResult, error) {
 		func(e *error) { dr, *e = bp.Detect(bc) },
 		func(e *error) { br, *e = bp.Build(bc, dr) },
 		func(e *error) { br.Advisories = bc.Advisories },
+		func(e *error) { completeProvenance(br, bc, bp, dr) },
 		func(e *error) { *e = m.ApplyMetadata(br, bc) },
 		func(e *error) { *e = m.RegisterAndWarnAdvisories(br, bc) },
 	)
@@ -33,15 +33,19 @@ type (
 
 	// BuildArtifact describes the actual built binary Sous will deploy
 	BuildArtifact struct {
-		Name, Type string
-		Qualities  []Quality
+		// Name identifies the artifact: an image name for Docker images, or a
+		// path for artifacts which are files.
+		Name string
+		// Type is the type of the artifact, one of the ArtifactType constants.
+		Type      string
+		Qualities []Quality
 	}
 
 	// A Quality represents a characteristic of a BuildArtifact that needs to be recorded.
 	Quality struct {
 		Name string
 		// Kind is the the kind of this quality
-		// Known kinds include: advisory
+		// Known kinds include: advisory, assertion
 		Kind string
 	}
 
@@ -71,6 +75,14 @@ type (
 		VersionName, RevisionName string
 		Advisories                []string
 		Elapsed                   time.Duration
+		// Provenance records how the artifact was built. Buildpacks may fill
+		// in the parts only they know; the BuildManager completes it.
+		Provenance *Provenance
+		// ArtifactType is the type of artifact built. If it's empty or
+		// ArtifactTypeDocker, the image is in the local Docker daemon, with ID
+		// ImageID; otherwise the artifact is the file or directory at
+		// ArtifactPath.
+		ArtifactType, ArtifactPath string
 	}
 
 	// EchoSelector wraps a buildpack Factory. But why?
@@ -92,6 +104,26 @@ func (br *BuildResult) String() string {
 	return fmt.Sprintf("%s\nElapsed: %s", str, br.Elapsed)
 }
 
+const (
+	// ArtifactTypeDocker is an image in a Docker registry. It's the only type
+	// of artifact Singularity clusters can deploy.
+	ArtifactTypeDocker = "docker"
+	// ArtifactTypeOCILayout is a directory in the OCI image layout.
+	ArtifactTypeOCILayout = "oci-layout"
+	// ArtifactTypeDockerArchive is a tarball of an image, as written by
+	// `docker save`.
+	ArtifactTypeDockerArchive = "docker-archive"
+)
+
+// ArtifactTypes lists the known types of artifact.
+var ArtifactTypes = []string{ArtifactTypeDocker, ArtifactTypeOCILayout, ArtifactTypeDockerArchive}
+
+// IsFileArtifactType returns true if artifacts of type typ are files, rather
+// than images in a registry.
+func IsFileArtifactType(typ string) bool {
+	return typ == ArtifactTypeOCILayout || typ == ArtifactTypeDockerArchive
+}
+
 // NewBuildArtifact creates a new BuildArtifact representing a Docker
 // image.
 func NewBuildArtifact(imageName string, qstrs Strpairs) *BuildArtifact {
@@ -100,5 +132,5 @@ func NewBuildArtifact(imageName string, qstrs Strpairs) *BuildArtifact {
 		qs = append(qs, Quality{Name: qstr[0], Kind: qstr[1]})
 	}
 
-	return &BuildArtifact{Name: imageName, Type: "docker", Qualities: qs}
+	return &BuildArtifact{Name: imageName, Type: ArtifactTypeDocker, Qualities: qs}
 }
@@ -30,6 +30,11 @@ type (
 
 		// Volumes lists the volume mappings for this deploy
 		Volumes Volumes
+
+		// Autoscale, if set, lets Sous vary the number of instances in
+		// response to load, within the bounds of the policy. NumInstances is
+		// then only the starting point.
+		Autoscale *AutoscalePolicy `yaml:",omitempty"`
 	}
 
 	// A DeployConfigs is a map from cluster name to DeployConfig
@@ -69,6 +74,10 @@ func (dc *DeployConfig) Validate() []Flaw {
 
 	flaws = append(flaws, rezs.Validate()...)
 
+	if dc.Autoscale != nil {
+		flaws = append(flaws, dc.Autoscale.Validate()...)
+	}
+
 	for _, f := range flaws {
 		f.AddContext("deploy config", dc)
 	}
@@ -80,6 +89,10 @@ func (dc *DeployConfig) Validate() []Flaw {
 func (nvf *NilVolumeFlaw) AddContext(string, interface{}) {
 }
 
+func (nvf *NilVolumeFlaw) String() string {
+	return "nil volume in DeployConfig.Volumes"
+}
+
 // Repair removes any nil entries in DeployConfig.Volumes.
 func (nvf *NilVolumeFlaw) Repair() error {
 	newVs := nvf.DeployConfig.Volumes[:0]
@@ -141,6 +154,8 @@ func (dc *DeployConfig) Diff(o DeployConfig) (bool, []string) {
 		}
 	}
 	// TODO: Compare Args
+	// Autoscale is not compared here, since schedulers do not report it;
+	// DeploySpec.Diff compares it.
 	return len(diffs) == 0, diffs
 }
 
@@ -163,6 +178,7 @@ func (dc DeployConfig) Clone() (c DeployConfig) {
 	}
 	c.Volumes = make(Volumes, len(dc.Volumes))
 	copy(dc.Volumes, c.Volumes)
+	c.Autoscale = dc.Autoscale.Clone()
 	return
 }
 
@@ -214,6 +230,106 @@ func stringSlicesEqual(left, right []string) bool {
 	return true
 }
 
+func (dc DeployConfig) isZero() bool {
+	return len(dc.Resources) == 0 && len(dc.Metadata) == 0 && len(dc.Env) == 0 &&
+		len(dc.Args) == 0 && dc.NumInstances == 0 && len(dc.Volumes) == 0 &&
+		dc.Autoscale == nil
+}
+
+// sharedBy returns the part of defaults that all of dcs can inherit: the
+// resources, env and metadata that every one of them sets, whatever its value,
+// and the number of instances, args, volumes and autoscale policy if every one
+// of them has some. Whatever defaults sets that some of dcs lack is dropped,
+// since those deployments would otherwise inherit it.
+func (defaults DeployConfig) sharedBy(dcs []DeployConfig) DeployConfig {
+	c := DeployConfig{
+		Resources:    make(Resources),
+		Env:          make(Env),
+		Metadata:     make(Metadata),
+		NumInstances: defaults.NumInstances,
+		Args:         defaults.Args,
+		Volumes:      defaults.Volumes,
+		Autoscale:    defaults.Autoscale.Clone(),
+	}
+	for k, v := range defaults.Resources {
+		c.Resources[k] = v
+	}
+	for k, v := range defaults.Env {
+		c.Env[k] = v
+	}
+	for k, v := range defaults.Metadata {
+		c.Metadata[k] = v
+	}
+	for _, dc := range dcs {
+		if dc.NumInstances == 0 {
+			c.NumInstances = 0
+		}
+		if len(dc.Args) == 0 {
+			c.Args = nil
+		}
+		if len(dc.Volumes) == 0 {
+			c.Volumes = nil
+		}
+		if dc.Autoscale == nil {
+			c.Autoscale = nil
+		}
+		for k := range c.Resources {
+			if _, ok := dc.Resources[k]; !ok {
+				delete(c.Resources, k)
+			}
+		}
+		for k := range c.Env {
+			if _, ok := dc.Env[k]; !ok {
+				delete(c.Env, k)
+			}
+		}
+		for k := range c.Metadata {
+			if _, ok := dc.Metadata[k]; !ok {
+				delete(c.Metadata, k)
+			}
+		}
+	}
+	return c
+}
+
+// without returns dc without the config it would inherit from defaults
+// anyway: whatever it sets to the same value. What it sets differently stays,
+// to override defaults.
+func (dc DeployConfig) without(defaults DeployConfig) DeployConfig {
+	if defaults.NumInstances != 0 && dc.NumInstances == defaults.NumInstances {
+		dc.NumInstances = 0
+	}
+	if len(defaults.Args) != 0 && stringSlicesEqual(dc.Args, defaults.Args) {
+		dc.Args = nil
+	}
+	if len(defaults.Volumes) != 0 && dc.Volumes.Equal(defaults.Volumes) {
+		dc.Volumes = nil
+	}
+	if defaults.Autoscale != nil && dc.Autoscale.Equal(defaults.Autoscale) {
+		dc.Autoscale = nil
+	}
+	rs := make(Resources)
+	for k, v := range dc.Resources {
+		if dv, ok := defaults.Resources[k]; !ok || dv != v {
+			rs[k] = v
+		}
+	}
+	env := make(Env)
+	for k, v := range dc.Env {
+		if dv, ok := defaults.Env[k]; !ok || dv != v {
+			env[k] = v
+		}
+	}
+	md := make(Metadata)
+	for k, v := range dc.Metadata {
+		if dv, ok := defaults.Metadata[k]; !ok || dv != v {
+			md[k] = v
+		}
+	}
+	dc.Resources, dc.Env, dc.Metadata = rs, env, md
+	return dc
+}
+
 func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 	dc := DeployConfig{
 		Resources: make(Resources),
@@ -232,6 +348,12 @@ func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 			break
 		}
 	}
+	for _, c := range dcs {
+		if c.Autoscale != nil {
+			dc.Autoscale = c.Autoscale.Clone()
+			break
+		}
+	}
 	for _, c := range dcs {
 		if len(c.Args) != 0 {
 			dc.Args = c.Args
@@ -28,6 +28,11 @@ type (
 		//     2. The metadata field is the full revision ID of the commit
 		//        which the tag in 1. points to.
 		Version semv.Version `validate:"nonzero"`
+		// Paused, if true, takes the deployment out of service without
+		// removing it: Sous pauses its request in the scheduler, rather than
+		// deleting it, and resumes it when Paused is unset. Changes to a
+		// paused deployment are made once it's resumed.
+		Paused bool `yaml:",omitempty"`
 		// clusterName is the name of the cluster this deployment belongs to. Upon
 		// parsing the Manifest, this will be set to the key in
 		// Manifests.Deployments which points at this Deployment.
@@ -65,10 +70,16 @@ func (spec DeploySpec) Diff(other DeploySpec) (bool, []string) {
 	if !spec.Version.Equals(other.Version) {
 		diff("version; this: %q; other: %q", spec.Version, other.Version)
 	}
+	if spec.Paused != other.Paused {
+		diff("paused; this: %t; other: %t", spec.Paused, other.Paused)
+	}
 	_, configDiffs := spec.DeployConfig.Diff(other.DeployConfig)
 	for _, d := range configDiffs {
 		diff(d)
 	}
+	if !spec.Autoscale.Equal(other.Autoscale) {
+		diff("autoscale; this: %+v; other: %+v", spec.Autoscale, other.Autoscale)
+	}
 	return len(diffs) != 0, diffs
 }
 
@@ -0,0 +1,104 @@
+package sous
+
+import (
+	"sync"
+	"time"
+)
+
+// A DeployThrottle paces the deploys Sous starts in each cluster, according
+// to the MaxConcurrentDeploys and MaxDeploysPerMinute limits of the cluster.
+// It remembers when recent deploys were started, so a single DeployThrottle
+// should be used across resolution cycles.
+type DeployThrottle struct {
+	sync.Mutex
+	started map[string][]time.Time
+	now     func() time.Time
+}
+
+// NewDeployThrottle creates a new DeployThrottle.
+func NewDeployThrottle() *DeployThrottle {
+	return &DeployThrottle{
+		started: map[string][]time.Time{},
+		now:     time.Now,
+	}
+}
+
+// InFlight counts the deployments in each cluster which have been requested
+// but are not yet running.
+func InFlight(actual DeployStates) map[string]int {
+	inFlight := map[string]int{}
+	for _, ds := range actual.Snapshot() {
+		if ds.Status == DeployStatusPending {
+			inFlight[ds.ClusterName]++
+		}
+	}
+	return inFlight
+}
+
+// admit returns true if a new deploy of d may start now, given the number of
+// deploys already in flight in each cluster. If so, the deploy is counted
+// against the limits of its cluster.
+func (t *DeployThrottle) admit(d *Deployment, inFlight map[string]int) bool {
+	t.Lock()
+	defer t.Unlock()
+	name := d.ClusterName
+	now := t.now()
+	recent := t.started[name][:0]
+	for _, at := range t.started[name] {
+		if now.Sub(at) < time.Minute {
+			recent = append(recent, at)
+		}
+	}
+	t.started[name] = recent
+	if c := d.Cluster; c != nil {
+		if c.MaxConcurrentDeploys > 0 && inFlight[name] >= c.MaxConcurrentDeploys {
+			return false
+		}
+		if c.MaxDeploysPerMinute > 0 && len(recent) >= c.MaxDeploysPerMinute {
+			return false
+		}
+	}
+	inFlight[name]++
+	t.started[name] = append(recent, now)
+	return true
+}
+
+// Throttle returns a DeployableChans which passes on the deployables from
+// dc, except that creates and updates exceeding the limits of their cluster
+// are reported to results as queued. Queued deployments are retried on the
+// next resolution cycle, since they will still differ from what's running.
+func (dc *DeployableChans) Throttle(t *DeployThrottle, inFlight map[string]int, results chan<- DiffResolution) *DeployableChans {
+	if t == nil {
+		return dc
+	}
+	out := &DeployableChans{
+		Start:  make(chan *Deployable, cap(dc.Start)),
+		Stop:   dc.Stop,
+		Stable: dc.Stable,
+		Update: make(chan *DeployablePair, cap(dc.Update)),
+	}
+	queued := func(id DeployID) {
+		results <- DiffResolution{DeployID: id, Desc: QueuedDiff}
+	}
+	go func() {
+		for d := range dc.Start {
+			if t.admit(d.Deployment, inFlight) {
+				out.Start <- d
+				continue
+			}
+			queued(d.ID())
+		}
+		close(out.Start)
+	}()
+	go func() {
+		for dp := range dc.Update {
+			if t.admit(dp.Post.Deployment, inFlight) {
+				out.Update <- dp
+				continue
+			}
+			queued(dp.ID())
+		}
+		close(out.Update)
+	}()
+	return out
+}
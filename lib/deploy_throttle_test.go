@@ -0,0 +1,90 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+)
+
+func throttledDeployable(repo string, c *Cluster) *Deployable {
+	return &Deployable{Deployment: &Deployment{
+		ClusterName: c.Name,
+		Cluster:     c,
+		SourceID:    MustParseSourceID(repo + ",1.0.0"),
+	}}
+}
+
+func TestDeployThrottle_MaxConcurrent(t *testing.T) {
+	assert := assert.New(t)
+	c := &Cluster{Name: "x", MaxConcurrentDeploys: 2}
+	th := NewDeployThrottle()
+	inFlight := map[string]int{"x": 1}
+
+	assert.True(th.admit(throttledDeployable("github.com/ot/one", c).Deployment, inFlight))
+	assert.False(th.admit(throttledDeployable("github.com/ot/two", c).Deployment, inFlight))
+	assert.Equal(2, inFlight["x"])
+}
+
+func TestDeployThrottle_PerMinute(t *testing.T) {
+	assert := assert.New(t)
+	c := &Cluster{Name: "x", MaxDeploysPerMinute: 2}
+	now := time.Unix(1000000, 0)
+	th := NewDeployThrottle()
+	th.now = func() time.Time { return now }
+
+	d := throttledDeployable("github.com/ot/one", c).Deployment
+	assert.True(th.admit(d, map[string]int{}))
+	assert.True(th.admit(d, map[string]int{}))
+	assert.False(th.admit(d, map[string]int{}))
+
+	now = now.Add(61 * time.Second)
+	assert.True(th.admit(d, map[string]int{}))
+
+	unlimited := throttledDeployable("github.com/ot/one", &Cluster{Name: "y"}).Deployment
+	for i := 0; i < 10; i++ {
+		assert.True(th.admit(unlimited, map[string]int{}))
+	}
+}
+
+func TestDeployableChans_Throttle(t *testing.T) {
+	assert := assert.New(t)
+	c := &Cluster{Name: "x", MaxConcurrentDeploys: 1}
+	dc := NewDeployableChans(10)
+	dc.Start <- throttledDeployable("github.com/ot/one", c)
+	dc.Start <- throttledDeployable("github.com/ot/two", c)
+	close(dc.Start)
+	close(dc.Update)
+
+	results := make(chan DiffResolution, 10)
+	out := dc.Throttle(NewDeployThrottle(), map[string]int{}, results)
+
+	var started []*Deployable
+	for d := range out.Start {
+		started = append(started, d)
+	}
+	for range out.Update {
+	}
+	close(results)
+
+	assert.Len(started, 1)
+	var queued []DiffResolution
+	for rez := range results {
+		queued = append(queued, rez)
+	}
+	if assert.Len(queued, 1) {
+		assert.Equal(QueuedDiff, queued[0].Desc)
+		assert.NotEqual(started[0].ID(), queued[0].DeployID)
+	}
+}
+
+func TestInFlight(t *testing.T) {
+	actual := NewDeployStates()
+	for i, stat := range []DeployStatus{DeployStatusPending, DeployStatusActive, DeployStatusPending} {
+		ds := &DeployState{Status: stat, Deployment: Deployment{ClusterName: "x"}}
+		ds.SourceID = MustParseSourceID("github.com/ot/one,1.0.0")
+		ds.Flavor = string('a' + rune(i))
+		actual.Add(ds)
+	}
+	assert.Equal(t, map[string]int{"x": 2}, InFlight(actual))
+}
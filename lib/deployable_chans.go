@@ -3,6 +3,8 @@ package sous
 import (
 	"fmt"
 	"sync"
+
+	"github.com/pkg/errors"
 )
 
 type (
@@ -45,21 +47,29 @@ func GuardImage(r Registry, d *Deployment) (*BuildArtifact, error) {
 		return nil, nil
 	}
 	art, err := r.GetArtifact(d.SourceID)
+	if moved, ok := errors.Cause(err).(*TagMovedError); ok {
+		return nil, moved
+	}
 	if err != nil {
 		return nil, &MissingImageNameError{err}
 	}
+	if art, err = acceptableArtifact(d, art); err != nil {
+		return nil, err
+	}
+	asserted := map[string]struct{}{}
 	for _, q := range art.Qualities {
+		if q.Kind == "assertion" {
+			asserted[q.Name] = struct{}{}
+		}
 		if q.Kind == "advisory" {
 			if q.Name == "" {
 				continue
 			}
 			advisoryIsValid := false
-			var allowedAdvisories []string
 			if d.Cluster == nil {
 				return nil, fmt.Errorf("nil cluster on deployment %q", d)
 			}
-			allowedAdvisories = d.Cluster.AllowedAdvisories
-			for _, aa := range allowedAdvisories {
+			for _, aa := range d.Cluster.AllowedAdvisories {
 				if aa == q.Name {
 					advisoryIsValid = true
 					break
@@ -70,9 +80,31 @@ func GuardImage(r Registry, d *Deployment) (*BuildArtifact, error) {
 			}
 		}
 	}
+	if d.Cluster == nil {
+		return art, err
+	}
+	var missing []string
+	for _, rq := range d.Cluster.RequiredQualities {
+		if _, ok := asserted[rq]; !ok {
+			missing = append(missing, rq)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingQualitiesError{SourceID: d.SourceID, Cluster: d.ClusterName, Missing: missing}
+	}
 	return art, err
 }
 
+// acceptableArtifact returns art if the cluster of d accepts its type.
+// Artifacts aren't converted here: files are pushed into the registry as
+// they're built, since only the build host can read them.
+func acceptableArtifact(d *Deployment, art *BuildArtifact) (*BuildArtifact, error) {
+	if d.Cluster.AcceptsArtifactType(art.Type) {
+		return art, nil
+	}
+	return nil, &UnacceptableArtifactTypeError{SourceID: d.SourceID, Cluster: d.ClusterName, Type: art.Type}
+}
+
 // ID returns the ID of this DeployablePair.
 func (dp *DeployablePair) ID() DeployID {
 	return dp.name
@@ -94,7 +126,7 @@ func resolveCreates(r Registry, from chan *DeploymentPair, to chan *Deployable,
 		dep := dp.Post
 		Log.Vomit.Printf("Deployment processed, needs artifact: %#v", dep)
 
-		da, err := resolveName(r, dep, dp.Status)
+		da, err := resolveDeployedName(r, dep, dp.Status)
 		if err != nil {
 			Log.Info.Printf("Unable to create new deployment %q: %s", dep.ID(), err)
 			Log.Debug.Printf("Failed create deployment %q: % #v", dep.ID(), dep)
@@ -166,9 +198,44 @@ func resolveName(r Registry, dep *Deployment, stat DeployStatus) (*Deployable, e
 	return d, err
 }
 
+// resolveDeployedName is resolveName for a deployment which is about to be
+// deployed, and so whose artifact is replicated into its cluster's registry.
+func resolveDeployedName(r Registry, dep *Deployment, stat DeployStatus) (*Deployable, error) {
+	d, err := resolveName(r, dep, stat)
+	if err != nil {
+		return d, err
+	}
+	art, err := replicate(r, dep, d.BuildArtifact)
+	if err != nil {
+		d.BuildArtifact = nil
+		return d, err
+	}
+	d.BuildArtifact = art
+	return d, nil
+}
+
+// replicate copies art into the registry of the deployment's cluster, if the
+// cluster names one and r can replicate artifacts, and returns the artifact as
+// named there.
+func replicate(r Registry, dep *Deployment, art *BuildArtifact) (*BuildArtifact, error) {
+	if art == nil || dep.Cluster == nil || dep.Cluster.DockerRepo == "" {
+		return art, nil
+	}
+	rep, ok := r.(Replicator)
+	if !ok {
+		return art, nil
+	}
+	copied, err := rep.Replicate(dep.SourceID, art, dep.Cluster.DockerRepo)
+	if err != nil {
+		return nil, &ReplicationError{SourceID: dep.SourceID, Registry: dep.Cluster.DockerRepo, Err: err}
+	}
+	return copied, nil
+}
+
 func resolvePair(r Registry, depPair *DeploymentPair) (*DeployablePair, error) {
+	// Only the artifact of Post is to be deployed, so only it is replicated.
 	prior, _ := resolveName(r, depPair.Prior, depPair.Status)
-	post, err := resolveName(r, depPair.Post, depPair.Status)
+	post, err := resolveDeployedName(r, depPair.Post, depPair.Status)
 
 	return &DeployablePair{name: depPair.name, Prior: prior, Post: post}, err
 }
@@ -34,6 +34,9 @@ type (
 		Owners OwnerSet
 		// Kind is the kind of software that SourceRepo represents.
 		Kind ManifestKind
+		// Paused is true if the deployment is paused in its cluster. See
+		// DeploySpec.Paused.
+		Paused bool
 		// Notes collected from the deployment's source.
 		Annotation
 	}
@@ -83,7 +86,7 @@ func (vs Volumes) Clone() Volumes {
 }
 
 func (d *Deployment) String() string {
-	return fmt.Sprintf("%s @ %s %s", d.SourceID, d.Cluster, d.DeployConfig.String())
+	return fmt.Sprintf("%s @ %v %s", d.SourceID, d.Cluster, d.DeployConfig.String())
 }
 
 // ID returns the DeployID of this deployment.
@@ -183,6 +186,9 @@ func (d *Deployment) Diff(o *Deployment) (bool, []string) {
 	if d.Kind != o.Kind {
 		diff("kind; this: %q; other: %q", d.Kind, o.Kind)
 	}
+	if d.Paused != o.Paused {
+		diff("paused; this: %t; other: %t", d.Paused, o.Paused)
+	}
 	if len(d.Owners) != len(o.Owners) {
 		// TODO: Make sure owners get written to Singularity, then uncomment next line.
 		//diff("number of owners; this: %+v; other: %+v", len(d.Owners), len(o.Owners))
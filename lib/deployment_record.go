@@ -0,0 +1,198 @@
+package sous
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// A DeploymentRecord is the stable form in which a Deployment is output
+	// for scripts, e.g. by `sous query gdm -format json`. Fields may be added
+	// to it, but none will be renamed or removed.
+	DeploymentRecord struct {
+		Cluster      string
+		Repo         string
+		Offset       string
+		Flavor       string
+		Version      string
+		Kind         string
+		Owners       []string
+		NumInstances int
+		Resources    map[string]string
+		Env          map[string]string
+		Metadata     map[string]string
+		Args         []string
+		Volumes      []VolumeRecord
+		Paused       bool
+	}
+
+	// A VolumeRecord is the stable output form of a Volume.
+	VolumeRecord struct {
+		Host, Container, Mode string
+	}
+
+	deployIDs []DeployID
+
+	// A DeployStateRecord is the stable output form of a DeployState: its
+	// Deployment, with its Status, which is one of "Pending", "Active" or
+	// "Failed".
+	DeployStateRecord struct {
+		DeploymentRecord `yaml:",inline"`
+		Status           string
+	}
+
+	// A ResolveStatusRecord is the stable output form of a ResolveStatus.
+	ResolveStatusRecord struct {
+		Phase string
+		// Log lists how each deployment was resolved.
+		Log []DiffResolutionRecord
+		// Errors lists the errors that stopped the resolution.
+		Errors []string
+		// Queued lists the deployments held back by cluster deploy limits.
+		Queued []DeployIDRecord
+	}
+
+	// A DiffResolutionRecord is the stable output form of a DiffResolution:
+	// the deployment resolved, and its Resolution, which is one of
+	// "unchanged", "coming", "created", "updated", "deleted" or "queued".
+	DiffResolutionRecord struct {
+		DeployIDRecord `yaml:",inline"`
+		Resolution     string
+		// Error is the error resolving the deployment, if there was one.
+		Error string `json:",omitempty" yaml:",omitempty"`
+	}
+
+	// A DeployIDRecord is the stable output form of a DeployID.
+	DeployIDRecord struct {
+		Cluster string
+		Repo    string
+		Offset  string
+		Flavor  string
+	}
+)
+
+// Record returns the stable output form of d.
+func (d *Deployment) Record() DeploymentRecord {
+	r := DeploymentRecord{
+		Cluster:      d.ClusterName,
+		Repo:         d.SourceID.Location.Repo,
+		Offset:       d.SourceID.Location.Dir,
+		Flavor:       d.Flavor,
+		Version:      d.SourceID.Version.String(),
+		Kind:         string(d.Kind),
+		Owners:       d.Owners.Slice(),
+		NumInstances: d.NumInstances,
+		Resources:    map[string]string{},
+		Env:          map[string]string{},
+		Metadata:     map[string]string{},
+		Args:         append([]string{}, d.Args...),
+		Volumes:      []VolumeRecord{},
+		Paused:       d.Paused,
+	}
+	for k, v := range d.Resources {
+		r.Resources[k] = v
+	}
+	for k, v := range d.Env {
+		r.Env[k] = v
+	}
+	for k, v := range d.Metadata {
+		r.Metadata[k] = v
+	}
+	for _, v := range d.Volumes {
+		if v != nil {
+			r.Volumes = append(r.Volumes, VolumeRecord{Host: v.Host, Container: v.Container, Mode: string(v.Mode)})
+		}
+	}
+	return r
+}
+
+// Record returns the stable output form of ds.
+func (ds *DeployState) Record() DeployStateRecord {
+	return DeployStateRecord{
+		DeploymentRecord: ds.Deployment.Record(),
+		Status:           strings.TrimPrefix(ds.Status.String(), "DeployStatus"),
+	}
+}
+
+// Record returns the stable output form of rs.
+func (rs *ResolveStatus) Record() ResolveStatusRecord {
+	r := ResolveStatusRecord{
+		Phase:  rs.Phase,
+		Log:    []DiffResolutionRecord{},
+		Errors: []string{},
+		Queued: []DeployIDRecord{},
+	}
+	for _, rez := range rs.Log {
+		r.Log = append(r.Log, rez.Record())
+	}
+	for _, e := range rs.Errs.Causes {
+		r.Errors = append(r.Errors, e.message())
+	}
+	for _, id := range rs.Queued {
+		r.Queued = append(r.Queued, id.Record())
+	}
+	return r
+}
+
+// Record returns the stable output form of rez.
+func (rez DiffResolution) Record() DiffResolutionRecord {
+	r := DiffResolutionRecord{
+		DeployIDRecord: rez.DeployID.Record(),
+		Resolution:     string(rez.Desc),
+	}
+	if rez.Error != nil {
+		r.Error = rez.Error.message()
+	}
+	return r
+}
+
+// Record returns the stable output form of did.
+func (did DeployID) Record() DeployIDRecord {
+	return DeployIDRecord{
+		Cluster: did.Cluster,
+		Repo:    did.ManifestID.Source.Repo,
+		Offset:  did.ManifestID.Source.Dir,
+		Flavor:  did.ManifestID.Flavor,
+	}
+}
+
+// Records returns the stable output forms of ds, ordered by DeployID.
+func (ds Deployments) Records() []DeploymentRecord {
+	rs := []DeploymentRecord{}
+	for _, id := range sortedDeployIDs(ds.Keys()) {
+		d, _ := ds.Get(id)
+		rs = append(rs, d.Record())
+	}
+	return rs
+}
+
+// Records returns the stable output forms of ds, ordered by DeployID.
+func (ds DeployStates) Records() []DeployStateRecord {
+	rs := []DeployStateRecord{}
+	for _, id := range sortedDeployIDs(ds.Keys()) {
+		d, _ := ds.Get(id)
+		rs = append(rs, d.Record())
+	}
+	return rs
+}
+
+func sortedDeployIDs(ids []DeployID) []DeployID {
+	sort.Sort(deployIDs(ids))
+	return ids
+}
+
+func (ids deployIDs) Len() int      { return len(ids) }
+func (ids deployIDs) Swap(i, j int) { ids[i], ids[j] = ids[j], ids[i] }
+func (ids deployIDs) Less(i, j int) bool {
+	a, b := ids[i], ids[j]
+	if a.ManifestID.Source.Repo != b.ManifestID.Source.Repo {
+		return a.ManifestID.Source.Repo < b.ManifestID.Source.Repo
+	}
+	if a.ManifestID.Source.Dir != b.ManifestID.Source.Dir {
+		return a.ManifestID.Source.Dir < b.ManifestID.Source.Dir
+	}
+	if a.ManifestID.Flavor != b.ManifestID.Flavor {
+		return a.ManifestID.Flavor < b.ManifestID.Flavor
+	}
+	return a.Cluster < b.Cluster
+}
@@ -0,0 +1,88 @@
+package sous
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestDeploymentRecords(t *testing.T) {
+	ds := NewDeployStates()
+	for _, repo := range []string{"github.com/b/b", "github.com/a/a"} {
+		ds.Add(&DeployState{
+			Status: DeployStatusActive,
+			Deployment: Deployment{
+				ClusterName: "cluster-1",
+				SourceID:    MustNewSourceID(repo, "", "1.2.3"),
+				Kind:        ManifestKindService,
+				Owners:      NewOwnerSet("sam"),
+				DeployConfig: DeployConfig{
+					NumInstances: 2,
+					Resources:    Resources{"cpus": "1"},
+					Volumes:      Volumes{{Host: "/h", Container: "/c", Mode: "RO"}},
+				},
+			},
+		})
+	}
+
+	b, err := json.Marshal(ds.Records())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[` +
+		`{"Cluster":"cluster-1","Repo":"github.com/a/a","Offset":"","Flavor":"","Version":"1.2.3","Kind":"http-service",` +
+		`"Owners":["sam"],"NumInstances":2,"Resources":{"cpus":"1"},"Env":{},"Metadata":{},"Args":[],` +
+		`"Volumes":[{"Host":"/h","Container":"/c","Mode":"RO"}],"Paused":false,"Status":"Active"},` +
+		`{"Cluster":"cluster-1","Repo":"github.com/b/b","Offset":"","Flavor":"","Version":"1.2.3","Kind":"http-service",` +
+		`"Owners":["sam"],"NumInstances":2,"Resources":{"cpus":"1"},"Env":{},"Metadata":{},"Args":[],` +
+		`"Volumes":[{"Host":"/h","Container":"/c","Mode":"RO"}],"Paused":false,"Status":"Active"}` +
+		`]`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+// TestResolveStatusJSON pins the JSON form of ResolveStatus, which the server
+// reports at /status, and scripts rely on.
+func TestResolveStatusJSON(t *testing.T) {
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "github.com/a/a"}}, Cluster: "cluster-1"}
+	rs := ResolveStatus{
+		Phase: "finished",
+		Log:   []DiffResolution{{DeployID: id, Desc: CreateDiff}},
+		Errs:  ResolveErrors{Causes: []ErrorWrapper{}},
+	}
+	b, err := json.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Phase":"finished","Log":[{"ManifestID":"github.com/a/a",` +
+		`"Cluster":"cluster-1","Desc":"created","Error":null}],"Errs":{"Causes":[]}}`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+func TestResolveStatusRecord(t *testing.T) {
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "github.com/a/a"}}, Cluster: "cluster-1"}
+	rs := ResolveStatus{
+		Phase: "finished",
+		Log: []DiffResolution{
+			{DeployID: id, Desc: CreateDiff},
+			{DeployID: id, Desc: ModifyDiff, Error: WrapResolveError(fmt.Errorf("no artifact"))},
+		},
+		Errs:   ResolveErrors{Causes: []ErrorWrapper{{MarshallableError: MarshallableError{String: "no GDM"}}}},
+		Queued: []DeployID{id},
+	}
+	b, err := json.Marshal(rs.Record())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Phase":"finished","Log":[` +
+		`{"Cluster":"cluster-1","Repo":"github.com/a/a","Offset":"","Flavor":"","Resolution":"created"},` +
+		`{"Cluster":"cluster-1","Repo":"github.com/a/a","Offset":"","Flavor":"","Resolution":"updated","Error":"no artifact"}],` +
+		`"Errors":["no GDM"],` +
+		`"Queued":[{"Cluster":"cluster-1","Repo":"github.com/a/a","Offset":"","Flavor":""}]}`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
@@ -19,6 +19,9 @@ func TestDeploymentEqual(t *testing.T) {
 		},
 	}
 	assert.True(dep.Equal(&other))
+
+	other.Paused = true
+	assert.False(dep.Equal(&other))
 }
 
 func TestCanonName(t *testing.T) {
@@ -71,5 +74,12 @@ func TestBuildDeployment(t *testing.T) {
 			assert.Equal("c", d.DeployConfig.Volumes[0].Container)
 		}
 		assert.Equal(nick, d.ClusterName)
+		assert.False(d.Paused)
+	}
+
+	sp.Paused = true
+	d, err = BuildDeployment(state, m, nick, sp, ih)
+	if assert.NoError(err) {
+		assert.True(d.Paused)
 	}
 }
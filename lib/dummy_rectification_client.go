@@ -12,11 +12,24 @@ type (
 		Created  []Deployable
 		Deployed []Deployable
 		Deleted  []dummyDelete
+		Scaled   []dummyScale
+		Paused   []dummyPause
+		Unpaused []dummyPause
 	}
 
 	dummyDelete struct {
 		Cluster, Reqid, Message string
 	}
+
+	dummyPause struct {
+		Cluster, Reqid, Message string
+	}
+
+	dummyScale struct {
+		Cluster, Reqid string
+		Count          int
+		Message        string
+	}
 )
 
 // NewDummyRectificationClient builds a new DummyRectificationClient
@@ -63,3 +76,25 @@ func (drc *DummyRectificationClient) DeleteRequest(
 	drc.Deleted = append(drc.Deleted, dummyDelete{cluster, reqid, message})
 	return nil
 }
+
+// Scale (cluster url, request id, instance count, message)
+func (drc *DummyRectificationClient) Scale(
+	cluster, reqid string, count int, message string) error {
+	drc.logf("Scaling application %s %s %d %s", cluster, reqid, count, message)
+	drc.Scaled = append(drc.Scaled, dummyScale{cluster, reqid, count, message})
+	return nil
+}
+
+// Pause (cluster url, request id, message)
+func (drc *DummyRectificationClient) Pause(cluster, reqid, message string) error {
+	drc.logf("Pausing application %s %s %s", cluster, reqid, message)
+	drc.Paused = append(drc.Paused, dummyPause{cluster, reqid, message})
+	return nil
+}
+
+// Unpause (cluster url, request id, message)
+func (drc *DummyRectificationClient) Unpause(cluster, reqid, message string) error {
+	drc.logf("Unpausing application %s %s %s", cluster, reqid, message)
+	drc.Unpaused = append(drc.Unpaused, dummyPause{cluster, reqid, message})
+	return nil
+}
@@ -1,54 +1,37 @@
 package sous
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
-	"net/url"
-
 	"github.com/pkg/errors"
 )
 
 // An HTTPNameInserter sends its inserts to the configured HTTP server
 type HTTPNameInserter struct {
-	serverURL *url.URL
-	http.Client
+	// client is the client for the server, which carries the credentials and
+	// TLS configuration every other request to it uses.
+	client *LiveHTTPClient
+	user   User
 }
 
-// NewHTTPNameInserter creates a new HTTPNameInserter
-func NewHTTPNameInserter(server string) (*HTTPNameInserter, error) {
-	u, err := url.Parse(server)
-	return &HTTPNameInserter{
-		serverURL: u,
-	}, errors.Wrapf(err, "new state manager")
+// NewHTTPNameInserter creates a new HTTPNameInserter, which sends its inserts
+// as user with client.
+func NewHTTPNameInserter(client *LiveHTTPClient, user User) *HTTPNameInserter {
+	return &HTTPNameInserter{client: client, user: user}
 }
 
-// Insert implements Inserter for HTTPNameInserter
+// Insert implements Inserter for HTTPNameInserter. Artifacts are PUT without
+// preconditions: the server records the artifact for sid whatever it had
+// before.
 func (hni *HTTPNameInserter) Insert(sid SourceID, in, etag string, qs []Quality) error {
-	url, err := hni.serverURL.Parse("./artifact")
-	if err != nil {
-		return errors.Wrapf(err, "http insert name: %s for %v", in, sid)
-	}
-	url.RawQuery = sid.QueryValues().Encode()
-	art := &BuildArtifact{Name: in, Type: "docker", Qualities: qs}
-	buf := &bytes.Buffer{}
-	enc := json.NewEncoder(buf)
-	err = enc.Encode(art)
-	if err != nil {
-		return errors.Wrapf(err, "http insert name %s, encoding %v", in, art)
-	}
-
-	req, err := http.NewRequest("PUT", url.String(), buf)
-	if err != nil {
-		return errors.Wrapf(err, "http insert name %s, building request for %s/%v", in, url, art)
-	}
-
-	rz, err := hni.Client.Do(req)
-	if err != nil {
-		return errors.Wrapf(err, "http insert name %s, sending %v", in, req)
-	}
-	if rz.StatusCode >= 200 && rz.StatusCode < 300 {
-		return nil
+	art := &BuildArtifact{Name: in, Type: ArtifactTypeDocker, Qualities: qs}
+	vs := sid.QueryValues()
+	qParms := map[string]string{}
+	for k := range vs {
+		qParms[k] = vs.Get(k)
 	}
-	return errors.Errorf("Received %s when attempting %v", rz.Status, req)
+	return errors.Wrapf(func() error {
+		url, err := hni.client.buildURL("./artifact", qParms)
+		rq, err := hni.client.buildRequest("PUT", url, hni.user, nil, art, err)
+		rz, err := hni.client.sendRequest(rq, err)
+		return hni.client.getBody(rz, nil, err)
+	}(), "http insert name %s for %v", in, sid)
 }
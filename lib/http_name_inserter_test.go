@@ -1,6 +1,8 @@
 package sous
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +19,9 @@ func TestHTTPNameInserter(t *testing.T) {
 		if meth := r.Method; strings.ToUpper(meth) != "PUT" {
 			t.Errorf("Method should be PUT was: %s", meth)
 		}
+		if r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != "" {
+			t.Errorf("PUT /artifact should be unconditional, was sent %v", r.Header)
+		}
 		if path := r.URL.Path; path != "/artifact" {
 			t.Errorf("Path should be '/artifact' but was: %s", path)
 		}
@@ -33,10 +38,11 @@ func TestHTTPNameInserter(t *testing.T) {
 
 	srv := httptest.NewServer(http.HandlerFunc(h))
 
-	hni, err := NewHTTPNameInserter(srv.URL)
+	cl, err := NewClient(srv.URL)
 	if err != nil {
 		t.Error(err)
 	}
+	hni := NewHTTPNameInserter(cl, User{})
 	err = hni.Insert(
 		SourceID{Location: SourceLocation{Repo: "a-repo", Dir: "offset"}, Version: semv.MustParse("5.5.5")},
 		"dockerthin.com/repo/latest",
@@ -50,3 +56,36 @@ func TestHTTPNameInserter(t *testing.T) {
 		t.Errorf("No request issued")
 	}
 }
+
+func TestHTTPNameInserterTLS(t *testing.T) {
+	reqd := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		reqd = true
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hni := NewHTTPNameInserter(cl, User{})
+	sid := SourceID{Location: SourceLocation{Repo: "a-repo"}, Version: semv.MustParse("5.5.5")}
+
+	if err := hni.Insert(sid, "dockerthin.com/repo/latest", "", nil); err == nil {
+		t.Errorf("inserting to a server with an untrusted certificate returned nil error")
+	}
+
+	cert, err := x509.ParseCertificate(srv.TLS.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cas := x509.NewCertPool()
+	cas.AddCert(cert)
+	cl.SetTLSConfig(&tls.Config{RootCAs: cas})
+	if err := hni.Insert(sid, "dockerthin.com/repo/latest", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reqd {
+		t.Errorf("No request issued")
+	}
+}
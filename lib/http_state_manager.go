@@ -76,21 +76,24 @@ func (hsm *HTTPStateManager) WriteState(s *State, u User) error {
 	diff := cds.Diff(wds)
 	cchs := diff.Concentrate(s.Defs)
 	Log.Debug.Printf("Processing diffs...")
-	return hsm.process(cchs)
+	return hsm.process(cchs, s.Manifests)
 }
 
-func (hsm *HTTPStateManager) process(dc DiffConcentrator) error {
+// process sends the changes in dc to the server. Created and modified
+// manifests are sent as they are in ms, with their Defaults, rather than as
+// dc rebuilds them from deployments.
+func (hsm *HTTPStateManager) process(dc DiffConcentrator, ms Manifests) error {
 	done := make(chan struct{})
 	defer close(done)
 
 	createErrs := make(chan error)
-	go hsm.creates(dc.Created, createErrs, done)
+	go hsm.creates(ms, dc.Created, createErrs, done)
 
 	deleteErrs := make(chan error)
 	go hsm.deletes(dc.Deleted, deleteErrs, done)
 
 	modifyErrs := make(chan error)
-	go hsm.modifies(dc.Modified, modifyErrs, done)
+	go hsm.modifies(ms, dc.Modified, modifyErrs, done)
 
 	retainErrs := make(chan error)
 	go hsm.retains(dc.Retained, retainErrs, done)
@@ -144,7 +147,7 @@ func (hsm *HTTPStateManager) retains(mc chan *Manifest, ec chan error, done chan
 	}
 }
 
-func (hsm *HTTPStateManager) creates(mc chan *Manifest, ec chan error, done chan struct{}) {
+func (hsm *HTTPStateManager) creates(ms Manifests, mc chan *Manifest, ec chan error, done chan struct{}) {
 	defer close(ec)
 	for {
 		select {
@@ -154,7 +157,7 @@ func (hsm *HTTPStateManager) creates(mc chan *Manifest, ec chan error, done chan
 			if !open {
 				return
 			}
-			if err := hsm.create(m); err != nil {
+			if err := hsm.create(ownManifest(ms, m)); err != nil {
 				ec <- err
 			}
 		}
@@ -178,7 +181,7 @@ func (hsm *HTTPStateManager) deletes(mc chan *Manifest, ec chan error, done chan
 	}
 }
 
-func (hsm *HTTPStateManager) modifies(mc chan *ManifestPair, ec chan error, done chan struct{}) {
+func (hsm *HTTPStateManager) modifies(ms Manifests, mc chan *ManifestPair, ec chan error, done chan struct{}) {
 	defer close(ec)
 	for {
 		select {
@@ -189,7 +192,8 @@ func (hsm *HTTPStateManager) modifies(mc chan *ManifestPair, ec chan error, done
 				return
 			}
 			Log.Debug.Printf("Modifying %q", m.name)
-			if err := hsm.modify(m); err != nil {
+			own := &ManifestPair{name: m.name, Prior: m.Prior, Post: ownManifest(ms, m.Post)}
+			if err := hsm.modify(own); err != nil {
 				ec <- err
 			}
 		}
@@ -198,6 +202,15 @@ func (hsm *HTTPStateManager) modifies(mc chan *ManifestPair, ec chan error, done
 
 ////
 
+// ownManifest returns the manifest in ms with m's ID, which has its own
+// Defaults, or m if ms has none.
+func ownManifest(ms Manifests, m *Manifest) *Manifest {
+	if o, ok := ms.Get(m.ID()); ok {
+		return o
+	}
+	return m
+}
+
 func (hsm *HTTPStateManager) getDefs() (Defs, error) {
 	ds := Defs{}
 	return ds, errors.Wrapf(hsm.Retrieve("./defs", nil, &ds, hsm.User), "getting defs")
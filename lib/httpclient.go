@@ -2,7 +2,9 @@ package sous
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -17,6 +19,9 @@ type (
 	LiveHTTPClient struct {
 		serverURL *url.URL
 		http.Client
+		// AuthToken, if not empty, is sent as a bearer token with every
+		// request.
+		AuthToken string
 	}
 
 	// HTTPClient interacts with a HTTPServer
@@ -47,8 +52,27 @@ type (
 
 	// Variances is a list of differences between two structs.
 	Variances []string
+
+	// A ResponseError is the error a LiveHTTPClient returns when the server
+	// answers with a status other than success.
+	ResponseError struct {
+		StatusCode int
+		Status     string
+		Body       string
+	}
 )
 
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s: %#v", e.Status, e.Body)
+}
+
+// IsNotFound returns true if err is a ResponseError with the status 404, or
+// was caused by one.
+func IsNotFound(err error) bool {
+	re, ok := errors.Cause(err).(*ResponseError)
+	return ok && re.StatusCode == http.StatusNotFound
+}
+
 // NewClient returns a new LiveHTTPClient for a particular serverURL.
 func NewClient(serverURL string) (*LiveHTTPClient, error) {
 	u, err := url.Parse(serverURL)
@@ -67,6 +91,32 @@ func NewClient(serverURL string) (*LiveHTTPClient, error) {
 	return client, errors.Wrapf(err, "new Sous REST client")
 }
 
+// SetTLSConfig configures the TLS settings this client (and any clients
+// derived from it with ForServer) uses to connect to servers.
+func (client *LiveHTTPClient) SetTLSConfig(tc *tls.Config) {
+	t, ok := client.Client.Transport.(*http.Transport)
+	if !ok {
+		t = &http.Transport{Proxy: http.ProxyFromEnvironment, DisableCompression: true}
+		client.Client.Transport = t
+	}
+	t.TLSClientConfig = tc
+}
+
+// ForServer returns a new LiveHTTPClient for serverURL which shares this
+// client's transport and credentials. It's used to talk to sibling servers.
+func (client *LiveHTTPClient) ForServer(serverURL string) (*LiveHTTPClient, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "new Sous REST client")
+	}
+	sibling := &LiveHTTPClient{
+		serverURL: u,
+		AuthToken: client.AuthToken,
+	}
+	sibling.Client.Transport = client.Client.Transport
+	return sibling, nil
+}
+
 // ****
 
 // Retrieve makes a GET request on urlPath, after transforming qParms into ?&=
@@ -213,9 +263,15 @@ func (client *LiveHTTPClient) buildRequest(method, url string, user User, header
 	}
 
 	rq, err := http.NewRequest(method, url, JSON)
+	if err != nil {
+		return nil, err
+	}
 
 	rq.Header.Add("Sous-User-Name", user.Name)
 	rq.Header.Add("Sous-User-Email", user.Email)
+	if client.AuthToken != "" {
+		rq.Header.Add("Authorization", "Bearer "+client.AuthToken)
+	}
 
 	if headers != nil {
 		for k, v := range headers {
@@ -223,7 +279,7 @@ func (client *LiveHTTPClient) buildRequest(method, url string, user User, header
 		}
 	}
 
-	return rq, err
+	return rq, nil
 }
 
 func (client *LiveHTTPClient) sendRequest(rq *http.Request, ierr error) (*http.Response, error) {
@@ -257,7 +313,7 @@ func (client *LiveHTTPClient) getBody(rz *http.Response, rzBody interface{}, err
 		if e != nil {
 			b = []byte{}
 		}
-		return errors.Errorf("%s: %#v", rz.Status, string(b))
+		return &ResponseError{StatusCode: rz.StatusCode, Status: rz.Status, Body: string(b)}
 	}
 	return errors.Wrapf(err, "processing response body")
 }
@@ -0,0 +1,155 @@
+package sous
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Lease grants leadership to its Holder until it Expires.
+	Lease struct {
+		// Holder identifies the leader, usually by the URL other servers can
+		// reach it at.
+		Holder string
+		// Expires is the time at which the lease lapses unless renewed.
+		Expires time.Time
+	}
+
+	// A LeaseBackend stores the single leadership lease shared by a group of
+	// redundant Sous servers.
+	LeaseBackend interface {
+		// Acquire takes the lease for holder until expires, if it is free,
+		// expired or already held by holder. It returns the lease as it stands
+		// after the attempt, whoever holds it.
+		Acquire(holder string, now, expires time.Time) (Lease, error)
+		// Release gives up the lease, if it is held by holder.
+		Release(holder string) error
+	}
+
+	// Leadership campaigns for a lease on behalf of a server, so that only one
+	// of a group of redundant servers acts as leader at a time.
+	Leadership struct {
+		Backend LeaseBackend
+		// ID identifies this server as a lease Holder.
+		ID string
+		// TTL is how long each acquired lease lasts.
+		TTL   time.Duration
+		now   func() time.Time
+		lease Lease
+		sync.RWMutex
+	}
+
+	// MemoryLeaseBackend is a LeaseBackend for servers sharing a process,
+	// useful for testing.
+	MemoryLeaseBackend struct {
+		lease Lease
+		sync.Mutex
+	}
+)
+
+// NewLeadership creates a Leadership campaigning as id.
+func NewLeadership(backend LeaseBackend, id string, ttl time.Duration) *Leadership {
+	return &Leadership{
+		Backend: backend,
+		ID:      id,
+		TTL:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Campaign attempts to acquire or renew the lease, and returns true if this
+// server is now the leader.
+func (l *Leadership) Campaign() (bool, error) {
+	now := l.now()
+	lease, err := l.Backend.Acquire(l.ID, now, now.Add(l.TTL))
+	if err != nil {
+		l.Lock()
+		l.lease = Lease{}
+		l.Unlock()
+		return false, errors.Wrap(err, "campaigning for leadership")
+	}
+	l.Lock()
+	l.lease = lease
+	l.Unlock()
+	return l.IsLeader(), nil
+}
+
+// Resign releases the lease if this server holds it.
+func (l *Leadership) Resign() error {
+	l.Lock()
+	l.lease = Lease{}
+	l.Unlock()
+	return l.Backend.Release(l.ID)
+}
+
+// IsLeader returns true if this server held an unexpired lease when it last
+// campaigned. A nil *Leadership is always the leader.
+func (l *Leadership) IsLeader() bool {
+	if l == nil {
+		return true
+	}
+	lease := l.Leader()
+	return lease.Holder == l.ID && l.now().Before(lease.Expires)
+}
+
+// Leader returns the lease as of the last campaign. If it has expired, the
+// returned Lease is empty.
+func (l *Leadership) Leader() Lease {
+	if l == nil {
+		return Lease{}
+	}
+	l.RLock()
+	defer l.RUnlock()
+	if !l.now().Before(l.lease.Expires) {
+		return Lease{}
+	}
+	return l.lease
+}
+
+// maintain renews the lease every third of its TTL until done is closed.
+func (l *Leadership) maintain(done TriggerChannel, ls *LogSet) {
+	for {
+		if _, err := l.Campaign(); err != nil {
+			ls.Warn.Print(err)
+		}
+		select {
+		case <-done:
+			if err := l.Resign(); err != nil {
+				ls.Warn.Print(err)
+			}
+			return
+		case <-time.After(l.TTL / 3):
+		}
+	}
+}
+
+// Acquire implements LeaseBackend on MemoryLeaseBackend.
+func (mb *MemoryLeaseBackend) Acquire(holder string, now, expires time.Time) (Lease, error) {
+	mb.Lock()
+	defer mb.Unlock()
+	mb.lease = AcquireLease(mb.lease, holder, now, expires)
+	return mb.lease, nil
+}
+
+// Release implements LeaseBackend on MemoryLeaseBackend.
+func (mb *MemoryLeaseBackend) Release(holder string) error {
+	mb.Lock()
+	defer mb.Unlock()
+	if mb.lease.Holder == holder {
+		mb.lease = Lease{}
+	}
+	return nil
+}
+
+// AcquireLease returns the lease resulting from holder trying to acquire
+// current at now: it's granted if current is free, expired or already held
+// by holder. Otherwise current is returned unchanged. LeaseBackends use it
+// to implement Acquire.
+func AcquireLease(current Lease, holder string, now, expires time.Time) Lease {
+	if current.Holder == "" || current.Holder == holder || !now.Before(current.Expires) {
+		return Lease{Holder: holder, Expires: expires}
+	}
+	return current
+}
@@ -0,0 +1,54 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestLeadership_SingleLeader(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	now := time.Unix(1000000, 0)
+	clock := func() time.Time { return now }
+	backend := &MemoryLeaseBackend{}
+	left := NewLeadership(backend, "http://left", time.Minute)
+	right := NewLeadership(backend, "http://right", time.Minute)
+	left.now, right.now = clock, clock
+
+	leading, err := left.Campaign()
+	require.NoError(err)
+	assert.True(leading)
+
+	leading, err = right.Campaign()
+	require.NoError(err)
+	assert.False(leading)
+	assert.Equal("http://left", right.Leader().Holder)
+
+	// Renewal keeps the lease with its holder.
+	now = now.Add(50 * time.Second)
+	leading, err = left.Campaign()
+	require.NoError(err)
+	assert.True(leading)
+
+	// If the leader stops renewing, another server takes over.
+	now = now.Add(2 * time.Minute)
+	assert.False(left.IsLeader())
+	leading, err = right.Campaign()
+	require.NoError(err)
+	assert.True(leading)
+
+	require.NoError(right.Resign())
+	leading, err = left.Campaign()
+	require.NoError(err)
+	assert.True(leading)
+}
+
+func TestLeadership_NilIsLeader(t *testing.T) {
+	var l *Leadership
+	assert.True(t, l.IsLeader())
+	assert.Equal(t, Lease{}, l.Leader())
+}
@@ -29,6 +29,10 @@ type (
 		Owners []string
 		// Kind is the kind of software that SourceRepo represents.
 		Kind ManifestKind `validate:"nonzero"`
+		// Defaults is the DeployConfig shared by all the deployments of this
+		// manifest. Each DeploySpec in Deployments overrides it field by
+		// field, and key by key for Resources, Env and Metadata.
+		Defaults DeployConfig `yaml:",omitempty"`
 		// Deployments is a map of cluster names to DeploymentSpecs
 		Deployments DeploySpecs `validate:"keys=nonempty,values=nonzero"`
 	}
@@ -55,6 +59,7 @@ func (m Manifest) Clone() (c *Manifest) {
 		deployments[k] = v.Clone()
 	}
 	m.Owners = owners
+	m.Defaults = m.Defaults.Clone()
 	m.Deployments = deployments
 	return &m
 }
@@ -64,8 +69,64 @@ func (m *Manifest) FileLocation() string {
 	return filepath.Join(string(m.Source.Repo), string(m.Source.Dir))
 }
 
+// ExpandedDeployments returns the DeploySpec of each of m's deployments with
+// m's Defaults applied.
+func (m *Manifest) ExpandedDeployments() DeploySpecs {
+	specs := make(DeploySpecs, len(m.Deployments))
+	for clusterName, spec := range m.Deployments {
+		specs[clusterName] = m.expand(spec)
+	}
+	return specs
+}
+
+func (m *Manifest) expand(spec DeploySpec) DeploySpec {
+	return flattenDeploySpecs([]DeploySpec{spec, {DeployConfig: m.Defaults}})
+}
+
+// KeepDefaults gives m, if it has no Defaults of its own, like a manifest
+// made from deployments, the Defaults of stored, the manifest m replaces, and
+// removes from m's deployments the config they inherit from them. Defaults
+// that some of m's deployments lack are dropped, and those its deployments set
+// differently are overridden.
+func (m *Manifest) KeepDefaults(stored *Manifest) {
+	if !m.Defaults.isZero() {
+		return
+	}
+	m.factorDefaults(stored.Defaults)
+}
+
+// SetExpandedDeployment sets m's deployment to cluster to spec, which is
+// complete, like one made from a deployment, rather than relative to m's
+// Defaults. Defaults that spec lacks are moved into m's other deployments.
+func (m *Manifest) SetExpandedDeployment(cluster string, spec DeploySpec) {
+	specs := m.ExpandedDeployments()
+	specs[cluster] = spec
+	m.Deployments = specs
+	m.factorDefaults(m.Defaults)
+}
+
+// factorDefaults makes as much of defaults as all of m's deployments share
+// m's Defaults, and removes from each deployment the config it inherits from
+// them. m's deployments must be complete, as from ExpandedDeployments.
+func (m *Manifest) factorDefaults(defaults DeployConfig) {
+	var dcs []DeployConfig
+	for _, spec := range m.Deployments {
+		dcs = append(dcs, spec.DeployConfig)
+	}
+	m.Defaults = defaults.sharedBy(dcs)
+	if m.Defaults.isZero() {
+		m.Defaults = DeployConfig{}
+		return
+	}
+	for clusterName, spec := range m.Deployments {
+		spec.DeployConfig = spec.DeployConfig.without(m.Defaults)
+		m.Deployments[clusterName] = spec
+	}
+}
+
 // Diff returns true and a list of differences if m and o are not equal.
-// Otherwise returns false and nil.
+// Otherwise returns false and nil. Deployments are compared with Defaults
+// applied, so moving config into or out of Defaults makes no difference.
 func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 	if m == o {
 		// They are the same pointer.
@@ -87,23 +148,24 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 		_, ods := here.Diff(there)
 		diffs = append(diffs, ods...)
 	}
-	if len(m.Deployments) != len(o.Deployments) {
-		diff("number of deployments; this: %d; other: %d", len(m.Deployments), len(o.Deployments))
+	mds, ods := m.ExpandedDeployments(), o.ExpandedDeployments()
+	if len(mds) != len(ods) {
+		diff("number of deployments; this: %d; other: %d", len(mds), len(ods))
 	} else {
-		for clusterName, deploySpec := range m.Deployments {
+		for clusterName, deploySpec := range mds {
 			// Check for missing deployment in o.
-			if _, ok := o.Deployments[clusterName]; !ok {
+			if _, ok := ods[clusterName]; !ok {
 				diff("missing deployment %q", clusterName)
 				continue
 			}
-			_, differences := deploySpec.Diff(o.Deployments[clusterName])
+			_, differences := deploySpec.Diff(ods[clusterName])
 			for _, deploySpecDiff := range differences {
 				diff("%s: "+deploySpecDiff, clusterName)
 			}
 		}
 		// Check for extra deployments in o.
-		for clusterName := range o.Deployments {
-			if _, ok := m.Deployments[clusterName]; !ok {
+		for clusterName := range ods {
+			if _, ok := mds[clusterName]; !ok {
 				diff("extra deployment %q", clusterName)
 			}
 		}
@@ -129,10 +191,19 @@ func (m *Manifest) Validate() []Flaw {
 		flaws = append(flaws, m.Kind.Validate()...)
 	}
 
-	for cluster, d := range m.Deployments {
-		df := d.Validate()
-		for _, f := range df {
+	for cluster := range m.Deployments {
+		cluster := cluster
+		// The deployment is validated with m's Defaults applied, and written
+		// back once repaired, less the config it inherits from them.
+		d := m.expand(m.Deployments[cluster])
+		df := d.DeployConfig.Validate()
+		for i, f := range df {
 			f.AddContext("cluster", cluster)
+			df[i] = &deploySpecFlaw{Flaw: f, repaired: func() {
+				spec := m.Deployments[cluster]
+				spec.DeployConfig = d.DeployConfig.without(m.Defaults)
+				m.Deployments[cluster] = spec
+			}}
 		}
 		flaws = append(flaws, df...)
 	}
@@ -148,3 +219,24 @@ func (m *Manifest) Validate() []Flaw {
 func (m *Manifest) Repair(fs []Flaw) error {
 	return errors.Errorf("Can't do nuffin with flaws yet")
 }
+
+// deploySpecFlaw is a flaw in one of a manifest's deployments, found with the
+// manifest's Defaults applied to it.
+type deploySpecFlaw struct {
+	Flaw
+	// repaired writes the repaired deployment back to the manifest.
+	repaired func()
+}
+
+// Repair implements Flaw.Repair.
+func (f *deploySpecFlaw) Repair() error {
+	if err := f.Flaw.Repair(); err != nil {
+		return err
+	}
+	f.repaired()
+	return nil
+}
+
+func (f *deploySpecFlaw) String() string {
+	return fmt.Sprint(f.Flaw)
+}
@@ -2,9 +2,11 @@ package sous
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/samsalisbury/semv"
+	"github.com/samsalisbury/yaml"
 )
 
 var manifestTests = []struct {
@@ -154,3 +156,127 @@ func TestManifest_Diff(t *testing.T) {
 		t.Errorf("diffs[1] == %q; want %q", actual, expected)
 	}
 }
+
+func TestManifest_Defaults(t *testing.T) {
+	state := makeTestState()
+	mid := ManifestID{Source: project1}
+	m, _ := state.Manifests.Get(mid)
+	m.Defaults = DeployConfig{
+		Resources:    Resources{"mem": "1024"},
+		Env:          Env{"ALL": "IS ONE"},
+		Metadata:     Metadata{"everybody": "wants to be a cat"},
+		NumInstances: 2,
+	}
+	for cluster, spec := range m.Deployments {
+		delete(spec.Env, "ALL")
+		delete(spec.Metadata, "everybody")
+		if cluster == "cluster-1" {
+			delete(spec.Resources, "mem")
+			spec.NumInstances = 0
+		}
+		m.Deployments[cluster] = spec
+	}
+
+	yml, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := &Manifest{}
+	if err := yaml.Unmarshal(yml, read); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Defaults.Env, m.Defaults.Env) || read.Defaults.NumInstances != 2 {
+		t.Errorf("Defaults not preserved in YAML:\n%s", yml)
+	}
+	if spec := read.Deployments["cluster-1"]; len(spec.Env) != 1 || spec.NumInstances != 0 {
+		t.Errorf("DeploySpecs expanded in YAML:\n%s", yml)
+	}
+
+	// Each cluster's spec overrides the defaults, so the deployments are as
+	// if they had been written out in full.
+	ds, err := state.Deployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDeployments(t, expectedDeployments, ds)
+
+	ms, err := ds.Manifests(state.Defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.KeepDefaults(state.Manifests)
+	bounced, _ := ms.Get(mid)
+	if different, diffs := m.Diff(bounced); different {
+		t.Errorf("manifest changed by conversion to and from deployments: %v", diffs)
+	}
+	if !reflect.DeepEqual(bounced.Defaults, m.Defaults) {
+		t.Errorf("Defaults not kept:\n got %+v\nwant %+v", bounced.Defaults, m.Defaults)
+	}
+	overrides := bounced.Deployments["cluster-2"]
+	if overrides.Resources["mem"] != "2048" || overrides.NumInstances != 3 {
+		t.Errorf("cluster-2 lost its overrides of the defaults: %+v", overrides.DeployConfig)
+	}
+	if _, ok := overrides.Env["ALL"]; ok {
+		t.Errorf("cluster-2 repeats the default env: %+v", overrides.Env)
+	}
+
+	// The manifest without Defaults doesn't gain any, even though its
+	// deployments share config.
+	flavored := ManifestID{Source: project1, Flavor: "some-flavor"}
+	if m, _ := ms.Get(flavored); !m.Defaults.isZero() {
+		t.Errorf("manifest without Defaults gained some: %+v", m.Defaults)
+	}
+}
+
+func TestManifest_KeepDefaults_Partial(t *testing.T) {
+	stored := &Manifest{
+		Defaults: DeployConfig{
+			Resources:    Resources{"cpus": "1", "ports": "1"},
+			NumInstances: 2,
+		},
+	}
+	m := &Manifest{Deployments: DeploySpecs{
+		"a": {DeployConfig: DeployConfig{Resources: Resources{"cpus": "1", "ports": "1"}, NumInstances: 2}},
+		"b": {DeployConfig: DeployConfig{Resources: Resources{"cpus": "4"}, NumInstances: 2}},
+	}}
+	expanded := m.ExpandedDeployments()
+
+	m.KeepDefaults(stored)
+	want := DeployConfig{Resources: Resources{"cpus": "1"}, Env: Env{}, Metadata: Metadata{}, NumInstances: 2}
+	if !reflect.DeepEqual(m.Defaults, want) {
+		t.Errorf("Defaults:\n got %+v\nwant %+v", m.Defaults, want)
+	}
+	if rs := m.Deployments["a"].Resources; !reflect.DeepEqual(rs, Resources{"ports": "1"}) {
+		t.Errorf("a's resources: %v, want only ports, which b lacks", rs)
+	}
+	if rs := m.Deployments["b"].Resources; !reflect.DeepEqual(rs, Resources{"cpus": "4"}) {
+		t.Errorf("b's resources: %v, want its override of cpus", rs)
+	}
+	if !reflect.DeepEqual(m.ExpandedDeployments(), expanded) {
+		t.Errorf("deployments changed:\n got %+v\nwant %+v", m.ExpandedDeployments(), expanded)
+	}
+}
+
+func TestManifest_Validate_RepairsWithDefaults(t *testing.T) {
+	m := &Manifest{
+		Kind:     ManifestKindService,
+		Defaults: DeployConfig{Resources: Resources{"cpus": "1", "memory": "100"}},
+		Deployments: DeploySpecs{
+			"a": {DeployConfig: DeployConfig{Resources: Resources{"memory": "200"}, NumInstances: 1}},
+		},
+	}
+	flaws := m.Validate()
+	if len(flaws) != 1 {
+		t.Fatalf("got flaws %v, want only the missing ports", flaws)
+	}
+	if _, errs := RepairAll(flaws); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	want := Resources{"memory": "200", "ports": "1"}
+	if rs := m.Deployments["a"].Resources; !reflect.DeepEqual(rs, want) {
+		t.Errorf("repaired resources: %v, want %v", rs, want)
+	}
+	if flaws := m.Validate(); len(flaws) != 0 {
+		t.Errorf("flaws left after repair: %v", flaws)
+	}
+}
@@ -70,3 +70,15 @@ func (ms Manifests) Flavors() []string {
 	}
 	return fs
 }
+
+// KeepDefaults gives each of ms the Defaults of the manifest with the same ID
+// in stored, as Manifest.KeepDefaults does. ms are made from deployments, and
+// so have no Defaults of their own.
+func (ms Manifests) KeepDefaults(stored Manifests) {
+	for id, m := range ms.Snapshot() {
+		if s, ok := stored.Get(id); ok {
+			m.KeepDefaults(s)
+			ms.Set(id, m)
+		}
+	}
+}
@@ -67,6 +67,7 @@ func (ds Deployments) Manifests(defs Defs) (Manifests, error) {
 		spec := DeploySpec{
 			Version:      d.SourceID.Version,
 			DeployConfig: d.DeployConfig.Clone(),
+			Paused:       d.Paused,
 		}
 		for k, v := range spec.DeployConfig.Env {
 			clusterVal, ok := d.Cluster.Env[k]
@@ -82,15 +83,6 @@ func (ds Deployments) Manifests(defs Defs) (Manifests, error) {
 
 		ms.Set(mid, m)
 	}
-
-	for _, k := range ms.Keys() {
-		m, there := ms.Get(k)
-		if !there {
-			continue
-		}
-		ms.Set(k, m)
-	}
-
 	return ms, nil
 }
 
@@ -99,7 +91,7 @@ func (ds Deployments) Manifests(defs Defs) (Manifests, error) {
 // and configuration).
 func (s *State) DeploymentsFromManifest(m *Manifest) (Deployments, error) {
 	ds := NewDeployments()
-	var inherit []DeploySpec
+	inherit := []DeploySpec{{DeployConfig: m.Defaults}}
 
 	for clusterName, spec := range m.Deployments {
 		cluster, ok := s.Defs.Clusters[clusterName]
@@ -128,6 +120,7 @@ func BuildDeployment(s *State, m *Manifest, nick string, spec DeploySpec, inheri
 		Owners:       ownMap,
 		Kind:         m.Kind,
 		SourceID:     m.Source.SourceID(ds.Version),
+		Paused:       ds.Paused,
 	}, nil
 }
 
@@ -137,6 +130,9 @@ func flattenDeploySpecs(dss []DeploySpec) DeploySpec {
 		dcs = append(dcs, s.DeployConfig)
 	}
 	ds := DeploySpec{DeployConfig: flattenDeployConfigs(dcs)}
+	for _, s := range dss {
+		ds.Paused = ds.Paused || s.Paused
+	}
 	var zeroVersion semv.Version
 	for _, s := range dss {
 		if s.Version != zeroVersion {
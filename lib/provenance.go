@@ -0,0 +1,75 @@
+package sous
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Provenance records how, where and by whom an artifact was built, for
+// audits.
+type Provenance struct {
+	// User and Host are the login name of the user who ran the build, and the
+	// host it ran on.
+	User, Host string
+	// Buildpack names the buildpack used, and Detected is its description of
+	// what it built, from its DetectResult.
+	Buildpack, Detected string
+	// BaseImage is the image the artifact was built from, by digest where
+	// that could be determined.
+	BaseImage string
+	// DockerfileHash is the SHA-256 of the Dockerfile built, if any.
+	DockerfileHash string
+	// RemoteURL and Revision identify the source code built: the git remote
+	// it was pushed to and its full revision.
+	RemoteURL, Revision string
+	// Built is when the build finished.
+	Built time.Time
+}
+
+// completeProvenance fills in the parts of the provenance of br which don't
+// depend on the buildpack used.
+func completeProvenance(br *BuildResult, bc *BuildContext, bp Buildpack, dr *DetectResult) {
+	if br.Provenance == nil {
+		br.Provenance = &Provenance{}
+	}
+	p := br.Provenance
+	if bc.User.Username != "" {
+		p.User = bc.User.Username
+	}
+	p.Host = bc.Machine.FullHost
+	if p.Buildpack == "" {
+		p.Buildpack = fmt.Sprintf("%T", bp)
+	}
+	if dr != nil {
+		p.Detected = dr.Description
+	}
+	p.RemoteURL = bc.Source.RemoteURL
+	p.Revision = bc.Source.Revision
+	p.Built = time.Now().UTC()
+}
+
+// AsTable writes the provenance as a two column table.
+func (p *Provenance) AsTable(to io.Writer) error {
+	w := &tabwriter.Writer{}
+	w.Init(to, 2, 4, 2, ' ', 0)
+	built := ""
+	if !p.Built.IsZero() {
+		built = p.Built.Format(time.RFC3339)
+	}
+	for _, row := range [][2]string{
+		{"User", p.User},
+		{"Host", p.Host},
+		{"Buildpack", p.Buildpack},
+		{"Detected", p.Detected},
+		{"Base image", p.BaseImage},
+		{"Dockerfile hash", p.DockerfileHash},
+		{"Remote URL", p.RemoteURL},
+		{"Revision", p.Revision},
+		{"Built", built},
+	} {
+		fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+	}
+	return w.Flush()
+}
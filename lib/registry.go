@@ -28,10 +28,28 @@ type (
 		Warmup(string) error
 	}
 
+	// A Replicator copies artifacts between registries.
+	Replicator interface {
+		// Replicate ensures that the artifact for sid is held by the named
+		// registry, copying it there if need be, and returns the artifact
+		// as named in that registry.
+		Replicate(sid SourceID, art *BuildArtifact, registry string) (*BuildArtifact, error)
+	}
+
 	// An Inserter puts data into a registry.
 	Inserter interface {
 		// Insert pairs a SourceID with an imagename, and tags the pairing with Qualities
 		// The etag can be (usually will be) the empty string
+		// If the imagename is empty, the Qualities are attached to the artifact
+		// already recorded for the SourceID, e.g. to assert that it has
+		// passed its integration tests.
 		Insert(sid SourceID, in, etag string, qs []Quality) error
 	}
+
+	// An ArtifactInserter records artifacts of any type, not just Docker
+	// images.
+	ArtifactInserter interface {
+		// InsertArtifact records art as the artifact for sid.
+		InsertArtifact(sid SourceID, art *BuildArtifact) error
+	}
 )
@@ -3,8 +3,10 @@ package sous
 import (
 	"fmt"
 	"io"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/pkg/errors"
 	"github.com/samsalisbury/semv"
 )
 
@@ -12,12 +14,23 @@ type (
 	// RegistryDumper dumps the contents of artifact registries
 	RegistryDumper struct {
 		Registry
+		// Server, if set, is asked for the artifacts instead of Registry, so
+		// that the dump matches what the server's resolver sees.
+		Server HTTPClient
+		User   User
 	}
 
 	// DumperEntry is a single entry from the dump
 	DumperEntry struct {
 		SourceID
 		*BuildArtifact
+		// Digest is the content digest of the image, if the artifact is
+		// pinned to one.
+		Digest string `json:",omitempty"`
+	}
+
+	artifactsWrapper struct {
+		Artifacts []DumperEntry
 	}
 )
 
@@ -26,51 +39,91 @@ func NewRegistryDumper(r Registry) *RegistryDumper {
 	return &RegistryDumper{Registry: r}
 }
 
+// NewHTTPRegistryDumper constructs a RegistryDumper which lists the artifacts
+// known to the Sous server cl talks to.
+func NewHTTPRegistryDumper(cl HTTPClient, user User) *RegistryDumper {
+	return &RegistryDumper{Server: cl, User: user}
+}
+
+// NewDumperEntry describes art, the artifact for sid.
+func NewDumperEntry(sid SourceID, art *BuildArtifact) DumperEntry {
+	de := DumperEntry{SourceID: sid, BuildArtifact: art}
+	if parts := strings.SplitN(art.Name, "@", 2); len(parts) == 2 {
+		de.Digest = parts[1]
+	}
+	return de
+}
+
+// ListArtifacts returns an entry for each SourceID known to r which passes
+// filter. A nil filter passes every SourceID.
+func ListArtifacts(r Registry, filter func(SourceID) bool) ([]DumperEntry, error) {
+	ss, err := r.ListSourceIDs()
+	Log.Vomit.Printf("%#v", ss)
+	if err != nil {
+		return nil, err
+	}
+
+	de := []DumperEntry{}
+	for _, s := range ss {
+		if filter != nil && !filter(s) {
+			continue
+		}
+		a, err := r.GetArtifact(s)
+		if err != nil {
+			return nil, err
+		}
+		Log.Vomit.Printf("%#v", s)
+		Log.Vomit.Printf("%#v", a)
+
+		de = append(de, NewDumperEntry(s, a))
+	}
+	return de, nil
+}
+
 // AsTable writes a tabular dump of the registry to a Writer
 func (rd *RegistryDumper) AsTable(to io.Writer) error {
-	w := &tabwriter.Writer{}
-	w.Init(to, 2, 4, 2, ' ', 0)
-	fmt.Fprintln(w, rd.TabbedHeaders())
-
 	es, err := rd.Entries()
 	if err != nil {
 		return err
 	}
+	return DumpArtifacts(to, es)
+}
+
+// DumpArtifacts writes es to writer as a table.
+func DumpArtifacts(writer io.Writer, es []DumperEntry) error {
+	w := &tabwriter.Writer{}
+	w.Init(writer, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, dumperHeaders)
 	for _, e := range es {
 		fmt.Fprintln(w, e.Tabbed())
 	}
-	w.Flush()
-	return nil
+	return w.Flush()
 }
 
+const dumperHeaders = "Repo\tOffset\tVersion\tName\tType\tQualities"
+
 // TabbedHeaders outputs the headers for the dump
 func (rd *RegistryDumper) TabbedHeaders() string {
-	return "Repo\tOffset\tVersion\tName\tType"
+	return dumperHeaders
 }
 
 // Entries emits the list of entries for the Resgistry
-func (rd *RegistryDumper) Entries() (de []DumperEntry, err error) {
-	ss, err := rd.Registry.ListSourceIDs()
-	Log.Vomit.Printf("%#v", ss)
-	if err != nil {
-		return
+func (rd *RegistryDumper) Entries() ([]DumperEntry, error) {
+	if rd.Server == nil {
+		return ListArtifacts(rd.Registry, nil)
 	}
-
-	for _, s := range ss {
-		a, err := rd.Registry.GetArtifact(s)
-		if err != nil {
-			return nil, err
-		}
-		Log.Vomit.Printf("%#v", s)
-		Log.Vomit.Printf("%#v", a)
-
-		de = append(de, DumperEntry{SourceID: s, BuildArtifact: a})
+	aw := artifactsWrapper{}
+	if err := rd.Server.Retrieve("./artifact", nil, &aw, rd.User); err != nil {
+		return nil, errors.Wrap(err, "listing artifacts")
 	}
-
-	return
+	return aw.Artifacts, nil
 }
 
 // Tabbed emits a tab-delimited string representing the entry
 func (de *DumperEntry) Tabbed() string {
-	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s", de.Location.Repo, de.Location.Dir, de.Version.Format(semv.MajorMinorPatch), de.Name, de.Type)
+	qs := []string{}
+	for _, q := range de.Qualities {
+		qs = append(qs, q.Kind+":"+q.Name)
+	}
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s", de.Location.Repo, de.Location.Dir, de.Version.Format(semv.MajorMinorPatch), de.Name, de.Type, strings.Join(qs, ","))
 }
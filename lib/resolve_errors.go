@@ -33,6 +33,18 @@ type (
 		Cause error
 	}
 
+	// A TagMovedError reports that the image tag for a SourceID now refers to
+	// a different image than when Sous first recorded it, i.e. that the tag
+	// has been pushed again.
+	TagMovedError struct {
+		SourceID SourceID
+		// Image is the name of the image as first recorded.
+		Image string
+		// Recorded is the digest first recorded for the image, and Current
+		// is the digest the tag now refers to.
+		Recorded, Current string
+	}
+
 	// A FailedStatusError reports that the the deploy has reported as failed on
 	// singularity
 	FailedStatusError struct{} // XXX maybe handy to have the root Singularity non-SUCCEEDED status?
@@ -44,6 +56,30 @@ type (
 		*SourceID
 	}
 
+	// A ReplicationError reports that an image couldn't be copied into the
+	// registry of the cluster it's to be deployed to.
+	ReplicationError struct {
+		SourceID SourceID
+		Registry string
+		Err      error
+	}
+
+	// A MissingQualitiesError reports that an image lacks assertions which
+	// the target cluster requires.
+	MissingQualitiesError struct {
+		SourceID SourceID
+		Cluster  string
+		Missing  []string
+	}
+
+	// An UnacceptableArtifactTypeError reports that the artifact for a
+	// deployment is of a type its cluster can't deploy.
+	UnacceptableArtifactTypeError struct {
+		SourceID SourceID
+		Cluster  string
+		Type     string
+	}
+
 	// CreateError is returned when there's an error trying to create a deployment
 	CreateError struct {
 		Deployment *Deployment
@@ -85,6 +121,15 @@ func (ew ErrorWrapper) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ew.MarshallableError)
 }
 
+// message returns the message of the error ew wraps, or, if ew was
+// unmarshalled, the message it was marshalled with.
+func (ew ErrorWrapper) message() string {
+	if ew.error != nil {
+		return ew.error.Error()
+	}
+	return ew.String
+}
+
 func buildMarshableError(err error) MarshallableError {
 	ew := MarshallableError{}
 	ew.Type = fmt.Sprintf("%T", err)
@@ -130,6 +175,24 @@ func IsTransientResolveError(err error) bool {
 		// intervention: either the image needs to be rebuilt clean, or the cluster
 		// reconfigured to accept the advisory.
 		return false
+	case *ReplicationError:
+		// ReplicationErrors are usually the result of the registries being
+		// unavailable, so the copy is tried again on the next resolution.
+		return true
+	case *MissingQualitiesError:
+		// MissingQualitiesError is excluded, since the missing assertions need
+		// to be attached to the image, e.g. by a CI job, or the cluster
+		// reconfigured not to require them.
+		return false
+	case *UnacceptableArtifactTypeError:
+		// UnacceptableArtifactTypeError is excluded, since the artifact needs
+		// to be rebuilt or pushed as a type the cluster accepts, or the
+		// cluster reconfigured to accept it.
+		return false
+	case *TagMovedError:
+		// TagMovedError requires that the image be rebuilt with a new version,
+		// or the tag be restored to the image first recorded.
+		return false
 	case *MissingImageNameError:
 		// MissingImageNameError isn't transient: it requires that an appropriate
 		// image be built with the desired name and the server needs to be able to
@@ -157,10 +220,28 @@ func (e *MissingImageNameError) Error() string {
 	return fmt.Sprintf("Image name unknown to Sous for source IDs: %s", e.Cause.Error())
 }
 
+func (e *TagMovedError) Error() string {
+	return fmt.Sprintf("Image for %v has been pushed again: %s was %s, now %s",
+		e.SourceID, e.Image, e.Recorded, e.Current)
+}
+
 func (e *UnacceptableAdvisory) Error() string {
 	return fmt.Sprintf("Advisory unacceptable on image: %s for %v", e.Quality.Name, e.SourceID)
 }
 
+func (e *ReplicationError) Error() string {
+	return fmt.Sprintf("Couldn't copy image for %v into %s: %v", e.SourceID, e.Registry, e.Err)
+}
+
+func (e *MissingQualitiesError) Error() string {
+	return fmt.Sprintf("Image for %v lacks qualities required by cluster %s: %s",
+		e.SourceID, e.Cluster, strings.Join(e.Missing, ", "))
+}
+
+func (e *UnacceptableArtifactTypeError) Error() string {
+	return fmt.Sprintf("Artifact for %v is a %s, which cluster %s can't deploy", e.SourceID, e.Type, e.Cluster)
+}
+
 func (e *FailedStatusError) Error() string {
 	return "Deploy failed on Singularity."
 }
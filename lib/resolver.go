@@ -9,6 +9,11 @@ type (
 		Deployer Deployer
 		Registry Registry
 		*ResolveFilter
+		// Throttle paces the deploys started in each cluster.
+		Throttle *DeployThrottle
+		// Autoscaler, if not nil, supplies the effective instance counts of
+		// autoscaled deployments, which replace those intended.
+		Autoscaler *Autoscaler
 	}
 
 	// DeploymentPredicate takes a *Deployment and returns true if the
@@ -23,6 +28,7 @@ func NewResolver(d Deployer, r Registry, rf *ResolveFilter) *Resolver {
 		Deployer:      d,
 		Registry:      r,
 		ResolveFilter: rf,
+		Throttle:      NewDeployThrottle(),
 	}
 }
 
@@ -86,6 +92,10 @@ func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecord
 			actual = actual.Filter(r.FilterDeployStates)
 		})
 
+		recorder.performGuaranteedPhase("applying autoscaling", func() {
+			intended = r.Autoscaler.Apply(intended, actual)
+		})
+
 		var diffs DiffChans
 		recorder.performGuaranteedPhase("generating diff", func() {
 			diffs = actual.Diff(intended)
@@ -110,8 +120,13 @@ func (r *Resolver) Begin(intended Deployments, clusters Clusters) *ResolveRecord
 			namer.ResolveNames(r.Registry, &diffs, errs)
 		})
 
+		var throttled *DeployableChans
+		recorder.performGuaranteedPhase("throttling deployments", func() {
+			throttled = namer.Throttle(r.Throttle, InFlight(actual), recorder.Log)
+		})
+
 		recorder.performGuaranteedPhase("rectification", func() {
-			r.rectify(namer, recorder.Log)
+			r.rectify(throttled, recorder.Log)
 		})
 		wg.Wait()
 	})
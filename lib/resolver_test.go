@@ -20,6 +20,22 @@ func TestGuardImageMissing(t *testing.T) {
 	_, err := GuardImage(dr, &missing)
 	assert.Error(err)
 }
+
+func TestGuardImageTagMoved(t *testing.T) {
+	assert := assert.New(t)
+
+	svOne := MustParseSourceID(`github.com/ot/one,1.3.5`)
+	dr := NewDummyRegistry()
+	config := DeployConfig{NumInstances: 1}
+	clusterX := &Cluster{Name: "x"}
+	moved := Deployment{ClusterName: `x`, SourceID: svOne, DeployConfig: config, Cluster: clusterX}
+
+	dr.FeedArtifact(nil, &TagMovedError{SourceID: svOne, Image: "ot/one:1.3.5", Recorded: "sha256:a", Current: "sha256:b"})
+	_, err := GuardImage(dr, &moved)
+	assert.IsType(&TagMovedError{}, err)
+	assert.False(IsTransientResolveError(err))
+}
+
 func TestGuardImageRejected(t *testing.T) {
 	assert := assert.New(t)
 
@@ -64,3 +80,102 @@ func TestAllowsWhitelistedAdvisories(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(art)
 }
+
+func TestGuardImageRequiredQualities(t *testing.T) {
+	assert := assert.New(t)
+
+	svOne := MustParseSourceID(`github.com/ot/one,1.3.5`)
+	dr := NewDummyRegistry()
+	config := DeployConfig{NumInstances: 1}
+	prod := &Cluster{Name: "prod", RequiredQualities: []string{"integration-tested", "vuln-scanned"}}
+	intoProd := Deployment{ClusterName: `prod`, Cluster: prod, SourceID: svOne, DeployConfig: config}
+
+	dr.FeedArtifact(&BuildArtifact{"ot-docker/one", "docker", []Quality{{"integration-tested", "assertion"}}}, nil)
+	_, err := GuardImage(dr, &intoProd)
+	if assert.IsType(&MissingQualitiesError{}, err) {
+		assert.Equal([]string{"vuln-scanned"}, err.(*MissingQualitiesError).Missing)
+	}
+	assert.False(IsTransientResolveError(err))
+
+	dr.FeedArtifact(&BuildArtifact{"ot-docker/one", "docker", []Quality{{"integration-tested", "assertion"}, {"vuln-scanned", "assertion"}}}, nil)
+	art, err := GuardImage(dr, &intoProd)
+	assert.NoError(err)
+	assert.NotNil(art)
+}
+
+type replicatingRegistry struct {
+	*DummyRegistry
+	err error
+	// replicated lists the SourceIDs of the artifacts replicated.
+	replicated []SourceID
+}
+
+func (rr *replicatingRegistry) Replicate(sid SourceID, art *BuildArtifact, registry string) (*BuildArtifact, error) {
+	if rr.err != nil {
+		return nil, rr.err
+	}
+	rr.replicated = append(rr.replicated, sid)
+	return &BuildArtifact{Name: registry + "/ot/one", Type: art.Type, Qualities: art.Qualities}, nil
+}
+
+func TestResolveDeployedNameReplicates(t *testing.T) {
+	assert := assert.New(t)
+
+	svOne := MustParseSourceID(`github.com/ot/one,1.3.5`)
+	rr := &replicatingRegistry{DummyRegistry: NewDummyRegistry()}
+	config := DeployConfig{NumInstances: 1}
+	remote := &Cluster{Name: "remote", DockerRepo: "mirror.example.com"}
+	dep := &Deployment{ClusterName: `remote`, Cluster: remote, SourceID: svOne, DeployConfig: config}
+
+	rr.FeedArtifact(&BuildArtifact{"docker.example.com/ot/one", "docker", nil}, nil)
+	d, err := resolveDeployedName(rr, dep, DeployStatusActive)
+	assert.NoError(err)
+	assert.Equal("mirror.example.com/ot/one", d.BuildArtifact.Name)
+
+	rr.FeedArtifact(&BuildArtifact{"docker.example.com/ot/one", "docker", nil}, nil)
+	rr.err = fmt.Errorf("mirror unavailable")
+	_, err = resolveDeployedName(rr, dep, DeployStatusActive)
+	assert.IsType(&ReplicationError{}, err)
+	assert.True(IsTransientResolveError(err))
+
+	rr.FeedArtifact(&BuildArtifact{"docker.example.com/ot/one", "docker", nil}, nil)
+	d, err = resolveDeployedName(rr, &Deployment{ClusterName: `x`, Cluster: &Cluster{Name: "x"}, SourceID: svOne, DeployConfig: config}, DeployStatusActive)
+	assert.NoError(err)
+	assert.Equal("docker.example.com/ot/one", d.BuildArtifact.Name, "clusters without their own registry should not replicate")
+}
+
+func TestResolvePairReplicatesPost(t *testing.T) {
+	assert := assert.New(t)
+
+	rr := &replicatingRegistry{DummyRegistry: NewDummyRegistry()}
+	config := DeployConfig{NumInstances: 1}
+	remote := &Cluster{Name: "remote", DockerRepo: "mirror.example.com"}
+	prior := &Deployment{ClusterName: `remote`, Cluster: remote, SourceID: MustParseSourceID(`github.com/ot/one,1.3.5`), DeployConfig: config}
+	post := &Deployment{ClusterName: `remote`, Cluster: remote, SourceID: MustParseSourceID(`github.com/ot/one,1.4.0`), DeployConfig: config}
+
+	dp, err := resolvePair(rr, &DeploymentPair{name: post.ID(), Prior: prior, Post: post, Status: DeployStatusActive})
+	assert.NoError(err)
+	assert.Equal([]SourceID{post.SourceID}, rr.replicated, "only the artifact to be deployed should be replicated")
+	assert.Equal("mirror.example.com/ot/one", dp.Post.BuildArtifact.Name)
+}
+
+func TestGuardImageArtifactTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	svOne := MustParseSourceID(`github.com/ot/one,1.3.5`)
+	config := DeployConfig{NumInstances: 1}
+	layout := &BuildArtifact{"/builds/one", ArtifactTypeOCILayout, nil}
+	intoProd := Deployment{ClusterName: `prod`, Cluster: &Cluster{Name: "prod"}, SourceID: svOne, DeployConfig: config}
+
+	dr := NewDummyRegistry()
+	dr.FeedArtifact(layout, nil)
+	_, err := GuardImage(dr, &intoProd)
+	assert.IsType(&UnacceptableArtifactTypeError{}, err, "clusters should reject types they don't accept")
+	assert.False(IsTransientResolveError(err))
+
+	intoLayouts := Deployment{ClusterName: `layouts`, Cluster: &Cluster{Name: "layouts", ArtifactTypes: []string{ArtifactTypeOCILayout}}, SourceID: svOne, DeployConfig: config}
+	dr.FeedArtifact(layout, nil)
+	art, err := GuardImage(dr, &intoLayouts)
+	assert.NoError(err)
+	assert.Equal(layout, art, "clusters accepting the type should get the artifact")
+}
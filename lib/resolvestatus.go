@@ -13,6 +13,9 @@ type (
 		Log []DiffResolution
 		// Errs collects errors during resolution
 		Errs ResolveErrors
+		// Queued lists the deployments held back by cluster deploy limits,
+		// which will be attempted again on the next resolution.
+		Queued []DeployID `json:",omitempty"`
 	}
 
 	// ResolveRecorder represents the status of a resolve run.
@@ -53,6 +56,9 @@ const (
 	ModifyDiff = ResolutionType("updated")
 	// DeleteDiff - a deployment was active that wasn't intended at all, and was deleted.
 	DeleteDiff = ResolutionType("deleted")
+	// QueuedDiff - the intended deployment differs from the active one, but
+	// starting it now would exceed its cluster's deploy limits.
+	QueuedDiff = ResolutionType("queued")
 )
 
 // NewResolveRecorder creates a new ResolveRecorder and calls f with it as its
@@ -71,6 +77,9 @@ func NewResolveRecorder(f func(*ResolveRecorder)) *ResolveRecorder {
 		for rez := range rr.Log {
 			rr.write(func() {
 				rr.status.Log = append(rr.status.Log, rez)
+				if rez.Desc == QueuedDiff {
+					rr.status.Queued = append(rr.status.Queued, rez.DeployID)
+				}
 				if rez.Error != nil {
 					rr.status.Errs.Causes = append(rr.status.Errs.Causes, ErrorWrapper{error: rez.Error})
 					Log.Debug.Printf("resolve error = %+v\n", rez.Error)
@@ -108,6 +117,10 @@ func (rr *ResolveRecorder) CurrentStatus() (rs ResolveStatus) {
 		copy(rs.Log, rr.status.Log)
 		rs.Errs.Causes = make([]ErrorWrapper, len(rr.status.Errs.Causes))
 		copy(rs.Errs.Causes, rr.status.Errs.Causes)
+		if rr.status.Queued != nil {
+			rs.Queued = make([]DeployID, len(rr.status.Queued))
+			copy(rs.Queued, rr.status.Queued)
+		}
 	})
 	return
 }
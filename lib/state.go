@@ -1,6 +1,7 @@
 package sous
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -71,11 +72,29 @@ type (
 		Kind string
 		// BaseURL is the main entrypoint URL for interacting with this cluster.
 		BaseURL string
+		// DockerRepo is the host:port (no schema) of the Docker registry this
+		// cluster pulls images from, if it isn't Defs.DockerRepo. Images are
+		// copied into it before they're deployed.
+		DockerRepo string `yaml:",omitempty"`
 		// Env is the default environment for all deployments in this region.
 		Env EnvDefaults
 		// AllowedAdvisories lists the artifact advisories which are permissible in
 		// this cluster
 		AllowedAdvisories []string
+		// RequiredQualities lists the assertions an artifact must carry to be
+		// deployed to this cluster, e.g. "integration-tested".
+		RequiredQualities []string `yaml:",omitempty"`
+		// MaxConcurrentDeploys limits the number of deploys which may be in
+		// flight in this cluster at once. Zero means no limit.
+		MaxConcurrentDeploys int `yaml:",omitempty"`
+		// MaxDeploysPerMinute limits the number of deploys Sous starts in this
+		// cluster in any minute. Zero means no limit.
+		MaxDeploysPerMinute int `yaml:",omitempty"`
+		// ArtifactTypes lists the types of artifact this cluster can deploy,
+		// in order of preference. Deployments of artifacts of other types
+		// to it are rejected. If it's empty, the cluster deploys Docker
+		// images.
+		ArtifactTypes []string `yaml:",omitempty"`
 	}
 
 	// EnvDefaults is a list of named environment variables along with their values.
@@ -136,9 +155,42 @@ func (c Cluster) Clone() *Cluster {
 	allowedAdvisories := make([]string, len(c.AllowedAdvisories))
 	copy(allowedAdvisories, c.AllowedAdvisories)
 	c.AllowedAdvisories = allowedAdvisories
+	if c.RequiredQualities != nil {
+		requiredQualities := make([]string, len(c.RequiredQualities))
+		copy(requiredQualities, c.RequiredQualities)
+		c.RequiredQualities = requiredQualities
+	}
+	if c.ArtifactTypes != nil {
+		artifactTypes := make([]string, len(c.ArtifactTypes))
+		copy(artifactTypes, c.ArtifactTypes)
+		c.ArtifactTypes = artifactTypes
+	}
 	return &c
 }
 
+// AcceptedArtifactTypes returns the types of artifact c can deploy, in order
+// of preference. A nil cluster deploys Docker images.
+func (c *Cluster) AcceptedArtifactTypes() []string {
+	if c == nil || len(c.ArtifactTypes) == 0 {
+		return []string{ArtifactTypeDocker}
+	}
+	return c.ArtifactTypes
+}
+
+// AcceptsArtifactType returns true if c can deploy artifacts of type typ.
+// Artifacts with no type are Docker images.
+func (c *Cluster) AcceptsArtifactType(typ string) bool {
+	if typ == "" {
+		typ = ArtifactTypeDocker
+	}
+	for _, t := range c.AcceptedArtifactTypes() {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
 // Clone returns a deep copy of this EnvDefs.
 func (evs EnvDefs) Clone() EnvDefs {
 	e := make(EnvDefs, len(evs))
@@ -153,6 +205,38 @@ func (rdf FieldDefinitions) Clone() FieldDefinitions {
 	return r
 }
 
+// Check returns an error if value isn't a valid value of the field: if it's
+// empty, though the field has no default and isn't optional, or if it isn't of
+// the field's type.
+func (fd FieldDefinition) Check(value string) error {
+	if value == "" {
+		if fd.Optional || fd.Default != "" {
+			return nil
+		}
+		return errors.Errorf("%s is required", fd.Name)
+	}
+	return errors.Wrap(fd.Type.Check(value), fd.Name)
+}
+
+// Check returns an error if value can't be parsed as a value of type t. Types
+// are named loosely, e.g. Float or float, Int or Integer, MemorySize or
+// memory_size; values of other types, such as string, are not checked.
+func (t VarType) Check(value string) error {
+	var err error
+	switch strings.Replace(strings.ToLower(string(t)), "_", "", -1) {
+	default:
+		return nil
+	case "float", "memorysize":
+		_, err = strconv.ParseFloat(value, 64)
+	case "int", "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	}
+	if err != nil {
+		return errors.Errorf("%q isn't a %s", value, t)
+	}
+	return nil
+}
+
 // ClusterMap returns the nicknames for all the clusters referred to in this state
 // paired with the URL for the named cluster
 func (s *State) ClusterMap() map[string]string {
@@ -207,6 +291,7 @@ func (s *State) UpdateDeployments(ds ...*Deployment) error {
 	if err != nil {
 		return err
 	}
+	newManifests.KeepDefaults(s.Manifests)
 
 	s.Manifests = newManifests
 	return nil
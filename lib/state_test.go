@@ -25,6 +25,27 @@ func TestClusterMap(t *testing.T) {
 	assert.Contains(m, "two")
 }
 
+func TestFieldDefinition_Check(t *testing.T) {
+	assert := assert.New(t)
+
+	cpus := FieldDefinition{Name: "cpus", Type: "Float"}
+	assert.NoError(cpus.Check("0.5"))
+	assert.EqualError(cpus.Check(""), "cpus is required")
+	assert.EqualError(cpus.Check("lots"), `cpus: "lots" isn't a Float`)
+
+	ports := FieldDefinition{Name: "ports", Type: "Integer", Default: "1"}
+	assert.NoError(ports.Check(""))
+	assert.NoError(ports.Check("2"))
+	assert.Error(ports.Check("1.5"))
+
+	mem := FieldDefinition{Name: "mem", Type: "memory_size", Optional: true}
+	assert.NoError(mem.Check(""))
+	assert.NoError(mem.Check("1024"))
+	assert.Error(mem.Check("1G"))
+
+	assert.NoError(VarType("string").Check("anything"))
+}
+
 func TestState_Validate(t *testing.T) {
 
 	mid := MustParseManifestID("github.com/user/repo")
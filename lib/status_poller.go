@@ -15,6 +15,8 @@ type (
 		User      User
 		pollChans map[string]ResolveState
 		status    ResolveState
+		// statuses are the latest statuses reported for each cluster.
+		statuses map[string]*ResolveStatus
 	}
 
 	subPoller struct {
@@ -23,6 +25,9 @@ type (
 		Deployments              *Deployments
 		locationFilter, idFilter *ResolveFilter
 		User                     User
+		// status is the status of the resolution of the polled deployment
+		// last reported by the server.
+		status *ResolveStatus
 	}
 
 	// copied from server - avoiding coupling to server implemention
@@ -45,6 +50,7 @@ type (
 	statusData struct {
 		Deployments           []*Deployment
 		Completed, InProgress *ResolveStatus
+		Leader                *Lease `json:",omitempty"`
 	}
 
 	// A ResolveState reflects the state of the Sous clusters in regard to
@@ -52,8 +58,9 @@ type (
 	ResolveState int
 
 	statPair struct {
-		url  string
-		stat ResolveState
+		url, cluster string
+		stat         ResolveState
+		status       *ResolveStatus
 	}
 )
 
@@ -145,8 +152,14 @@ func NewStatusPoller(cl HTTPClient, rf *ResolveFilter, user User) *StatusPoller
 	}
 }
 
-func newSubPoller(clusterName, serverURL string, baseFilter *ResolveFilter, user User) (*subPoller, error) {
-	cl, err := NewClient(serverURL)
+func newSubPoller(clusterName, serverURL string, baseFilter *ResolveFilter, user User, parent HTTPClient) (*subPoller, error) {
+	var cl *LiveHTTPClient
+	var err error
+	if live, is := parent.(*LiveHTTPClient); is {
+		cl, err = live.ForServer(serverURL)
+	} else {
+		cl, err = NewClient(serverURL)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +201,14 @@ func (sp *StatusPoller) Wait(ctx context.Context) (ResolveState, error) {
 	}
 }
 
+// Statuses returns the statuses of the resolution of the polled deployments
+// last reported by the server of each cluster polled, by cluster name. Their
+// logs and errors cover only the polled deployments. Call it once Wait has
+// returned.
+func (sp *StatusPoller) Statuses() map[string]*ResolveStatus {
+	return sp.statuses
+}
+
 func (sp *StatusPoller) waitForever() (ResolveState, error) {
 	// Retrieve the list of servers known to our main server.
 	clusters := &serverListData{}
@@ -236,7 +257,7 @@ func (sp *StatusPoller) subPollers(clusters *serverListData, deps Deployments) (
 		Log.Debug.Printf("Starting poller against %v", s)
 
 		// Kick off a separate process to issue HTTP requests against this cluster.
-		sub, err := newSubPoller(s.ClusterName, s.URL, sp.ResolveFilter, sp.User)
+		sub, err := newSubPoller(s.ClusterName, s.URL, sp.ResolveFilter, sp.User, sp.HTTPClient)
 		if err != nil {
 			return nil, err
 		}
@@ -255,6 +276,7 @@ func (sp *StatusPoller) poll(subs []*subPoller) ResolveState {
 	done := make(chan struct{})
 	go func() {
 		sp.pollChans = map[string]ResolveState{}
+		sp.statuses = map[string]*ResolveStatus{}
 		for {
 			sp.nextSubStatus(collect)
 			if sp.finished() {
@@ -275,6 +297,9 @@ func (sp *StatusPoller) poll(subs []*subPoller) ResolveState {
 func (sp *StatusPoller) nextSubStatus(collect chan statPair) {
 	update := <-collect
 	sp.pollChans[update.url] = update.stat
+	if update.status != nil {
+		sp.statuses[update.cluster] = update.status
+	}
 	Log.Debug.Printf("%s reports state: %s", update.url, update.stat)
 }
 
@@ -299,9 +324,9 @@ func (sp *StatusPoller) finished() bool {
 // start issues a new /status request every half second, reporting the state as computed.
 // c.f. pollOnce.
 func (sub *subPoller) start(rs chan statPair, done chan struct{}) {
-	rs <- statPair{url: sub.URL, stat: ResolveNotPolled}
+	rs <- statPair{url: sub.URL, cluster: sub.ClusterName, stat: ResolveNotPolled}
 	stat := sub.pollOnce()
-	rs <- statPair{url: sub.URL, stat: stat}
+	rs <- statPair{url: sub.URL, cluster: sub.ClusterName, stat: stat, status: sub.status}
 	ticker := time.NewTicker(time.Second / 2)
 	defer ticker.Stop()
 	for {
@@ -311,7 +336,7 @@ func (sub *subPoller) start(rs chan statPair, done chan struct{}) {
 		select {
 		case <-ticker.C:
 			stat = sub.pollOnce()
-			rs <- statPair{url: sub.URL, stat: stat}
+			rs <- statPair{url: sub.URL, cluster: sub.ClusterName, stat: stat, status: sub.status}
 		case <-done:
 			return
 		}
@@ -327,6 +352,7 @@ func (sub *subPoller) pollOnce() ResolveState {
 	}
 	deps := NewDeployments(data.Deployments...)
 	sub.Deployments = &deps
+	sub.status = data.statusFor(sub.locationFilter)
 
 	return sub.computeState(
 		// The serverIntent is the deployment in the GDM when the server started
@@ -456,6 +482,39 @@ func diffResolutionFor(rstat *ResolveStatus, rf *ResolveFilter) *DiffResolution
 	return nil
 }
 
+// statusFor returns the status of the resolution in progress, if it has
+// resolved the deployments rf matches, and otherwise of the last completed
+// resolution, with its Log, Errs and Queued narrowed to those deployments.
+func (data *statusData) statusFor(rf *ResolveFilter) *ResolveStatus {
+	rs := data.InProgress
+	if data.currentFor(rf) == nil {
+		rs = data.Completed
+	}
+	if rs == nil {
+		return nil
+	}
+	narrowed := &ResolveStatus{
+		Phase: rs.Phase,
+		Log:   []DiffResolution{},
+		Errs:  ResolveErrors{Causes: []ErrorWrapper{}},
+	}
+	for _, rez := range rs.Log {
+		if !rf.FilterManifestID(rez.ManifestID) {
+			continue
+		}
+		narrowed.Log = append(narrowed.Log, rez)
+		if rez.Error != nil {
+			narrowed.Errs.Causes = append(narrowed.Errs.Causes, *rez.Error)
+		}
+	}
+	for _, id := range rs.Queued {
+		if rf.FilterManifestID(id.ManifestID) {
+			narrowed.Queued = append(narrowed.Queued, id)
+		}
+	}
+	return narrowed
+}
+
 func (data *statusData) stableFor(rf *ResolveFilter) *DiffResolution {
 	return diffResolutionFor(data.Completed, rf)
 }
@@ -108,6 +108,40 @@ func TestSubPoller_ComputeState(t *testing.T) {
 	testCompute("1.0", deployment("1.0", DeployStatusPending), diffRez("coming", nil), nil, ResolveTasksStarting)
 }
 
+func TestStatusData_statusFor(t *testing.T) {
+	assert := assert.New(t)
+
+	rez := func(repo string, err error) DiffResolution {
+		r := DiffResolution{
+			DeployID: DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: repo}}, Cluster: "main"},
+			Desc:     StableDiff,
+		}
+		if err != nil {
+			r.Error = &ErrorWrapper{MarshallableError: buildMarshableError(err)}
+		}
+		return r
+	}
+	polled := rez("github.com/opentable/example", fmt.Errorf("polled broke"))
+	unrelated := rez("github.com/opentable/unrelated", fmt.Errorf("unrelated broke"))
+
+	data := &statusData{
+		Completed: &ResolveStatus{
+			Phase: "finished",
+			Log:   []DiffResolution{polled, unrelated},
+			Errs:  ResolveErrors{Causes: []ErrorWrapper{*polled.Error, *unrelated.Error}},
+		},
+	}
+
+	rs := data.statusFor(&ResolveFilter{Repo: "github.com/opentable/example"})
+	if !assert.NotNil(rs) {
+		return
+	}
+	assert.Equal([]DiffResolution{polled}, rs.Log)
+	if assert.Len(rs.Errs.Causes, 1) {
+		assert.Equal("polled broke", rs.Errs.Causes[0].message())
+	}
+}
+
 func TestStatusPoller_updateState(t *testing.T) {
 	assert := assert.New(t)
 
@@ -241,6 +275,16 @@ func TestStatusPoller(t *testing.T) {
 		if rState != ResolveComplete {
 			t.Errorf("Resolve state was %s not %s", rState, ResolveComplete)
 		}
+		for _, cluster := range []string{"main", "other"} {
+			rs, ok := poller.Statuses()[cluster]
+			if !ok {
+				t.Errorf("No status reported for %s", cluster)
+				continue
+			}
+			if len(rs.Log) != 1 || rs.Log[0].Desc != StableDiff {
+				t.Errorf("Status for %s logged %v; want the deployment unchanged", cluster, rs.Log)
+			}
+		}
 	}
 }
 
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	sous "github.com/opentable/sous/lib"
@@ -13,6 +14,19 @@ import (
 type (
 	ArtifactResource struct{}
 
+	// GETArtifactHandler describes the artifacts known to the server's
+	// Registry: the artifact for a single SourceID if a version is given,
+	// otherwise every artifact, optionally narrowed to a repo and offset,
+	// and to a range of versions.
+	GETArtifactHandler struct {
+		*restful.QueryValues
+		sous.Registry
+	}
+
+	artifactsWrapper struct {
+		Artifacts []sous.DumperEntry
+	}
+
 	PUTArtifactHandler struct {
 		*http.Request
 		*restful.QueryValues
@@ -20,7 +34,38 @@ type (
 	}
 )
 
-func (ar *ArtifactResource) Put() *PUTArtifactHandler { return &PUTArtifactHandler{} }
+// Get implements Getable on ArtifactResource.
+func (ar *ArtifactResource) Get() restful.Exchanger { return &GETArtifactHandler{} }
+
+// PutUnconditionally implements UnconditionalPutable on ArtifactResource:
+// an artifact PUT records the artifact whatever was recorded before, so
+// clients needn't send If-Match or If-None-Match.
+func (ar *ArtifactResource) PutUnconditionally() restful.Exchanger { return &PUTArtifactHandler{} }
+
+// Exchange implements restful.Exchanger on GETArtifactHandler.
+func (gah *GETArtifactHandler) Exchange() (interface{}, int) {
+	if _, ok := gah.QueryValues.Values["version"]; ok {
+		sid, err := sourceIDFromValues(gah.QueryValues)
+		if err != nil {
+			return err, http.StatusBadRequest
+		}
+		art, err := gah.Registry.GetArtifact(sid)
+		if err != nil {
+			return err, http.StatusNotFound
+		}
+		return sous.NewDumperEntry(sid, art), http.StatusOK
+	}
+
+	filter, err := artifactFilterFromValues(gah.QueryValues)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	es, err := sous.ListArtifacts(gah.Registry, filter)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return artifactsWrapper{Artifacts: es}, http.StatusOK
+}
 
 func (pah *PUTArtifactHandler) Exchange() (interface{}, int) {
 	ba := sous.BuildArtifact{}
@@ -35,7 +80,24 @@ func (pah *PUTArtifactHandler) Exchange() (interface{}, int) {
 		return err, http.StatusNotAcceptable
 	}
 
-	err = pah.Inserter.Insert(sid, ba.Name, "", ba.Qualities)
+	// Without a name, the qualities are attached to the artifact already
+	// recorded for sid, which is only allowed for assertions: advisories
+	// are discovered by the build.
+	if ba.Name == "" {
+		for _, q := range ba.Qualities {
+			if q.Kind != "assertion" {
+				return fmt.Errorf("cannot attach %s %q to an existing artifact", q.Kind, q.Name), http.StatusBadRequest
+			}
+		}
+	}
+
+	if ba.Type == "" || ba.Type == sous.ArtifactTypeDocker {
+		err = pah.Inserter.Insert(sid, ba.Name, "", ba.Qualities)
+	} else if ai, ok := pah.Inserter.(sous.ArtifactInserter); ok {
+		err = ai.InsertArtifact(sid, &ba)
+	} else {
+		return fmt.Errorf("cannot record %s artifacts", ba.Type), http.StatusBadRequest
+	}
 	if err != nil {
 		return err, http.StatusNotAcceptable
 	}
@@ -43,6 +105,37 @@ func (pah *PUTArtifactHandler) Exchange() (interface{}, int) {
 	return "", http.StatusOK
 }
 
+// artifactFilterFromValues selects SourceIDs by the repo, offset and versions
+// query values, each of which may be omitted. The versions are a semver range,
+// like "^1.2" or ">=2.0.0".
+func artifactFilterFromValues(qv *restful.QueryValues) (func(sous.SourceID) bool, error) {
+	var r, o, vs string
+	var vr *semv.Range
+	_, hasOffset := qv.Values["offset"]
+	err := firsterr.Returned(
+		func() (err error) { r, err = qv.Single("repo", ""); return },
+		func() (err error) { o, err = qv.Single("offset", ""); return },
+		func() (err error) { vs, err = qv.Single("versions", ""); return },
+		func() error {
+			if vs == "" {
+				return nil
+			}
+			rng, err := semv.ParseRange(vs)
+			vr = &rng
+			return err
+		},
+	)
+	return func(sid sous.SourceID) bool {
+		if r != "" && sid.Location.Repo != r {
+			return false
+		}
+		if hasOffset && sid.Location.Dir != o {
+			return false
+		}
+		return vr == nil || vr.SatisfiedBy(sid.Version)
+	}, err
+}
+
 func sourceIDFromValues(qv *restful.QueryValues) (sous.SourceID, error) {
 	var r, o, vs string
 	var v semv.Version
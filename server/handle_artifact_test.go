@@ -3,12 +3,15 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	sous "github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/restful"
+	"github.com/samsalisbury/psyringe"
 )
 
 type artifactTestInserter struct {
@@ -19,6 +22,16 @@ func (ati *artifactTestInserter) Insert(sid sous.SourceID, in, etag string, qs [
 	return ati.insFunc(sid, in, etag, qs)
 }
 
+type artifactTypeTestInserter struct {
+	artifactTestInserter
+	inserted *sous.BuildArtifact
+}
+
+func (ati *artifactTypeTestInserter) InsertArtifact(sid sous.SourceID, art *sous.BuildArtifact) error {
+	ati.inserted = art
+	return nil
+}
+
 func TestPUTArtifact(t *testing.T) {
 	art := sous.NewBuildArtifact("test.reg.com/repo/test", sous.Strpairs{})
 	buf := &bytes.Buffer{}
@@ -60,3 +73,190 @@ func TestPUTArtifact(t *testing.T) {
 		t.Errorf("inserted artifact name was %s, should be test.reg.com/repo/test", inName)
 	}
 }
+
+func TestPUTArtifactAssertions(t *testing.T) {
+	q, err := url.ParseQuery("repo=github.com/opentable/test&offset=&version=1.2.3")
+	if err != nil {
+		t.Fatal("error parsing query", err)
+	}
+
+	var inQuals []sous.Quality
+	exchange := func(art *sous.BuildArtifact) int {
+		buf := &bytes.Buffer{}
+		json.NewEncoder(buf).Encode(art)
+		req, err := http.NewRequest("PUT", "", buf)
+		if err != nil {
+			t.Fatal("error building request", err)
+		}
+		pah := &PUTArtifactHandler{
+			Request:     req,
+			QueryValues: &restful.QueryValues{q},
+			Inserter: &artifactTestInserter{
+				insFunc: func(s sous.SourceID, in, et string, qz []sous.Quality) error {
+					inQuals = qz
+					return nil
+				},
+			},
+		}
+		_, status := pah.Exchange()
+		return status
+	}
+
+	tested := []sous.Quality{{Name: "integration-tested", Kind: "assertion"}}
+	if status := exchange(&sous.BuildArtifact{Qualities: tested}); status != 200 {
+		t.Errorf("status should be 200, was %d", status)
+	}
+	if len(inQuals) != 1 || inQuals[0] != tested[0] {
+		t.Errorf("inserted qualities were %v, should be %v", inQuals, tested)
+	}
+
+	advisory := []sous.Quality{{Name: "ephemeral_tag", Kind: "advisory"}}
+	if status := exchange(&sous.BuildArtifact{Qualities: advisory}); status != 400 {
+		t.Errorf("status should be 400 for an advisory without an artifact name, was %d", status)
+	}
+}
+
+func TestPUTArtifactTypes(t *testing.T) {
+	q, err := url.ParseQuery("repo=github.com/opentable/test&offset=&version=1.2.3")
+	if err != nil {
+		t.Fatal("error parsing query", err)
+	}
+	exchange := func(ins sous.Inserter) int {
+		buf := &bytes.Buffer{}
+		json.NewEncoder(buf).Encode(&sous.BuildArtifact{Name: "/builds/test", Type: sous.ArtifactTypeOCILayout})
+		req, err := http.NewRequest("PUT", "", buf)
+		if err != nil {
+			t.Fatal("error building request", err)
+		}
+		pah := &PUTArtifactHandler{Request: req, QueryValues: &restful.QueryValues{q}, Inserter: ins}
+		_, status := pah.Exchange()
+		return status
+	}
+
+	imagesOnly := &artifactTestInserter{insFunc: func(sous.SourceID, string, string, []sous.Quality) error {
+		t.Error("an oci-layout artifact should not be inserted as an image")
+		return nil
+	}}
+	if status := exchange(imagesOnly); status != 400 {
+		t.Errorf("status should be 400 for an inserter of images only, was %d", status)
+	}
+
+	typed := &artifactTypeTestInserter{artifactTestInserter: *imagesOnly}
+	if status := exchange(typed); status != 200 {
+		t.Errorf("status should be 200, was %d", status)
+	}
+	if typed.inserted == nil || typed.inserted.Type != sous.ArtifactTypeOCILayout {
+		t.Errorf("inserted artifact was %v, should be of type %s", typed.inserted, sous.ArtifactTypeOCILayout)
+	}
+}
+
+func TestGETArtifact(t *testing.T) {
+	sids := []sous.SourceID{
+		sous.MustParseSourceID("github.com/opentable/test,1.2.3"),
+		sous.MustParseSourceID("github.com/opentable/test,2.0.0"),
+		sous.MustParseSourceID("github.com/opentable/other,1.5.0"),
+	}
+	exchange := func(query string) (interface{}, int) {
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal("error parsing query", err)
+		}
+		reg := sous.NewDummyRegistry()
+		reg.FeedSourceIDList(sids, nil)
+		gah := &GETArtifactHandler{
+			QueryValues: &restful.QueryValues{Values: q},
+			Registry:    reg,
+		}
+		return gah.Exchange()
+	}
+
+	data, status := exchange("repo=github.com/opentable/test&versions=^1")
+	if status != 200 {
+		t.Fatalf("status should be 200, was %d", status)
+	}
+	es := data.(artifactsWrapper).Artifacts
+	if len(es) != 1 || !es[0].SourceID.Equal(sids[0]) {
+		t.Errorf("listed %v, should be only %v", es, sids[0])
+	}
+
+	data, status = exchange("")
+	if status != 200 {
+		t.Fatalf("status should be 200, was %d", status)
+	}
+	if es := data.(artifactsWrapper).Artifacts; len(es) != 3 {
+		t.Errorf("listed %d artifacts, should be 3", len(es))
+	}
+
+	if _, status := exchange("versions=bogus"); status != 400 {
+		t.Errorf("status should be 400 for a bad version range, was %d", status)
+	}
+
+	q, _ := url.ParseQuery("repo=github.com/opentable/test&offset=&version=1.2.3")
+	reg := sous.NewDummyRegistry()
+	reg.FeedArtifact(&sous.BuildArtifact{Name: "test.reg.com/repo/test@sha256:abcd", Type: "docker"}, nil)
+	gah := &GETArtifactHandler{QueryValues: &restful.QueryValues{Values: q}, Registry: reg}
+	data, status = gah.Exchange()
+	if status != 200 {
+		t.Fatalf("status should be 200, was %d", status)
+	}
+	if e := data.(sous.DumperEntry); e.Digest != "sha256:abcd" {
+		t.Errorf("digest was %q, should be sha256:abcd", e.Digest)
+	}
+
+	reg.FeedArtifact(nil, errors.New("no such artifact"))
+	if _, status := gah.Exchange(); status != 404 {
+		t.Errorf("status should be 404 for a missing artifact, was %d", status)
+	}
+}
+
+// TestPUTArtifactAuthenticated records an artifact with an HTTPNameInserter
+// on a server that requires authentication, and without If-Match or
+// If-None-Match.
+func TestPUTArtifactAuthenticated(t *testing.T) {
+	var inserted string
+	ins := &artifactTestInserter{
+		insFunc: func(s sous.SourceID, in, et string, qz []sous.Quality) error {
+			inserted = in
+			return nil
+		},
+	}
+	var auth restful.Authenticator = restful.MultiAuthenticator{&restful.StaticTokenAuthenticator{
+		Users: &restful.FileUserStore{Users: []restful.StoredUser{{
+			Principal: restful.Principal{Name: "Builder", Email: "builder@example.com"},
+			Tokens:    []string{"builders-token"},
+		}}},
+	}}
+	gf := func() restful.Injector {
+		return psyringe.New(sous.SilentLogSet,
+			func() restful.Authenticator { return auth },
+			func() sous.Inserter { return ins })
+	}
+	router, err := SousRouteMap.BuildRouter(gf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cl, err := sous.NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hni := sous.NewHTTPNameInserter(cl, sous.User{Name: "Builder"})
+	sid := sous.MustNewSourceID("github.com/opentable/test", "", "1.2.3")
+
+	if err := hni.Insert(sid, "test.reg.com/repo/test:1.2.3", "", nil); err == nil {
+		t.Errorf("inserting without a token returned nil error")
+	}
+	if inserted != "" {
+		t.Errorf("artifact %q recorded without a token", inserted)
+	}
+
+	cl.AuthToken = "builders-token"
+	if err := hni.Insert(sid, "test.reg.com/repo/test:1.2.3", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if inserted != "test.reg.com/repo/test:1.2.3" {
+		t.Errorf("recorded artifact %q, want test.reg.com/repo/test:1.2.3", inserted)
+	}
+}
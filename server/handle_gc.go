@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// GCResource describes the garbage collection of the registry.
+	GCResource struct{}
+
+	// GCHandler handles GET requests for /gc.
+	GCHandler struct {
+		GarbageCollector *docker.GarbageCollector
+	}
+)
+
+// Get implements Getable on GCResource.
+func (*GCResource) Get() restful.Exchanger { return &GCHandler{} }
+
+// Exchange implements restful.Exchanger on GCHandler. It reports the images
+// removed by the last garbage collection.
+func (h *GCHandler) Exchange() (interface{}, int) {
+	if h.GarbageCollector == nil || h.GarbageCollector.Interval <= 0 {
+		return "No garbage collection configured", http.StatusNotFound
+	}
+	return h.GarbageCollector.Report(), http.StatusOK
+}
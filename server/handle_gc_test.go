@@ -0,0 +1,26 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/ext/docker"
+)
+
+func TestHandlesGCGet(t *testing.T) {
+	assert := assert.New(t)
+
+	th := &GCHandler{GarbageCollector: docker.NewGarbageCollector(nil, 3, time.Hour)}
+	data, status := th.Exchange()
+	assert.Equal(200, status)
+	assert.Empty(data.(docker.GCReport).Removed)
+
+	th = &GCHandler{GarbageCollector: docker.NewGarbageCollector(nil, 3, 0)}
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+
+	th = &GCHandler{}
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// HarvestResource describes the harvest of the registry catalog.
+	HarvestResource struct{}
+
+	// HarvestHandler handles GET requests for /harvest.
+	HarvestHandler struct {
+		Harvester *docker.Harvester
+	}
+)
+
+// Get implements Getable on HarvestResource.
+func (*HarvestResource) Get() restful.Exchanger { return &HarvestHandler{} }
+
+// Exchange implements restful.Exchanger on HarvestHandler. It reports the
+// progress of the harvest underway, or the results of the last one.
+func (h *HarvestHandler) Exchange() (interface{}, int) {
+	if h.Harvester == nil {
+		return "No harvester configured", http.StatusNotFound
+	}
+	return h.Harvester.Status(), http.StatusOK
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/ext/docker"
+)
+
+func TestHandlesHarvestGet(t *testing.T) {
+	assert := assert.New(t)
+
+	th := &HarvestHandler{Harvester: docker.NewHarvester(nil, 0, 1)}
+	data, status := th.Exchange()
+	assert.Equal(200, status)
+	assert.False(data.(docker.HarvestStatus).Running)
+
+	th = &HarvestHandler{}
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
@@ -26,6 +28,7 @@ type (
 		*sous.LogSet
 		*http.Request
 		*restful.QueryValues
+		*restful.Principal
 		User        ClientUser
 		StateWriter graph.StateWriter
 	}
@@ -34,6 +37,7 @@ type (
 	DELETEManifestHandler struct {
 		*sous.State
 		*restful.QueryValues
+		*restful.Principal
 		StateWriter graph.StateWriter
 	}
 )
@@ -66,10 +70,13 @@ func (dmh *DELETEManifestHandler) Exchange() (interface{}, int) {
 	if err != nil {
 		return err, http.StatusNotFound
 	}
-	_, there := dmh.State.Manifests.Get(mid)
+	m, there := dmh.State.Manifests.Get(mid)
 	if !there {
 		return nil, http.StatusNotFound
 	}
+	if err := authorizeManifestChange(dmh.Principal, m); err != nil {
+		return err, http.StatusForbidden
+	}
 	dmh.State.Manifests.Remove(mid)
 
 	return nil, http.StatusNoContent
@@ -88,7 +95,25 @@ func (pmh *PUTManifestHandler) Exchange() (interface{}, int) {
 	flaws := m.Validate()
 	if len(flaws) > 0 {
 		pmh.Vomit.Printf("%#v", flaws)
-		return "Invalid manifest", http.StatusBadRequest
+		descs := make([]string, len(flaws))
+		for i, f := range flaws {
+			descs[i] = fmt.Sprint(f)
+		}
+		return "Invalid manifest: " + strings.Join(descs, "; "), http.StatusBadRequest
+	}
+	// The owners of an existing manifest decide who may change it; a new
+	// manifest may be created by any of the owners it names.
+	existing, there := pmh.State.Manifests.Get(mid)
+	if !there {
+		existing = m
+	}
+	if err := authorizeManifestChange(pmh.Principal, existing); err != nil {
+		return err, http.StatusForbidden
+	}
+	// A manifest with no Defaults, as clients make from deployments, keeps
+	// those of the manifest it replaces.
+	if there {
+		m.KeepDefaults(existing)
 	}
 	pmh.State.Manifests.Set(mid, m)
 	if err := pmh.StateWriter.WriteState(pmh.State, sous.User(pmh.User)); err != nil {
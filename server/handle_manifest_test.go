@@ -155,3 +155,98 @@ func TestHandlesManifestPut(t *testing.T) {
 	assert.Equal(changed.Owners[1], "judson")
 
 }
+
+func TestHandlesManifestPutUnauthorized(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+	state := sous.NewState()
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"sam@example.com"},
+		Kind:   sous.ManifestKindService,
+	})
+	writer := graph.StateWriter{StateWriter: &sous.DummyStateManager{State: state}}
+
+	manifest := &sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"mallory@example.com"},
+		Kind:   sous.ManifestKindService,
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(json.NewEncoder(buf).Encode(manifest))
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(err)
+
+	th := &PUTManifestHandler{
+		Request:     req,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: &restful.QueryValues{Values: q},
+		Principal:   &restful.Principal{Name: "Mallory", Email: "mallory@example.com"},
+	}
+
+	_, status := th.Exchange()
+	assert.Equal(http.StatusForbidden, status)
+
+	unchanged, found := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	require.True(found)
+	assert.Equal([]string{"sam@example.com"}, unchanged.Owners)
+}
+
+func TestHandlesManifestPutInvalid(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+	state := sous.NewState()
+	writer := graph.StateWriter{StateWriter: &sous.DummyStateManager{State: state}}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(buf).Encode(&sous.Manifest{Source: sous.SourceLocation{Repo: "gh"}}))
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(t, err)
+
+	th := &PUTManifestHandler{
+		Request:     req,
+		LogSet:      &sous.Log,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: &restful.QueryValues{Values: q},
+	}
+
+	data, status := th.Exchange()
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Regexp(t, "^Invalid manifest: .*missing Kind", data)
+}
+
+func TestHandlesManifestDeleteAuthorization(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}
+	state := sous.NewState()
+	state.Manifests.Add(&sous.Manifest{
+		Source: mid.Source,
+		Owners: []string{"sam@example.com"},
+		Kind:   sous.ManifestKindService,
+	})
+
+	th := &DELETEManifestHandler{
+		State:       state,
+		QueryValues: &restful.QueryValues{Values: q},
+		Principal:   &restful.Principal{Name: "Mallory", Email: "mallory@example.com"},
+	}
+	_, status := th.Exchange()
+	assert.Equal(http.StatusForbidden, status)
+	_, found := state.Manifests.Get(mid)
+	assert.True(found)
+
+	th.Principal = &restful.Principal{Name: "Sam", Email: "Sam@Example.com"}
+	_, status = th.Exchange()
+	assert.Equal(http.StatusNoContent, status)
+	_, found = state.Manifests.Get(mid)
+	assert.False(found)
+}
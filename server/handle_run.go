@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// RunResource describes the runs of on-demand and run-once deployments.
+	RunResource struct{}
+
+	// GETRunHandler handles GET requests for /run.
+	GETRunHandler struct {
+		*sous.State
+		*restful.QueryValues
+		Runner *singularity.Runner
+	}
+
+	// PUTRunHandler handles PUT requests for /run.
+	PUTRunHandler struct {
+		*sous.State
+		*sous.LogSet
+		*http.Request
+		*restful.QueryValues
+		*restful.Principal
+		User   ClientUser
+		Runner *singularity.Runner
+	}
+
+	// A TriggeredRun is a run triggered by a PUT to /run.
+	TriggeredRun struct {
+		RunID string
+		// Args and Env are those the run was triggered with.
+		Args []string
+		Env  map[string]string
+		// TriggeredBy is the user who triggered the run.
+		TriggeredBy sous.User
+	}
+)
+
+// Get implements Getable on RunResource.
+func (*RunResource) Get() restful.Exchanger { return &GETRunHandler{} }
+
+// Put implements Putable on RunResource.
+func (*RunResource) Put() restful.Exchanger { return &PUTRunHandler{} }
+
+// Exchange implements restful.Exchanger on GETRunHandler. It returns the task
+// of the run given by the "run" query parameter of the deployment given by
+// the others, or 404 if Singularity hasn't launched it yet.
+func (h *GETRunHandler) Exchange() (interface{}, int) {
+	did, runID, err := runFromValues(h.QueryValues)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if _, ok := h.State.Defs.Clusters[did.Cluster]; !ok {
+		return "No cluster named " + did.Cluster, http.StatusNotFound
+	}
+	task, err := h.Runner.RunTask(h.State.Defs.Clusters, did, runID)
+	if err != nil {
+		return err.Error(), http.StatusInternalServerError
+	}
+	if task == nil {
+		return "No task for run " + runID + " yet", http.StatusNotFound
+	}
+	return task, http.StatusOK
+}
+
+// Exchange implements restful.Exchanger on PUTRunHandler. It triggers a run of
+// the deployment, with the Args and Env in the request body, and records who
+// triggered it. Only the manifest's owners may trigger runs when the server
+// authenticates its clients.
+func (h *PUTRunHandler) Exchange() (interface{}, int) {
+	did, runID, err := runFromValues(h.QueryValues)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	m, ok := h.State.Manifests.Get(did.ManifestID)
+	if !ok {
+		return "No manifest " + did.ManifestID.String(), http.StatusNotFound
+	}
+	if err := authorizeManifestChange(h.Principal, m); err != nil {
+		return err.Error(), http.StatusForbidden
+	}
+	run := TriggeredRun{RunID: runID, TriggeredBy: sous.User(h.User)}
+	if err := json.NewDecoder(h.Request.Body).Decode(&run); err != nil && err != io.EOF {
+		return err.Error(), http.StatusBadRequest
+	}
+	// The body mustn't override what the query and the server determine.
+	run.RunID, run.TriggeredBy = runID, sous.User(h.User)
+	if err := singularity.Runnable(m, did.Cluster); err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	rr := singularity.RunRequest{ID: runID, Args: run.Args, Env: run.Env}
+	if err := h.Runner.Trigger(h.State.Defs.Clusters, m, did.Cluster, rr, run.TriggeredBy); err != nil {
+		return err.Error(), http.StatusInternalServerError
+	}
+	h.Info.Printf("%s triggered run %s of %v", run.TriggeredBy, runID, did)
+	return run, http.StatusOK
+}
+
+// runFromValues parses the deployment and the "run" query parameter.
+func runFromValues(qv *restful.QueryValues) (sous.DeployID, string, error) {
+	did, err := deployIDFromValues(qv)
+	if err != nil {
+		return did, "", err
+	}
+	runID, err := qv.Single("run")
+	return did, runID, err
+}
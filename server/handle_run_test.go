@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	sing "github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/sous/util/restful"
+	"github.com/opentable/swaggering"
+)
+
+func TestHandlesRunPutAndGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fake := fake_singularity.NewServer()
+	defer fake.Close()
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"test": {Name: "test", BaseURL: fake.URL}}
+	m := &sous.Manifest{
+		Source:      sous.SourceLocation{Repo: "gh1"},
+		Kind:        sous.ManifestKindOnDemand,
+		Owners:      []string{"owner@example.com"},
+		Deployments: sous.DeploySpecs{"test": {}},
+	}
+	state.Manifests.Add(m)
+	reqID := singularity.MakeRequestID(sous.DeployID{ManifestID: m.ID(), Cluster: "test"})
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(err)
+	_, err = sing.NewClient(fake.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(err)
+
+	qv := func(s string) *restful.QueryValues {
+		v, err := url.ParseQuery(s)
+		require.NoError(err)
+		return &restful.QueryValues{Values: v}
+	}
+	runner := singularity.NewRunner(singularity.NewTaskReader())
+	get := &GETRunHandler{State: state, Runner: runner, QueryValues: qv("repo=gh1&cluster=test&run=r1")}
+	_, status := get.Exchange()
+	assert.Equal(404, status, "not run yet")
+
+	put := func(query, body string, p *restful.Principal) (interface{}, int) {
+		rq, err := http.NewRequest("PUT", "/run", strings.NewReader(body))
+		require.NoError(err)
+		h := &PUTRunHandler{State: state, LogSet: sous.SilentLogSet(), Request: rq, Principal: p,
+			QueryValues: qv(query), User: ClientUser{Name: "Owner", Email: "owner@example.com"}, Runner: runner}
+		return h.Exchange()
+	}
+	data, status := put("repo=gh1&cluster=test&run=r1", `{"Args": ["x"], "Env": {"A": "1"}, "TriggeredBy": {"Name": "someone else"}}`, nil)
+	require.Equal(200, status, "%v", data)
+	run := data.(TriggeredRun)
+	assert.Equal("r1", run.RunID)
+	assert.Equal([]string{"x"}, run.Args)
+	assert.Equal("owner@example.com", run.TriggeredBy.Email, "the body can't say who triggered the run")
+
+	data, status = get.Exchange()
+	require.Equal(200, status, "%v", data)
+	assert.Equal(fake.RunTaskID(reqID, "r1"), data.(*singularity.Task).ID)
+
+	_, status = put("repo=gh1&cluster=test&run=r2", "", &restful.Principal{Name: "Other", Email: "other@example.com"})
+	assert.Equal(403, status, "not an owner")
+	_, status = put("repo=gh1&cluster=test", "", nil)
+	assert.Equal(400, status, "no run ID")
+	_, status = put("repo=nope&cluster=test&run=r2", "", nil)
+	assert.Equal(404, status)
+
+	m.Kind = sous.ManifestKindService
+	_, status = put("repo=gh1&cluster=test&run=r2", "", nil)
+	assert.Equal(400, status, "services can't be run")
+}
@@ -21,6 +21,9 @@ type (
 	statusData struct {
 		Deployments           []*sous.Deployment
 		Completed, InProgress *sous.ResolveStatus
+		// Leader is the current leadership lease, if this server campaigns for
+		// leadership.
+		Leader *sous.Lease `json:",omitempty"`
 	}
 )
 
@@ -34,5 +37,9 @@ func (h *StatusHandler) Exchange() (interface{}, int) {
 		status.Deployments = append(status.Deployments, d)
 	}
 	status.Completed, status.InProgress = h.AutoResolver.Statuses()
+	if l := h.AutoResolver.Leadership; l != nil {
+		lease := l.Leader()
+		status.Leader = &lease
+	}
 	return status, http.StatusOK
 }
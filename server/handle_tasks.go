@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/firsterr"
+	"github.com/opentable/sous/util/restful"
+)
+
+type (
+	// TasksResource describes the Singularity tasks of a deployment.
+	TasksResource struct{}
+
+	// TasksHandler handles GET requests for /tasks.
+	TasksHandler struct {
+		*sous.State
+		*restful.QueryValues
+		TaskReader *singularity.TaskReader
+	}
+
+	// LogsResource describes the logs of a deployment's tasks.
+	LogsResource struct{}
+
+	// LogsHandler handles GET requests for /logs.
+	LogsHandler struct {
+		*sous.State
+		*restful.QueryValues
+		TaskReader *singularity.TaskReader
+	}
+)
+
+// defaultRecentTasks is how many finished tasks /tasks lists, unless asked
+// for some other number.
+const defaultRecentTasks = 5
+
+// Get implements Getable on TasksResource.
+func (*TasksResource) Get() restful.Exchanger { return &TasksHandler{} }
+
+// Get implements Getable on LogsResource.
+func (*LogsResource) Get() restful.Exchanger { return &LogsHandler{} }
+
+// Exchange implements restful.Exchanger on TasksHandler. It lists the active
+// and recently finished tasks of the deployment given by the repo, offset,
+// flavor and cluster query parameters; "recent" says how many finished tasks
+// to list.
+func (h *TasksHandler) Exchange() (interface{}, int) {
+	did, err := deployIDFromValues(h.QueryValues)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if _, ok := h.State.Defs.Clusters[did.Cluster]; !ok {
+		return "No cluster named " + did.Cluster, http.StatusNotFound
+	}
+	recent := defaultRecentTasks
+	if r, err := h.Single("recent", ""); err != nil {
+		return err.Error(), http.StatusBadRequest
+	} else if r != "" {
+		if recent, err = strconv.Atoi(r); err != nil {
+			return err.Error(), http.StatusBadRequest
+		}
+	}
+	tasks, err := h.TaskReader.DeploymentTasks(h.State.Defs.Clusters, did, recent)
+	if err != nil {
+		return err.Error(), http.StatusInternalServerError
+	}
+	return tasks, http.StatusOK
+}
+
+// Exchange implements restful.Exchanger on LogsHandler. It returns a chunk of
+// the stdout or stderr ("file") of one of the deployment's tasks ("task"),
+// from "position" bytes in.
+func (h *LogsHandler) Exchange() (interface{}, int) {
+	did, err := deployIDFromValues(h.QueryValues)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	if _, ok := h.State.Defs.Clusters[did.Cluster]; !ok {
+		return "No cluster named " + did.Cluster, http.StatusNotFound
+	}
+	var task, file, pos string
+	err = firsterr.Returned(
+		func() error { task, err = h.Single("task"); return err },
+		func() error { file, err = h.Single("file", "stdout"); return err },
+		func() error { pos, err = h.Single("position", "0"); return err },
+	)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	position, err := strconv.ParseInt(pos, 10, 64)
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	chunk, err := h.TaskReader.DeploymentLog(h.State.Defs.Clusters, did, task, file, position)
+	if err != nil {
+		return err.Error(), http.StatusInternalServerError
+	}
+	return chunk, http.StatusOK
+}
+
+// deployIDFromValues parses the repo, offset, flavor and cluster query
+// parameters.
+func deployIDFromValues(qv *restful.QueryValues) (sous.DeployID, error) {
+	mid, err := manifestIDFromValues(qv)
+	if err != nil {
+		return sous.DeployID{}, err
+	}
+	cluster, err := qv.Single("cluster")
+	if err != nil {
+		return sous.DeployID{}, err
+	}
+	return sous.DeployID{ManifestID: mid, Cluster: cluster}, nil
+}
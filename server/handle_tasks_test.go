@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	sing "github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/opentable/sous/util/restful"
+	"github.com/opentable/swaggering"
+)
+
+func TestHandlesTasksAndLogsGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fake := fake_singularity.NewServer()
+	defer fake.Close()
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{"test": {Name: "test", BaseURL: fake.URL}}
+	did := sous.DeployID{
+		ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh1"}},
+		Cluster:    "test",
+	}
+	reqID := singularity.MakeRequestID(did)
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, map[string]interface{}{"Id": reqID})
+	require.NoError(err)
+	_, err = sing.NewClient(fake.URL).PostRequest(req.(*dtos.SingularityRequest))
+	require.NoError(err)
+	taskID, err := fake.AddTask(reqID, fake_singularity.Task{Host: "h1", State: "TASK_FAILED", Stderr: "oops\n"})
+	require.NoError(err)
+
+	qv := func(s string) *restful.QueryValues {
+		v, err := url.ParseQuery(s)
+		require.NoError(err)
+		return &restful.QueryValues{Values: v}
+	}
+	reader := singularity.NewTaskReader()
+
+	th := &TasksHandler{State: state, QueryValues: qv("repo=gh1&cluster=test"), TaskReader: reader}
+	data, status := th.Exchange()
+	require.Equal(200, status, "%v", data)
+	tasks := data.([]singularity.Task)
+	require.Len(tasks, 1)
+	assert.Equal(taskID, tasks[0].ID)
+	assert.Equal("TASK_FAILED", tasks[0].State)
+
+	th.QueryValues = qv("repo=gh1&cluster=test&recent=0")
+	data, status = th.Exchange()
+	assert.Equal(200, status)
+	assert.Empty(data)
+
+	th.QueryValues = qv("repo=gh1")
+	_, status = th.Exchange()
+	assert.Equal(400, status, "no cluster given")
+	th.QueryValues = qv("repo=gh1&cluster=nope")
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+
+	lh := &LogsHandler{State: state, TaskReader: reader,
+		QueryValues: qv("repo=gh1&cluster=test&file=stderr&position=1&task=" + url.QueryEscape(taskID))}
+	data, status = lh.Exchange()
+	require.Equal(200, status, "%v", data)
+	assert.Equal(singularity.LogChunk{Data: "ops\n", Offset: 1, NextOffset: 5}, data)
+
+	lh.QueryValues = qv("repo=gh1&cluster=test&task=someone-elses-task")
+	_, status = lh.Exchange()
+	assert.Equal(500, status)
+	lh.QueryValues = qv("repo=gh1&cluster=test")
+	_, status = lh.Exchange()
+	assert.Equal(400, status, "no task given")
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/opentable/sous/lib"
+)
+
+// proxiedHeader marks requests a follower has forwarded to the leader, so
+// that they aren't forwarded again if leadership changes in the meantime.
+const proxiedHeader = "Sous-Proxied-By"
+
+// leaderProxy serves reads locally, but forwards requests that change state
+// to the leader whenever this server is a follower.
+type leaderProxy struct {
+	http.Handler
+	leadership *sous.Leadership
+	transport  http.RoundTripper
+	log        *sous.LogSet
+}
+
+// ServeHTTP implements http.Handler on leaderProxy.
+func (lp *leaderProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" || r.Method == "HEAD" || lp.leadership.IsLeader() {
+		lp.Handler.ServeHTTP(w, r)
+		return
+	}
+	leader := lp.leadership.Leader().Holder
+	if leader == "" || r.Header.Get(proxiedHeader) != "" {
+		http.Error(w, "no leader available to accept writes", http.StatusServiceUnavailable)
+		return
+	}
+	u, err := url.Parse(leader)
+	if err != nil {
+		http.Error(w, "leader URL is invalid: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	lp.log.Debug.Printf("Forwarding %s %s to leader %s", r.Method, r.URL, leader)
+	r.Header.Set(proxiedHeader, lp.leadership.ID)
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	proxy.Transport = lp.transport
+	proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+)
+
+func TestLeaderProxy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	answer := func(who string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(who + " " + r.Method))
+		})
+	}
+
+	leaderSrv := httptest.NewServer(answer("leader"))
+	defer leaderSrv.Close()
+
+	backend := &sous.MemoryLeaseBackend{}
+	leader := sous.NewLeadership(backend, leaderSrv.URL, time.Minute)
+	_, err := leader.Campaign()
+	require.NoError(err)
+
+	follower := sous.NewLeadership(backend, "http://follower", time.Minute)
+	leading, err := follower.Campaign()
+	require.NoError(err)
+	require.False(leading)
+
+	followerSrv := httptest.NewServer(&leaderProxy{
+		Handler:    answer("follower"),
+		leadership: follower,
+		transport:  http.DefaultTransport,
+		log:        sous.SilentLogSet(),
+	})
+	defer followerSrv.Close()
+
+	send := func(method string) string {
+		req, err := http.NewRequest(method, followerSrv.URL+"/manifest", strings.NewReader("{}"))
+		require.NoError(err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(err)
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(err)
+		return string(body)
+	}
+
+	assert.Equal("follower GET", send("GET"))
+	assert.Equal("leader PUT", send("PUT"))
+	assert.Equal("leader DELETE", send("DELETE"))
+}
@@ -11,5 +11,10 @@ var (
 		{"artifact", "/artifact", &ArtifactResource{}},
 		{"status", "/status", &StatusResource{}},
 		{"servers", "/servers", &ServerListResource{}},
+		{"harvest", "/harvest", &HarvestResource{}},
+		{"gc", "/gc", &GCResource{}},
+		{"tasks", "/tasks", &TasksResource{}},
+		{"logs", "/logs", &LogsResource{}},
+		{"run", "/run", &RunResource{}},
 	}
 )
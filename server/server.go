@@ -5,6 +5,7 @@ import (
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
 	"github.com/opentable/sous/util/restful"
 )
 
@@ -22,25 +23,63 @@ func New(laddr string, gf func() restful.Injector) *http.Server {
 // the same across requests
 type fixedPoints struct {
 	*config.Config
+	*sous.Leadership
+	*sous.LogSet
 }
 
-// Handler builds the http.Handler for the Sous server httprouter.
-func Handler(mainGraph *graph.SousGraph) http.Handler {
-	mainGraph.Inject(&fixedPoints{})
+// Handler builds the http.Handler for the Sous server httprouter. If the
+// server campaigns for leadership, writes are forwarded to the leader while
+// it is a follower.
+func Handler(mainGraph *graph.SousGraph) (http.Handler, error) {
+	fp := &fixedPoints{}
+	mainGraph.Inject(fp)
 	gf := func() restful.Injector {
 		g := mainGraph.Clone()
 		AddsPerRequest(g)
 
 		return g
 	}
-	return SousRouteMap.BuildRouter(gf)
+	router, err := SousRouteMap.BuildRouter(gf)
+	if err != nil {
+		return nil, err
+	}
+	if fp.Leadership == nil {
+		return router, nil
+	}
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if fp.Config != nil {
+		tc, err := fp.Config.TLS.ClientConfig()
+		if err != nil {
+			fp.LogSet.Warn.Printf("Forwarding writes to the leader without TLS configuration: %v", err)
+		}
+		transport.TLSClientConfig = tc
+	}
+	return &leaderProxy{
+		Handler:    router,
+		leadership: fp.Leadership,
+		transport:  transport,
+		log:        fp.LogSet,
+	}, nil
 }
 
-// Run starts a server up.
+// Run starts a server up. If the graph's ServerTLSConfig is set, the server
+// listens with TLS.
 func Run(mainGraph *graph.SousGraph, laddr string) error {
+	h, err := Handler(mainGraph)
+	if err != nil {
+		return err
+	}
 	s := &http.Server{
 		Addr:    laddr,
-		Handler: Handler(mainGraph),
+		Handler: h,
+	}
+	var tc struct{ graph.ServerTLSConfig }
+	if err := mainGraph.Inject(&tc); err != nil {
+		return err
+	}
+	if tc.Config == nil {
+		return s.ListenAndServe()
 	}
-	return s.ListenAndServe()
+	s.TLSConfig = tc.Config
+	return s.ListenAndServeTLS("", "")
 }
@@ -25,7 +25,8 @@ func basicInjectedHandler(factory restful.ExchangeFactory, t *testing.T) restful
 		return g.Clone()
 	}
 
-	exchLogger := SousRouteMap.SingleExchanger(factory, gf)
+	exchLogger, err := SousRouteMap.SingleExchanger(factory, gf)
+	require.NoError(err)
 
 	logger, ok := exchLogger.(*restful.ExchangeLogger)
 	require.True(ok)
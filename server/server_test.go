@@ -25,7 +25,9 @@ func (suite *serverSuite) SetupTest() {
 	g := graph.TestGraphWithConfig(&bytes.Buffer{}, os.Stdout, os.Stdout,
 		"StateLocation: '../ext/storage/testdata/in'\n")
 	g.Add(&config.Verbosity{})
-	suite.server = httptest.NewServer(Handler(g))
+	h, err := Handler(g)
+	suite.Require().NoError(err)
+	suite.server = httptest.NewServer(h)
 	suite.url = suite.server.URL
 }
 
@@ -4,16 +4,41 @@ import (
 	"net/http"
 
 	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/restful"
+	"github.com/pkg/errors"
 )
 
 // ClientUser is the sous.User configured in the calling client.
 type ClientUser sous.User
 
-// getUser parses a ClientUser from the headers of a HTTP request.
-func getUser(req *http.Request) ClientUser {
+// getUser derives the ClientUser for a HTTP request. When the server
+// authenticates its clients, the user is the verified Principal. Otherwise,
+// the user is parsed from the request headers as claimed by the client.
+func getUser(p *restful.Principal, req *http.Request) ClientUser {
+	if p != nil {
+		return ClientUser{
+			Name:  p.Name,
+			Email: p.Email,
+		}
+	}
 	// Maybe we want to check this user isn't empty, eventually.
 	return ClientUser{
 		Name:  req.Header.Get("Sous-User-Name"),
 		Email: req.Header.Get("Sous-User-Email"),
 	}
 }
+
+// authorizeManifestChange returns an error unless p is allowed to change m:
+// that is, p is an admin or is named in m.Owners. If p is nil, the server is
+// not authenticating its clients, and every change is allowed.
+func authorizeManifestChange(p *restful.Principal, m *sous.Manifest) error {
+	if p == nil || p.Admin {
+		return nil
+	}
+	for _, owner := range m.Owners {
+		if p.Matches(owner) {
+			return nil
+		}
+	}
+	return errors.Errorf("%s is not an owner of %q", sous.User{Name: p.Name, Email: p.Email}, m.ID())
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+)
+
+// anyLabels labels every image with no labels at all, so that images Sous
+// didn't build can be deployed.
+type anyLabels struct{}
+
+func (anyLabels) ImageLabels(string) (map[string]string, error) { return map[string]string{}, nil }
+
+func TestAdopt(t *testing.T) {
+	h, err := NewHarness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	st, _ := h.ReadState()
+
+	sid := sous.MustNewSourceID("github.com/example/legacy", "", "1.0.0")
+	image, err := h.PushImage(sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Registry.AddImage("example/mystery", "latest", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make requests by hand, as if Sous had never seen them.
+	agent := singularity.NewRectiAgent(h.NameCache)
+	handMade := func(reqID, image string, agent *singularity.RectiAgent) {
+		d := sous.Deployable{
+			Deployment: &sous.Deployment{
+				SourceID:    sid,
+				ClusterName: h.ClusterName,
+				Cluster:     st.Defs.Clusters[h.ClusterName],
+				Kind:        sous.ManifestKindService,
+				Owners:      sous.NewOwnerSet("tom"),
+				DeployConfig: sous.DeployConfig{
+					NumInstances: 2,
+					Resources:    sous.Resources{"cpus": "1", "memory": "256", "ports": "1"},
+					Env:          sous.Env{"GREETING": "hello"},
+				},
+			},
+			BuildArtifact: sous.NewBuildArtifact(image, nil),
+		}
+		if err := agent.PostRequest(d, reqID); err != nil {
+			t.Fatal(err)
+		}
+		if err := agent.Deploy(d, reqID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handMade("legacy-app", image, agent)
+	handMade("mystery-app", h.Registry.Host()+"/example/mystery:latest", singularity.NewRectiAgent(anyLabels{}))
+
+	adopter := singularity.NewAdopter(h.NameCache, agent)
+	ads, err := adopter.Unmanaged(st.Defs.Clusters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ads) != 2 {
+		t.Fatalf("got %d unmanaged requests; want 2", len(ads))
+	}
+	legacy, mystery := ads[0], ads[1]
+	if mystery.RequestID != "mystery-app" || mystery.Err == nil {
+		t.Errorf("got %q (error %v); want mystery-app, unadoptable for lack of labels", mystery.RequestID, mystery.Err)
+	}
+	if legacy.Err != nil {
+		t.Fatal(legacy.Err)
+	}
+	if !legacy.Deployment.SourceID.Equal(sid) {
+		t.Errorf("got source ID %v; want %v", legacy.Deployment.SourceID, sid)
+	}
+
+	m, err := legacy.Manifest(st.Defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := m.Deployments[h.ClusterName]
+	if spec.NumInstances != 2 || spec.Env["GREETING"] != "hello" || !reflect.DeepEqual(m.Owners, []string{"tom"}) {
+		t.Errorf("got manifest %#v; want the hand-made deploy", m)
+	}
+
+	recorded := false
+	record := func() error {
+		recorded = true
+		h.SetManifest(m)
+		return nil
+	}
+	h.Singularity.DeployState = "FAILED"
+	if err := adopter.Adopt(legacy, record); err == nil {
+		t.Fatal("adopting with a failed deploy should fail")
+	}
+	if recorded {
+		t.Error("an adoption whose deploy failed should not be recorded")
+	}
+	if got, want := h.Singularity.RequestIDs(), []string{"legacy-app", "mystery-app"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got requests %q after a failed deploy; want %q", got, want)
+	}
+	h.Singularity.DeployState = "SUCCEEDED"
+
+	if err := adopter.Adopt(legacy, record); err != nil {
+		t.Fatal(err)
+	}
+	if !recorded {
+		t.Error("the adoption was not recorded")
+	}
+	reqID := singularity.MakeRequestID(legacy.Deployment.ID())
+	if got, want := h.Singularity.RequestIDs(), []string{reqID, "mystery-app"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got requests %q; want %q", got, want)
+	}
+	if img := h.Singularity.Image(reqID); img != image {
+		t.Errorf("got image %q deployed; want %q", img, image)
+	}
+
+	status, err := h.Resolve(10 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Errs.Causes) > 0 {
+		t.Fatalf("resolve errors: %v", status.Errs)
+	}
+	if n := h.Singularity.Deploys(reqID); n != 1 {
+		t.Errorf("resolving the adopted deployment made %d deploys; want none", n-1)
+	}
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/docker_registry"
+	"github.com/opentable/sous/util/fake_registry"
+	"github.com/opentable/sous/util/fake_singularity"
+	"github.com/pkg/errors"
+)
+
+// A Harness runs the resolution cycle of `sous server` - harvesting the
+// registry, then auto-resolving the intended state with the real Singularity
+// deployer - against a fake Singularity and a fake Docker registry, so that
+// whole deploys can be tested without a cluster.
+type Harness struct {
+	// Singularity and Registry are the fakes Sous talks to.
+	Singularity *fake_singularity.Server
+	Registry    *fake_registry.Server
+	// ClusterName is the name of the only cluster in the state, which is
+	// scheduled by Singularity.
+	ClusterName string
+	*docker.NameCache
+	Harvester    *docker.Harvester
+	AutoResolver *sous.AutoResolver
+	state        *sous.State
+	sync.Mutex
+}
+
+var harnessCount struct {
+	n int
+	sync.Mutex
+}
+
+// NewHarness starts the fakes, and builds the components of a Sous server
+// talking to them. Close it when you're done.
+func NewHarness() (*Harness, error) {
+	harnessCount.Lock()
+	harnessCount.n++
+	n := harnessCount.n
+	harnessCount.Unlock()
+
+	h := &Harness{
+		Singularity: fake_singularity.NewServer(),
+		Registry:    fake_registry.NewServer(),
+		ClusterName: "test-cluster",
+	}
+	db, err := docker.GetDatabase(&docker.DBConfig{
+		Driver:     "sqlite3_sous",
+		Connection: docker.InMemoryConnection(fmt.Sprintf("harness%d", n)),
+	})
+	if err != nil {
+		h.Close()
+		return nil, errors.Wrap(err, "opening name cache")
+	}
+	cl := docker_registry.NewClient()
+	cl.BecomeFoolishlyTrusting()
+	h.NameCache = docker.NewNameCache(h.Registry.Host(), cl, db)
+	h.Harvester = docker.NewHarvester(h.NameCache, 0, 1)
+
+	h.state = sous.NewState()
+	h.state.Defs.DockerRepo = h.Registry.Host()
+	h.state.Defs.Clusters = sous.Clusters{
+		h.ClusterName: &sous.Cluster{
+			Name:    h.ClusterName,
+			Kind:    "singularity",
+			BaseURL: h.Singularity.URL,
+		},
+	}
+
+	deployer := singularity.NewDeployer(singularity.NewRectiAgent(h.NameCache))
+	rez := sous.NewResolver(deployer, h.NameCache, &sous.ResolveFilter{})
+	h.AutoResolver = sous.NewAutoResolver(rez, h, sous.SilentLogSet())
+	return h, nil
+}
+
+// Close stops the fakes.
+func (h *Harness) Close() {
+	h.Singularity.Close()
+	h.Registry.Close()
+}
+
+// ReadState implements sous.StateReader, returning a copy of the state the
+// harness resolves.
+func (h *Harness) ReadState() (*sous.State, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.state.Clone(), nil
+}
+
+// PushImage puts an image built from sid into the fake registry, labelled as
+// `sous build` would label it, and returns its canonical name, by which it
+// will be deployed.
+func (h *Harness) PushImage(sid sous.SourceID) (string, error) {
+	repo := strings.TrimPrefix(sid.Location.Repo, "github.com/")
+	if sid.Location.Dir != "" {
+		repo += "/" + sid.Location.Dir
+	}
+	dg, err := h.Registry.AddImage(repo, sid.Version.Format("M.m.p-?"), docker.Labels(sid))
+	if err != nil {
+		return "", err
+	}
+	return h.Registry.Host() + "/" + repo + "@" + dg, nil
+}
+
+// SetManifest adds m to the intended state, replacing any manifest with the
+// same ID.
+func (h *Harness) SetManifest(m *sous.Manifest) {
+	h.Lock()
+	defer h.Unlock()
+	h.state.Manifests.Set(m.ID(), m.Clone())
+}
+
+// Resolve harvests the registry, then runs a single auto-resolve cycle, and
+// returns its status.
+func (h *Harness) Resolve(timeout time.Duration) (*sous.ResolveStatus, error) {
+	if err := h.Harvester.Harvest(); err != nil {
+		return nil, err
+	}
+	if errs := h.Harvester.Status().Errors; len(errs) > 0 {
+		return nil, errors.Errorf("harvesting: %s", strings.Join(errs, "; "))
+	}
+
+	before, _ := h.AutoResolver.Statuses()
+	done := h.AutoResolver.Kickoff()
+	defer close(done)
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-deadline:
+			return nil, errors.Errorf("resolve did not finish within %s", timeout)
+		case <-time.After(10 * time.Millisecond):
+		}
+		if stable, _ := h.AutoResolver.Statuses(); stable != nil && stable != before {
+			return stable, nil
+		}
+	}
+}
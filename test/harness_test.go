@@ -0,0 +1,73 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentable/sous/ext/singularity"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/semv"
+)
+
+func TestHarness_Resolve(t *testing.T) {
+	h, err := NewHarness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	m := buildManifest(h.ClusterName, "github.com/example/app", "1.0.0")
+	dep := m.Deployments[h.ClusterName]
+	dep.NumInstances = 2
+	m.Deployments[h.ClusterName] = dep
+	image, err := h.PushImage(sous.MustNewSourceID("github.com/example/app", "", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetManifest(m)
+
+	status, err := h.Resolve(10 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Errs.Causes) > 0 {
+		t.Fatalf("resolve errors: %v", status.Errs)
+	}
+	reqID := singularity.MakeRequestID(sous.DeployID{ManifestID: m.ID(), Cluster: h.ClusterName})
+	if n, ok := h.Singularity.Instances(reqID); !ok || n != 2 {
+		t.Fatalf("got request %q with %d instances (exists: %t); want 2", reqID, n, ok)
+	}
+	if img := h.Singularity.Image(reqID); img != image {
+		t.Errorf("got image %q deployed; want %q", img, image)
+	}
+
+	dep.NumInstances = 3
+	m.Deployments[h.ClusterName] = dep
+	h.SetManifest(m)
+	if _, err := h.Resolve(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := h.Singularity.Instances(reqID); n != 3 {
+		t.Errorf("got %d instances after scaling; want 3", n)
+	}
+	if n := h.Singularity.Deploys(reqID); n != 1 {
+		t.Errorf("scaling made %d deploys; want 1", n)
+	}
+
+	upgraded, err := h.PushImage(sous.MustNewSourceID("github.com/example/app", "", "1.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep.Version = semv.MustParse("1.1.0")
+	m.Deployments[h.ClusterName] = dep
+	h.SetManifest(m)
+	if _, err := h.Resolve(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if n := h.Singularity.Deploys(reqID); n != 2 {
+		t.Errorf("got %d deploys after upgrading; want 2", n)
+	}
+	if img := h.Singularity.Image(reqID); img != upgraded {
+		t.Errorf("got image %q deployed after upgrading; want %q", img, upgraded)
+	}
+}
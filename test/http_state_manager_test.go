@@ -35,6 +35,40 @@ func buildManifest(cluster, repo, version string) *sous.Manifest {
 	}
 }
 
+// newTestServer returns a Sous server storing its state in sm, and an
+// HTTPStateManager for it.
+func newTestServer(t *testing.T, sm *sous.DummyStateManager) (*sous.HTTPStateManager, *httptest.Server) {
+	di := psyringe.New()
+	di.Add(sous.NewLogSet(os.Stderr, os.Stderr, os.Stderr))
+	//di.Add(sous.NewLogSet(os.Stderr, ioutil.Discard, ioutil.Discard))
+	graph.AddInternals(di)
+	di.Add(
+		func() graph.StateReader { return graph.StateReader{StateReader: sm} },
+		func() graph.StateWriter { return graph.StateWriter{StateWriter: sm} },
+	)
+	di.Add(&config.Verbosity{})
+	di.Add(graph.LocalSousConfig{Config: &config.Config{}})
+
+	gf := func() restful.Injector {
+		cdi := di.Clone()
+		server.AddsPerRequest(cdi)
+		return cdi
+	}
+
+	router, err := server.SousRouteMap.BuildRouter(gf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testServer := httptest.NewServer(router)
+
+	cl, err := sous.NewClient(testServer.URL)
+	if err != nil {
+		testServer.Close()
+		t.Fatal(err)
+	}
+	return sous.NewHTTPStateManager(cl), testServer
+}
+
 func TestWriteState(t *testing.T) {
 	steadyManifest := buildManifest("test-cluster", "github.com/opentable/steady", "1.2.3")
 	diesManifest := buildManifest("test-cluster", "github.com/opentable/dies", "133.56.987431")
@@ -63,31 +97,9 @@ func TestWriteState(t *testing.T) {
 		t.Fatal("State manager double is empty")
 	}
 
-	di := psyringe.New()
-	di.Add(sous.NewLogSet(os.Stderr, os.Stderr, os.Stderr))
-	//di.Add(sous.NewLogSet(os.Stderr, ioutil.Discard, ioutil.Discard))
-	graph.AddInternals(di)
-	di.Add(
-		func() graph.StateReader { return graph.StateReader{StateReader: &sm} },
-		func() graph.StateWriter { return graph.StateWriter{StateWriter: &sm} },
-	)
-	di.Add(&config.Verbosity{})
-
-	gf := func() restful.Injector {
-		cdi := di.Clone()
-		server.AddsPerRequest(cdi)
-		return cdi
-	}
-
-	testServer := httptest.NewServer(server.SousRouteMap.BuildRouter(gf))
+	hsm, testServer := newTestServer(t, &sm)
 	defer testServer.Close()
 
-	cl, err := sous.NewClient(testServer.URL)
-	if err != nil {
-		t.Fatal(err)
-	}
-	hsm := sous.NewHTTPStateManager(cl)
-
 	originalState, err := hsm.ReadState()
 	if err != nil {
 		t.Fatal(err)
@@ -155,3 +167,50 @@ func TestWriteState(t *testing.T) {
 		t.Errorf("Server's version of changed state was %q; want %q", actualVersion, expectedVersion)
 	}
 }
+
+func TestWriteStateDefaults(t *testing.T) {
+	m := buildManifest("test-cluster", "github.com/opentable/defaults", "1.0.0")
+	m.Deployments["other-cluster"] = m.Deployments["test-cluster"].Clone()
+	m.Defaults = sous.DeployConfig{Resources: sous.Resources{"memory": "256"}}
+	for cluster, spec := range m.Deployments {
+		delete(spec.Resources, "memory")
+		m.Deployments[cluster] = spec
+	}
+
+	state := &sous.State{}
+	state.Defs.Clusters = sous.Clusters{
+		"test-cluster":  &sous.Cluster{Name: "test-cluster"},
+		"other-cluster": &sous.Cluster{Name: "other-cluster"},
+	}
+	state.Manifests = sous.NewManifests(m)
+
+	sm := sous.DummyStateManager{State: state}
+	hsm, testServer := newTestServer(t, &sm)
+	defer testServer.Close()
+
+	if _, err := hsm.ReadState(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := m.Clone()
+	changed.Defaults.Resources = sous.Resources{"memory": "512"}
+	local := state.Clone()
+	local.Manifests.Set(changed.ID(), changed)
+
+	if err := hsm.WriteState(local, sous.User{Name: "Test User"}); err != nil {
+		t.Fatalf("Failed to write state: %+v", err)
+	}
+
+	stored, there := sm.State.Manifests.Get(m.ID())
+	if !there {
+		t.Fatalf("Manifest %q missing from server's state", m.ID())
+	}
+	if mem := stored.Defaults.Resources["memory"]; mem != "512" {
+		t.Errorf("Server's Defaults memory was %q; want %q", mem, "512")
+	}
+	for cluster, spec := range stored.Deployments {
+		if mem, set := spec.Resources["memory"]; set {
+			t.Errorf("Server's deployment to %q overrides Defaults memory with %q", cluster, mem)
+		}
+	}
+}
@@ -38,6 +38,11 @@ type (
 		// apply to this command.
 		AddFlags(*flag.FlagSet)
 	}
+	// Hider means this command may be hidden. Hidden commands can be invoked
+	// like any other, but aren't listed in help or offered by completion.
+	Hider interface {
+		Hidden() bool
+	}
 )
 
 // SortedKeys returns the names of the commands in alphabetical order.
@@ -51,3 +56,9 @@ func (cs Commands) SortedKeys() []string {
 	sort.Strings(keys)
 	return keys
 }
+
+// isHidden returns true if cmd is a Hider which is hidden.
+func isHidden(cmd Command) bool {
+	h, ok := cmd.(Hider)
+	return ok && h.Hidden()
+}
@@ -0,0 +1,222 @@
+package cmdr
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+type (
+	// completionNode is a command in the command tree, as shell completion
+	// sees it.
+	completionNode struct {
+		// path is the names of the subcommands leading to this command from
+		// the root, separated by spaces.
+		path        string
+		subcommands []string
+		flags       []*flag.Flag
+	}
+
+	boolFlag interface {
+		IsBoolFlag() bool
+	}
+)
+
+// CompletionShells are the shells CompletionScript writes scripts for.
+var CompletionShells = []string{"bash", "fish", "zsh"}
+
+// CompletionScript returns a script which teaches the shell named to complete the
+// commands and flags of the program name. The script is generated from the
+// command tree under c.Root, so needs regenerating when that changes.
+//
+// Values of flags are completed by invoking "name valuesCommand <flag>",
+// which should print the values the flag named might take, one per line.
+// Leave valuesCommand empty to complete only commands and flags.
+func (c *CLI) CompletionScript(shell, name, valuesCommand string) (string, error) {
+	nodes := c.completionTree()
+	switch shell {
+	default:
+		return "", UsageErrorf("can't complete for %q shells, only %s",
+			shell, strings.Join(CompletionShells, ", "))
+	case "bash":
+		return bashCompletion(nodes, name, valuesCommand), nil
+	case "zsh":
+		return "# zsh completion for " + name + ", via its bash completion\n" +
+			"autoload -U +X bashcompinit && bashcompinit\n\n" +
+			bashCompletion(nodes, name, valuesCommand), nil
+	case "fish":
+		return fishCompletion(nodes, name, valuesCommand), nil
+	}
+}
+
+// completionTree walks the command tree from c.Root, collecting each
+// command's subcommands, and the flags it parses: the global flags, and those
+// of it and its ancestors.
+func (c *CLI) completionTree() []completionNode {
+	nodes := []completionNode{}
+	var walk func(path string, cmd Command, flagAddFuncs []func(*flag.FlagSet))
+	walk = func(path string, cmd Command, flagAddFuncs []func(*flag.FlagSet)) {
+		if cmdWithFlags, ok := cmd.(AddsFlags); ok {
+			flagAddFuncs = append(flagAddFuncs[:len(flagAddFuncs):len(flagAddFuncs)], cmdWithFlags.AddFlags)
+		}
+		n := completionNode{path: path}
+		fs := flag.NewFlagSet(path, flag.ContinueOnError)
+		for _, addFlags := range c.GlobalFlagSetFuncs {
+			addFlags(fs)
+		}
+		for _, addFlags := range flagAddFuncs {
+			addFlags(fs)
+		}
+		// VisitAll visits the flags in lexical order.
+		fs.VisitAll(func(f *flag.Flag) { n.flags = append(n.flags, f) })
+
+		var subcommands Commands
+		if cmdWithSubcmd, ok := cmd.(Subcommander); ok {
+			subcommands = cmdWithSubcmd.Subcommands()
+		}
+		for _, name := range subcommands.SortedKeys() {
+			if !isHidden(subcommands[name]) {
+				n.subcommands = append(n.subcommands, name)
+			}
+		}
+		nodes = append(nodes, n)
+		for _, name := range n.subcommands {
+			walk(strings.TrimSpace(path+" "+name), subcommands[name], flagAddFuncs)
+		}
+	}
+	walk("", c.Root, nil)
+	return nodes
+}
+
+// takesValue returns true unless f is a boolean flag.
+func takesValue(f *flag.Flag) bool {
+	b, ok := f.Value.(boolFlag)
+	return !ok || !b.IsBoolFlag()
+}
+
+func (n completionNode) flagNames(valuesOnly bool) []string {
+	names := []string{}
+	for _, f := range n.flags {
+		if !valuesOnly || takesValue(f) {
+			names = append(names, "-"+f.Name)
+		}
+	}
+	return names
+}
+
+// shellFuncName makes name safe for use in shell function names.
+func shellFuncName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// bashCase writes a shell function which echoes the words list returns for
+// each node's path.
+func bashCase(b *bytes.Buffer, fn string, nodes []completionNode, list func(completionNode) []string) {
+	fmt.Fprintf(b, "%s() {\n  case \"$1\" in\n", fn)
+	for _, n := range nodes {
+		if words := list(n); len(words) != 0 {
+			fmt.Fprintf(b, "    %q) echo %q ;;\n", n.path, strings.Join(words, " "))
+		}
+	}
+	b.WriteString("  esac\n}\n\n")
+}
+
+func bashCompletion(nodes []completionNode, name, valuesCommand string) string {
+	fn := "_" + shellFuncName(name)
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "# bash completion for %s, generated from its command tree\n\n", name)
+	bashCase(b, fn+"_subcommands", nodes, func(n completionNode) []string { return n.subcommands })
+	bashCase(b, fn+"_flags", nodes, func(n completionNode) []string { return n.flagNames(false) })
+	bashCase(b, fn+"_value_flags", nodes, func(n completionNode) []string { return n.flagNames(true) })
+
+	values := "  return"
+	if valuesCommand != "" {
+		values = fmt.Sprintf(`  COMPREPLY=( $(compgen -W "$(%s %s "${prev#-}" 2>/dev/null)" -- "$cur") )
+  return`, name, valuesCommand)
+	}
+	fmt.Fprintf(b, `%[1]s() {
+  local cur prev cmdpath w i
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  prev="${COMP_WORDS[COMP_CWORD-1]}"
+  cmdpath=""
+  for ((i=1; i<COMP_CWORD; i++)); do
+    w="${COMP_WORDS[i]}"
+    case " $(%[1]s_subcommands "$cmdpath") " in
+      *" $w "*) cmdpath="${cmdpath:+$cmdpath }$w" ;;
+      *) break ;;
+    esac
+  done
+  COMPREPLY=()
+  if [[ " $(%[1]s_value_flags "$cmdpath") " == *" $prev "* ]]; then
+%[3]s
+  fi
+  if [[ $cur == -* ]]; then
+    COMPREPLY=( $(compgen -W "$(%[1]s_flags "$cmdpath")" -- "$cur") )
+  else
+    COMPREPLY=( $(compgen -W "$(%[1]s_subcommands "$cmdpath")" -- "$cur") )
+  fi
+}
+
+complete -F %[1]s %[2]s
+`, fn, name, values)
+	return b.String()
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func fishCompletion(nodes []completionNode, name, valuesCommand string) string {
+	fn := "__" + shellFuncName(name)
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "# fish completion for %s, generated from its command tree\n\n", name)
+	fmt.Fprintf(b, "function %s_subcommands\n  switch \"$argv[1]\"\n", fn)
+	for _, n := range nodes {
+		if len(n.subcommands) != 0 {
+			fmt.Fprintf(b, "    case %s\n      echo %s\n", fishQuote(n.path), strings.Join(n.subcommands, " "))
+		}
+	}
+	fmt.Fprintf(b, `  end
+end
+
+function %[1]s_at
+  set -l cmdpath ""
+  for w in (commandline -opc)[2..-1]
+    if contains -- $w (string split " " (%[1]s_subcommands "$cmdpath"))
+      set cmdpath (string trim "$cmdpath $w")
+    else
+      break
+    end
+  end
+  test "$cmdpath" = "$argv[1]"
+end
+
+complete -c %[2]s -f
+`, fn, name)
+
+	for _, n := range nodes {
+		at := fishQuote(fn + "_at " + fishQuote(n.path))
+		if len(n.subcommands) != 0 {
+			fmt.Fprintf(b, "complete -c %s -n %s -a %s\n", name, at, fishQuote(strings.Join(n.subcommands, " ")))
+		}
+		for _, f := range n.flags {
+			fmt.Fprintf(b, "complete -c %s -n %s -o %s -d %s", name, at, f.Name, fishQuote(strings.Join(strings.Fields(f.Usage), " ")))
+			if takesValue(f) {
+				b.WriteString(" -r")
+				if valuesCommand != "" {
+					fmt.Fprintf(b, " -a %s", fishQuote(fmt.Sprintf("(%s %s %s 2>/dev/null)", name, valuesCommand, f.Name)))
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package cmdr
+
+import (
+	"flag"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type (
+	completionRoot   struct{ verbose bool }
+	completionGet    struct{ cluster, repo string }
+	completionHidden struct{}
+)
+
+func (*completionRoot) Help() string { return "root" }
+func (*completionRoot) Subcommands() Commands {
+	return Commands{"get": &completionGet{}, "__values": &completionHidden{}}
+}
+func (c *completionRoot) AddFlags(fs *flag.FlagSet) { fs.BoolVar(&c.verbose, "v", false, "loud") }
+
+func (*completionGet) Help() string            { return "get" }
+func (*completionGet) Execute([]string) Result { return Success() }
+func (c *completionGet) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.cluster, "cluster", "", "the cluster's name")
+	fs.StringVar(&c.repo, "repo", "", "the repo")
+}
+
+func (*completionHidden) Help() string            { return "values" }
+func (*completionHidden) Execute([]string) Result { return Success() }
+func (*completionHidden) Hidden() bool            { return true }
+
+func TestCompletionTree(t *testing.T) {
+	c := &CLI{Root: &completionRoot{}}
+	nodes := c.completionTree()
+	if len(nodes) != 2 {
+		t.Fatalf("got %d commands; want 2, without the hidden one", len(nodes))
+	}
+	root, get := nodes[0], nodes[1]
+	if !reflect.DeepEqual(root.subcommands, []string{"get"}) {
+		t.Errorf("got subcommands %q; want only get", root.subcommands)
+	}
+	if get.path != "get" {
+		t.Errorf("got path %q; want get", get.path)
+	}
+	if got, want := get.flagNames(false), []string{"-cluster", "-repo", "-v"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got flags %q; want %q", got, want)
+	}
+	if got, want := get.flagNames(true), []string{"-cluster", "-repo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got value flags %q; want %q", got, want)
+	}
+
+	help, err := c.Help(c.Root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(help, "__values") {
+		t.Errorf("hidden command listed in help:\n%s", help)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	c := &CLI{Root: &completionRoot{}}
+	if _, err := c.CompletionScript("csh", "prog", "__values"); err == nil {
+		t.Error("expected an error for csh")
+	}
+
+	fish, err := c.CompletionScript("fish", "prog", "__values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `complete -c prog -n '__prog_at \'get\'' -o cluster -d 'the cluster\'s name' -r -a '(prog __values cluster 2>/dev/null)'`
+	if !strings.Contains(fish, want) {
+		t.Errorf("fish script lacks\n%s\ngot:\n%s", want, fish)
+	}
+
+	script, err := c.CompletionScript("bash", "prog", "__values")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("no bash to run the script with")
+	}
+	complete := func(line string) string {
+		words := strings.Split(line, " ")
+		// prog stands in for the program, printing values for any flag.
+		src := script + `
+prog() { echo "one-$2 two-$2"; }
+COMP_WORDS=(` + line + `)
+COMP_CWORD=` + strconv.Itoa(len(words)-1) + `
+_prog
+echo "${COMPREPLY[@]}"
+`
+		out, err := exec.Command(bash, "-c", src).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %s", err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	for line, want := range map[string]string{
+		`prog ""`:                     "get",
+		`prog g`:                      "get",
+		`prog get -`:                  "-cluster -repo -v",
+		`prog get -cluster ""`:        "one-cluster two-cluster",
+		`prog get -v -repo t`:         "two-repo",
+		`prog -`:                      "-v",
+		`prog get -cluster x -r`:      "-repo",
+		`prog get -cluster x ""`:      "",
+		`prog get -cluster x -repo o`: "one-repo",
+	} {
+		if got := complete(line); got != want {
+			t.Errorf("completing %s: got %q; want %q", line, got, want)
+		}
+	}
+}
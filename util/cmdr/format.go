@@ -0,0 +1,95 @@
+package cmdr
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"text/template"
+
+	"github.com/opentable/sous/util/yaml"
+)
+
+// Output formats understood by Formatter. Any other format is taken to be a
+// Go template.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// A Formatter renders the data a command outputs in the format chosen with
+// its -format flag: a table for people to read, which is the default, JSON
+// or YAML for scripts, or a Go template. A template is executed with each
+// element of the data in turn if the data is a slice, and otherwise with the
+// data as a whole, and a newline is written after each execution.
+//
+// Commands embed a Formatter, add its flag in their AddFlags, and return
+// the Result of their data.
+type Formatter struct {
+	Format string
+}
+
+// AddFormatFlag adds the -format flag to fs.
+func (f *Formatter) AddFormatFlag(fs *flag.FlagSet) {
+	fs.StringVar(&f.Format, "format", FormatTable,
+		"output format: table, json, yaml, or a Go template, e.g. '{{.Repo}}'")
+}
+
+// Result renders v in the chosen format. If the format is table, table is
+// called to write v as a table.
+func (f *Formatter) Result(v interface{}, table func(io.Writer) error) Result {
+	buf := &bytes.Buffer{}
+	if err := f.Render(buf, v, table); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return SuccessData(buf.Bytes())
+}
+
+// Render writes v to w in the chosen format. If the format is table, table
+// is called to write v as a table.
+func (f *Formatter) Render(w io.Writer, v interface{}, table func(io.Writer) error) error {
+	switch f.Format {
+	case "", FormatTable:
+		return table(w)
+	case FormatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return InternalErrorf("unable to marshal JSON: %s", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	case FormatYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return InternalErrorf("unable to marshal YAML: %s", err)
+		}
+		_, err = w.Write(b)
+		return err
+	}
+
+	tmpl, err := template.New("format").Parse(f.Format)
+	if err != nil {
+		return UsageErrorf("-format is not table, json, yaml, or a valid template: %s", err)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return execLine(w, tmpl, v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := execLine(w, tmpl, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execLine(w io.Writer, tmpl *template.Template, v interface{}) error {
+	if err := tmpl.Execute(w, v); err != nil {
+		return UsageErrorf("executing -format template: %s", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
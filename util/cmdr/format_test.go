@@ -0,0 +1,51 @@
+package cmdr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestFormatter(t *testing.T) {
+	type row struct{ Name, Value string }
+	rows := []row{{"a", "1"}, {"b", "2"}}
+	table := func(w io.Writer) error {
+		for _, r := range rows {
+			fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Value)
+		}
+		return nil
+	}
+
+	for format, want := range map[string]string{
+		"":                     "a\t1\nb\t2\n",
+		FormatTable:            "a\t1\nb\t2\n",
+		FormatJSON:             "[\n  {\n    \"Name\": \"a\",\n    \"Value\": \"1\"\n  },\n  {\n    \"Name\": \"b\",\n    \"Value\": \"2\"\n  }\n]\n",
+		FormatYAML:             "- Name: a\n  Value: \"1\"\n- Name: b\n  Value: \"2\"\n",
+		"{{.Name}}={{.Value}}": "a=1\nb=2\n",
+	} {
+		f := Formatter{Format: format}
+		res, ok := f.Result(rows, table).(SuccessResult)
+		if !ok {
+			t.Errorf("format %q: got %v; want success", format, f.Result(rows, table))
+			continue
+		}
+		if got := string(res.Data); got != want {
+			t.Errorf("format %q: got %q; want %q", format, got, want)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	f := Formatter{Format: "{{.Name}}"}
+	if err := f.Render(buf, row{"c", "3"}, table); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "c\n" {
+		t.Errorf("got %q; want a template to execute once with a value which isn't a slice", buf.String())
+	}
+
+	f = Formatter{Format: "{{.Name"}
+	if res := f.Result(rows, table); res.ExitCode() != EX_USAGE {
+		t.Errorf("got exit code %d for an invalid template; want %d", res.ExitCode(), EX_USAGE)
+	}
+}
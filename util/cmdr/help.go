@@ -37,6 +37,9 @@ func formatSubcommands(c Command) string {
 	subCmds := subcommander.Subcommands()
 	b.WriteString("\n\nsubcommands:\n")
 	for _, name := range subCmds.SortedKeys() {
+		if isHidden(subCmds[name]) {
+			continue
+		}
 		var shortHelp string
 		splitHelp := strings.Split(subCmds[name].Help(), "\n")
 		if len(splitHelp) > 0 {
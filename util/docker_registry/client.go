@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -58,6 +59,11 @@ type (
 		LabelsForImageName(string) (map[string]string, error)
 		GetImageMetadata(imageName, etag string) (Metadata, error)
 		AllTags(repoName string) ([]string, error)
+		Repositories(regHost string) ([]string, error)
+		DeleteImage(imageName string) error
+		CopyImage(imageName, toHost string) (string, error)
+		PushOCILayout(dir string, labels map[string]string, names ...string) (string, error)
+		PushArchive(path string, labels map[string]string, names ...string) (string, error)
 		Cancel()
 		BecomeFoolishlyTrusting()
 	}
@@ -69,6 +75,9 @@ type (
 		Etag          string
 		CanonicalName string
 		AllNames      []string
+		// Digest is the digest of the image manifest, which identifies the
+		// image immutably.
+		Digest string
 	}
 )
 
@@ -171,6 +180,57 @@ func (c *liveClient) AllTags(repoName string) ([]string, error) {
 	return rep.getRepoTags(ref)
 }
 
+// catalogPageSize is the number of repositories requested from a registry
+// catalog at a time.
+const catalogPageSize = 100
+
+// Repositories returns the names of all the repositories in the catalog of a
+// registry, without the registry hostname.
+func (c *liveClient) Repositories(regHost string) ([]string, error) {
+	reg, err := NewRegistryOld(c.ctx, fmt.Sprintf("https://%s", regHost), c.xport)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	entries := make([]string, catalogPageSize)
+	last := ""
+	for {
+		n, err := reg.Repositories(c.ctx, entries, last)
+		repos = append(repos, entries[:n]...)
+		if err == io.EOF || (err == nil && n == 0) {
+			return repos, nil
+		}
+		if err != nil {
+			return repos, err
+		}
+		last = entries[n-1]
+	}
+}
+
+// DeleteImage deletes the manifest of an image from its registry, along with
+// every tag referring to it. If imageName is tagged rather than digested, the
+// manifest the tag currently refers to is deleted.
+func (c *liveClient) DeleteImage(imageName string) error {
+	regHost, ref, err := splitHost(imageName)
+	if err != nil {
+		return err
+	}
+	if _, ok := ref.(reference.Digested); !ok {
+		md, err := c.metadataForImage(regHost, ref, "")
+		if err != nil {
+			return err
+		}
+		if ref, err = digestRef(ref, md.Digest); err != nil {
+			return err
+		}
+	}
+	rep, err := c.registryForHostname(regHost)
+	if err != nil {
+		return err
+	}
+	return rep.deleteManifest(ref)
+}
+
 func splitHost(in string) (url string, ref reference.Named, err error) {
 	ref, err = reference.ParseNamed(in)
 	if err != nil {
@@ -283,7 +343,8 @@ func (c *liveClient) metadataForImage(regHost string, ref reference.Named, etag
 	}
 	md.AllNames[0] = ref.String()
 
-	md.CanonicalName = ref.Name() + "@" + dg.String()
+	md.Digest = dg.String()
+	md.CanonicalName = ref.Name() + "@" + md.Digest
 	md.AllNames[1] = md.CanonicalName
 
 	switch mani := mani.(type) {
@@ -456,6 +517,30 @@ func (r *registry) getManifestWithEtag(ctx context.Context, ref reference.Named,
 	return
 }
 
+func (r *registry) deleteManifest(ref reference.Named) error {
+	u, err := r.ub.BuildManifestURL(ref)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	defer safeCloseBody(resp)
+
+	if err != nil {
+		return err
+	}
+
+	if !client.SuccessStatus(resp.StatusCode) {
+		return client.HandleErrorResponse(resp)
+	}
+	return nil
+}
+
 func safeCloseBody(r *http.Response) {
 	defer func() { recover() }()
 	r.Body.Close()
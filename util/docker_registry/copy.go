@@ -0,0 +1,196 @@
+package docker_registry
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/client"
+	"github.com/pkg/errors"
+)
+
+// CopyImage copies an image - its manifest and the blobs the manifest refers
+// to - into the registry at toHost, under the same repository name. Blobs
+// already in that registry aren't copied again. It returns the name of the
+// copy, referring to it by digest.
+func (c *liveClient) CopyImage(imageName, toHost string) (string, error) {
+	fromHost, ref, err := splitHost(imageName)
+	if err != nil {
+		return "", err
+	}
+	from, err := c.registryForHostname(fromHost)
+	if err != nil {
+		return "", err
+	}
+	to, err := c.registryForHostname(toHost)
+	if err != nil {
+		return "", err
+	}
+
+	mani, dg, _, err := from.getManifestWithEtag(c.ctx, ref, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "getting manifest of %s", imageName)
+	}
+	name, err := reference.ParseNamed(ref.Name())
+	if err != nil {
+		return "", err
+	}
+	for _, desc := range blobsOf(mani) {
+		if err := copyBlob(from, to, name, desc.Digest); err != nil {
+			return "", errors.Wrapf(err, "copying blob %s of %s to %s", desc.Digest, imageName, toHost)
+		}
+	}
+
+	mediaType, payload, err := mani.Payload()
+	if err != nil {
+		return "", err
+	}
+	copied, err := reference.WithDigest(name, dg)
+	if err != nil {
+		return "", err
+	}
+	if err := to.putManifest(copied, mediaType, payload); err != nil {
+		return "", errors.Wrapf(err, "putting manifest of %s to %s", imageName, toHost)
+	}
+	if tagged, ok := ref.(reference.Tagged); ok {
+		tag, err := reference.WithTag(name, tagged.Tag())
+		if err != nil {
+			return "", err
+		}
+		if err := to.putManifest(tag, mediaType, payload); err != nil {
+			return "", errors.Wrapf(err, "tagging %s in %s", imageName, toHost)
+		}
+	}
+
+	named, err := joinHost(toHost, copied)
+	if err != nil {
+		return "", err
+	}
+	return named.String(), nil
+}
+
+// blobsOf returns the blobs mani refers to. The References of a schema 2
+// manifest are only its layers, so its config is added.
+func blobsOf(mani distribution.Manifest) []distribution.Descriptor {
+	blobs := mani.References()
+	if m, ok := mani.(*schema2.DeserializedManifest); ok {
+		blobs = append([]distribution.Descriptor{m.Config}, blobs...)
+	}
+	return blobs
+}
+
+func copyBlob(from, to *registry, name reference.Named, dg digest.Digest) error {
+	ref, err := reference.WithDigest(name, dg)
+	if err != nil {
+		return err
+	}
+	exists, err := to.blobExists(ref)
+	if err != nil || exists {
+		return err
+	}
+
+	u, err := from.ub.BuildBlobURL(ref)
+	if err != nil {
+		return err
+	}
+	resp, err := from.client.Get(u)
+	defer safeCloseBody(resp)
+	if err != nil {
+		return err
+	}
+	if !client.SuccessStatus(resp.StatusCode) {
+		return client.HandleErrorResponse(resp)
+	}
+	return to.uploadBlob(ref, resp.Body, resp.ContentLength)
+}
+
+func (r *registry) blobExists(ref reference.Canonical) (bool, error) {
+	u, err := r.ub.BuildBlobURL(ref)
+	if err != nil {
+		return false, err
+	}
+	resp, err := r.client.Head(u)
+	defer safeCloseBody(resp)
+	if err != nil {
+		return false, err
+	}
+	if client.SuccessStatus(resp.StatusCode) {
+		return true, nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return false, client.HandleErrorResponse(resp)
+}
+
+// uploadBlob uploads a blob in a single request, as a monolithic upload.
+func (r *registry) uploadBlob(ref reference.Canonical, body io.Reader, length int64) error {
+	u, err := r.ub.BuildBlobUploadURL(ref)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Post(u, "", nil)
+	safeCloseBody(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return client.HandleErrorResponse(resp)
+	}
+
+	location, err := sanitizeLocation(resp.Header.Get("Location"), u)
+	if err != nil {
+		return err
+	}
+	lu, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+	q := lu.Query()
+	q.Set("digest", ref.Digest().String())
+	lu.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("PUT", lu.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.ContentLength = length
+
+	resp, err = r.client.Do(req)
+	defer safeCloseBody(resp)
+	if err != nil {
+		return err
+	}
+	if !client.SuccessStatus(resp.StatusCode) {
+		return client.HandleErrorResponse(resp)
+	}
+	return nil
+}
+
+func (r *registry) putManifest(ref reference.Named, mediaType string, payload []byte) error {
+	u, err := r.ub.BuildManifestURL(ref)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PUT", u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := r.client.Do(req)
+	defer safeCloseBody(resp)
+	if err != nil {
+		return err
+	}
+	if !client.SuccessStatus(resp.StatusCode) {
+		return client.HandleErrorResponse(resp)
+	}
+	return nil
+}
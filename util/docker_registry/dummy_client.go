@@ -3,6 +3,7 @@ package docker_registry
 import (
 	"fmt"
 	"regexp"
+	"sync"
 
 	"github.com/pkg/errors"
 )
@@ -24,10 +25,13 @@ type (
 	// DummyRegistryClient is a type for use in testing - it supports the Client
 	// interface, while only returning metadata that are fed to it
 	DummyRegistryClient struct {
-		mds []matcher
-		ts  []matcher
+		mds   []matcher
+		ts    []matcher
+		repos []string
 
-		calls []call
+		deleted []string
+		calls   []call
+		sync.Mutex
 	}
 )
 
@@ -89,7 +93,7 @@ func (drc *DummyRegistryClient) GetImageMetadata(in, et string) (md Metadata, er
 	call := call{method: "GetImageMetadata", args: valList{in, et}}
 	defer func() {
 		call.res = valList{md, err}
-		drc.calls = append(drc.calls, call)
+		drc.record(call)
 	}()
 
 	m := findMatch(in, drc.mds)
@@ -106,7 +110,7 @@ func (drc *DummyRegistryClient) AllTags(rn string) (tags []string, err error) {
 	call := call{method: "AllTags", args: valList{rn}}
 	defer func() {
 		call.res = valList{tags, err}
-		drc.calls = append(drc.calls, call)
+		drc.record(call)
 	}()
 
 	m := findMatch(rn, drc.ts)
@@ -117,12 +121,95 @@ func (drc *DummyRegistryClient) AllTags(rn string) (tags []string, err error) {
 	return
 }
 
+// Repositories fulfills part of Client
+func (drc *DummyRegistryClient) Repositories(regHost string) (repos []string, err error) {
+	call := call{method: "Repositories", args: valList{regHost}}
+	defer func() {
+		call.res = valList{repos, err}
+		drc.record(call)
+	}()
+
+	repos = drc.repos
+	return
+}
+
+// DeleteImage fulfills part of Client
+func (drc *DummyRegistryClient) DeleteImage(in string) (err error) {
+	call := call{method: "DeleteImage", args: valList{in}}
+	defer func() {
+		call.res = valList{err}
+		drc.record(call)
+	}()
+	drc.Lock()
+	defer drc.Unlock()
+	drc.deleted = append(drc.deleted, in)
+	return
+}
+
+// CopyImage fulfills part of Client. It reports the copy as having the same
+// name as the original, in the registry at toHost.
+func (drc *DummyRegistryClient) CopyImage(in, toHost string) (copied string, err error) {
+	call := call{method: "CopyImage", args: valList{in, toHost}}
+	defer func() {
+		call.res = valList{copied, err}
+		drc.record(call)
+	}()
+
+	_, ref, err := splitHost(in)
+	if err != nil {
+		return
+	}
+	named, err := joinHost(toHost, ref)
+	if err != nil {
+		return
+	}
+	copied = named.String()
+	return
+}
+
+// PushOCILayout fulfills part of Client. It reports the image as pushed by
+// the first name given.
+func (drc *DummyRegistryClient) PushOCILayout(dir string, labels map[string]string, names ...string) (pushed string, err error) {
+	call := call{method: "PushOCILayout", args: valList{dir, labels, names}}
+	defer func() {
+		call.res = valList{pushed, err}
+		drc.record(call)
+	}()
+	return firstName(names)
+}
+
+// PushArchive fulfills part of Client. It reports the image as pushed by
+// the first name given.
+func (drc *DummyRegistryClient) PushArchive(path string, labels map[string]string, names ...string) (pushed string, err error) {
+	call := call{method: "PushArchive", args: valList{path, labels, names}}
+	defer func() {
+		call.res = valList{pushed, err}
+		drc.record(call)
+	}()
+	return firstName(names)
+}
+
+func firstName(names []string) (string, error) {
+	if len(names) == 0 {
+		return "", errors.New("no name to push the image as")
+	}
+	return names[0], nil
+}
+
+// Deleted returns the names of the images deleted from the
+// DummyRegistryClient, in order.
+func (drc *DummyRegistryClient) Deleted() []string {
+	drc.Lock()
+	defer drc.Unlock()
+	return append([]string{}, drc.deleted...)
+}
+
 // LabelsForImageName fulfills part of Client
 func (drc *DummyRegistryClient) LabelsForImageName(in string) (labels map[string]string, err error) {
 	call := call{method: "LabelsForImageName", args: valList{in}}
 	defer func() {
 		call.res = valList{labels, err}
-		drc.calls = append(drc.calls, call)
+		drc.record(call)
 	}()
 
 	md, err := drc.GetImageMetadata(in, "")
@@ -134,8 +221,16 @@ func (drc *DummyRegistryClient) LabelsForImageName(in string) (labels map[string
 	return
 }
 
+func (drc *DummyRegistryClient) record(c call) {
+	drc.Lock()
+	defer drc.Unlock()
+	drc.calls = append(drc.calls, c)
+}
+
 // CallsTo returns a filtered list of the calls to this spy: those that were made to the named method
 func (drc *DummyRegistryClient) CallsTo(name string) []call {
+	drc.Lock()
+	defer drc.Unlock()
 	calls := []call{}
 	for _, c := range drc.calls {
 		if c.method == name {
@@ -170,3 +265,9 @@ func (drc *DummyRegistryClient) FeedMetadata(md Metadata) {
 func (drc *DummyRegistryClient) FeedTags(ts []string) {
 	drc.AddTag(`.*`, ts)
 }
+
+// FeedRepositories sets the repositories the DummyRegistryClient reports in
+// its catalog.
+func (drc *DummyRegistryClient) FeedRepositories(repos []string) {
+	drc.repos = repos
+}
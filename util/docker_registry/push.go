@@ -0,0 +1,413 @@
+package docker_registry
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A localImage is an image held in files: its config, and its layers,
+	// each compressed with gzip.
+	localImage struct {
+		config []byte
+		layers []localLayer
+		// scratch is a temporary directory holding files made while reading
+		// the image, if any, to be removed when it has been pushed.
+		scratch string
+	}
+
+	localLayer struct {
+		path   string
+		digest digest.Digest
+		size   int64
+	}
+
+	// ociDescriptor is a content descriptor in the OCI image spec.
+	ociDescriptor struct {
+		MediaType string        `json:"mediaType"`
+		Digest    digest.Digest `json:"digest"`
+		Size      int64         `json:"size"`
+	}
+
+	// archiveManifest is an entry in the manifest.json of a `docker save`
+	// tarball.
+	archiveManifest struct {
+		Config string
+		Layers []string
+	}
+)
+
+// Media types of the OCI image spec.
+const (
+	ociIndex     = "application/vnd.oci.image.index.v1+json"
+	ociLayer     = "application/vnd.oci.image.layer.v1.tar"
+	ociLayerGzip = "application/vnd.oci.image.layer.v1.tar+gzip"
+	dockerLayer  = "application/vnd.docker.image.rootfs.diff.tar"
+)
+
+// PushOCILayout pushes the image in the OCI image layout directory dir into
+// a registry, as a Docker schema 2 image named by each of names, and returns
+// the first name, referring to the image by digest. The labels are added to
+// the image's config.
+func (c *liveClient) PushOCILayout(dir string, labels map[string]string, names ...string) (string, error) {
+	img, err := readOCILayout(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading OCI layout %s", dir)
+	}
+	defer img.remove()
+	return c.pushImage(img, labels, names)
+}
+
+// PushArchive pushes the image in path, a tarball written by `docker save`,
+// into a registry, as a Docker schema 2 image named by each of names, and
+// returns the first name, referring to the image by digest. The labels are
+// added to the image's config.
+func (c *liveClient) PushArchive(path string, labels map[string]string, names ...string) (string, error) {
+	img, err := readArchive(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading image archive %s", path)
+	}
+	defer img.remove()
+	return c.pushImage(img, labels, names)
+}
+
+func (c *liveClient) pushImage(img *localImage, labels map[string]string, names []string) (string, error) {
+	if len(names) == 0 {
+		return "", errors.New("no name to push the image as")
+	}
+	config, err := labelConfig(img.config, labels)
+	if err != nil {
+		return "", err
+	}
+	m := schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeConfig,
+			Size:      int64(len(config)),
+			Digest:    digest.FromBytes(config),
+		},
+	}
+	for _, l := range img.layers {
+		m.Layers = append(m.Layers, distribution.Descriptor{
+			MediaType: schema2.MediaTypeLayer,
+			Size:      l.size,
+			Digest:    l.digest,
+		})
+	}
+	mani, err := schema2.FromStruct(m)
+	if err != nil {
+		return "", err
+	}
+	mediaType, payload, err := mani.Payload()
+	if err != nil {
+		return "", err
+	}
+
+	var pushed string
+	for _, n := range names {
+		host, ref, err := splitHost(n)
+		if err != nil {
+			return "", err
+		}
+		reg, err := c.registryForHostname(host)
+		if err != nil {
+			return "", err
+		}
+		name, err := reference.ParseNamed(ref.Name())
+		if err != nil {
+			return "", err
+		}
+		err = pushBlob(reg, name, m.Config.Digest, int64(len(config)), func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(config)), nil
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "pushing config of %s", n)
+		}
+		for _, l := range img.layers {
+			path := l.path
+			err := pushBlob(reg, name, l.digest, l.size, func() (io.ReadCloser, error) {
+				return os.Open(path)
+			})
+			if err != nil {
+				return "", errors.Wrapf(err, "pushing layer %s of %s", l.digest, n)
+			}
+		}
+
+		canonical, err := reference.WithDigest(name, digest.FromBytes(payload))
+		if err != nil {
+			return "", err
+		}
+		target := reference.Named(canonical)
+		if tagged, ok := ref.(reference.Tagged); ok {
+			if target, err = reference.WithTag(name, tagged.Tag()); err != nil {
+				return "", err
+			}
+		}
+		if err := reg.putManifest(target, mediaType, payload); err != nil {
+			return "", errors.Wrapf(err, "putting manifest of %s", n)
+		}
+		if pushed == "" {
+			named, err := joinHost(host, canonical)
+			if err != nil {
+				return "", err
+			}
+			pushed = named.String()
+		}
+	}
+	return pushed, nil
+}
+
+// pushBlob uploads the blob with digest dg to the repository name in reg,
+// unless it's already there.
+func pushBlob(reg *registry, name reference.Named, dg digest.Digest, size int64, open func() (io.ReadCloser, error)) error {
+	ref, err := reference.WithDigest(name, dg)
+	if err != nil {
+		return err
+	}
+	exists, err := reg.blobExists(ref)
+	if err != nil || exists {
+		return err
+	}
+	body, err := open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return reg.uploadBlob(ref, body, size)
+}
+
+// labelConfig adds labels to the image config, preserving its other fields.
+func labelConfig(config []byte, labels map[string]string) ([]byte, error) {
+	if len(labels) == 0 {
+		return config, nil
+	}
+	cfg := map[string]interface{}{}
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, errors.Wrap(err, "parsing image config")
+	}
+	cc, _ := cfg["config"].(map[string]interface{})
+	if cc == nil {
+		cc = map[string]interface{}{}
+		cfg["config"] = cc
+	}
+	ls, _ := cc["Labels"].(map[string]interface{})
+	if ls == nil {
+		ls = map[string]interface{}{}
+		cc["Labels"] = ls
+	}
+	for k, v := range labels {
+		ls[k] = v
+	}
+	return json.Marshal(cfg)
+}
+
+func (img *localImage) remove() {
+	if img != nil && img.scratch != "" {
+		os.RemoveAll(img.scratch)
+	}
+}
+
+func readOCILayout(dir string) (_ *localImage, err error) {
+	img := &localImage{}
+	defer func() {
+		if err != nil {
+			img.remove()
+		}
+	}()
+	var index struct {
+		Manifests []ociDescriptor `json:"manifests"`
+	}
+	if err := readJSON(filepath.Join(dir, "index.json"), &index); err != nil {
+		return nil, err
+	}
+	if len(index.Manifests) != 1 {
+		return nil, errors.Errorf("index.json lists %d manifests; want exactly one", len(index.Manifests))
+	}
+	if index.Manifests[0].MediaType == ociIndex {
+		return nil, errors.New("image indexes aren't supported")
+	}
+	var mani struct {
+		Config ociDescriptor   `json:"config"`
+		Layers []ociDescriptor `json:"layers"`
+	}
+	if err := readJSON(ociBlobPath(dir, index.Manifests[0].Digest), &mani); err != nil {
+		return nil, err
+	}
+	if img.config, err = ioutil.ReadFile(ociBlobPath(dir, mani.Config.Digest)); err != nil {
+		return nil, err
+	}
+	for _, l := range mani.Layers {
+		path := ociBlobPath(dir, l.Digest)
+		switch l.MediaType {
+		default:
+			return nil, errors.Errorf("layer %s has unsupported media type %q", l.Digest, l.MediaType)
+		case ociLayerGzip, schema2.MediaTypeLayer:
+			img.layers = append(img.layers, localLayer{path: path, digest: l.Digest, size: l.Size})
+		case ociLayer, dockerLayer:
+			layer, err := img.gzipLayer(path)
+			if err != nil {
+				return nil, err
+			}
+			img.layers = append(img.layers, layer)
+		}
+	}
+	return img, nil
+}
+
+func ociBlobPath(dir string, dg digest.Digest) string {
+	return filepath.Join(dir, "blobs", string(dg.Algorithm()), dg.Hex())
+}
+
+func readArchive(path string) (_ *localImage, err error) {
+	img := &localImage{}
+	defer func() {
+		if err != nil {
+			img.remove()
+		}
+	}()
+	if err := img.makeScratch(); err != nil {
+		return nil, err
+	}
+	extracted := filepath.Join(img.scratch, "archive")
+	if err := extractTar(path, extracted); err != nil {
+		return nil, err
+	}
+	var manis []archiveManifest
+	if err := readJSON(filepath.Join(extracted, "manifest.json"), &manis); err != nil {
+		return nil, err
+	}
+	if len(manis) != 1 {
+		return nil, errors.Errorf("manifest.json lists %d images; want exactly one", len(manis))
+	}
+	if img.config, err = ioutil.ReadFile(filepath.Join(extracted, filepath.FromSlash(manis[0].Config))); err != nil {
+		return nil, err
+	}
+	for _, l := range manis[0].Layers {
+		layer, err := img.gzipLayer(filepath.Join(extracted, filepath.FromSlash(l)))
+		if err != nil {
+			return nil, err
+		}
+		img.layers = append(img.layers, layer)
+	}
+	return img, nil
+}
+
+// extractTar extracts the regular files in the tarball at path into dir.
+func extractTar(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return errors.Errorf("archive entry %q is outside the archive", hdr.Name)
+		}
+		dest := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (img *localImage) makeScratch() (err error) {
+	if img.scratch == "" {
+		img.scratch, err = ioutil.TempDir("", "sous-push")
+	}
+	return err
+}
+
+// gzipLayer returns the layer at path, compressing it into the scratch
+// directory unless it's already compressed.
+func (img *localImage) gzipLayer(path string) (localLayer, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return localLayer{}, err
+	}
+	defer in.Close()
+	br := bufio.NewReader(in)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		dg, err := digest.FromReader(br)
+		if err != nil {
+			return localLayer{}, err
+		}
+		info, err := in.Stat()
+		if err != nil {
+			return localLayer{}, err
+		}
+		return localLayer{path: path, digest: dg, size: info.Size()}, nil
+	}
+
+	if err := img.makeScratch(); err != nil {
+		return localLayer{}, err
+	}
+	out, err := ioutil.TempFile(img.scratch, "layer")
+	if err != nil {
+		return localLayer{}, err
+	}
+	defer out.Close()
+	digester := digest.Canonical.New()
+	counter := &countingWriter{w: io.MultiWriter(out, digester.Hash())}
+	zw := gzip.NewWriter(counter)
+	if _, err := io.Copy(zw, br); err != nil {
+		return localLayer{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return localLayer{}, err
+	}
+	return localLayer{path: out.Name(), digest: digester.Digest(), size: counter.n}, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func readJSON(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(b, v), "parsing %s", filepath.Base(path))
+}
@@ -0,0 +1,126 @@
+package docker_registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/util/fake_registry"
+)
+
+// testLayer returns an uncompressed layer holding a single file.
+func testLayer(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	content := []byte("hello\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "hello.txt", Mode: 0644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(content)
+	tw.Close()
+	return buf.Bytes()
+}
+
+func testConfig(layer []byte) []byte {
+	return []byte(`{"architecture":"amd64","os":"linux",` +
+		`"config":{"Labels":{"built.by":"test"}},` +
+		`"rootfs":{"type":"layers","diff_ids":["` + digest.FromBytes(layer).String() + `"]}}`)
+}
+
+// writeOCILayout writes an OCI image layout holding an image with an
+// uncompressed layer into dir.
+func writeOCILayout(t *testing.T, dir string) {
+	layer := testLayer(t)
+	config := testConfig(layer)
+	mani, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config":        ociDescriptor{MediaType: "application/vnd.oci.image.config.v1+json", Digest: digest.FromBytes(config), Size: int64(len(config))},
+		"layers":        []ociDescriptor{{MediaType: ociLayer, Digest: digest.FromBytes(layer), Size: int64(len(layer))}},
+	})
+	index, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests":     []ociDescriptor{{MediaType: "application/vnd.oci.image.manifest.v1+json", Digest: digest.FromBytes(mani), Size: int64(len(mani))}},
+	})
+	blobs := filepath.Join(dir, "blobs", "sha256")
+	if err := os.MkdirAll(blobs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range [][]byte{layer, config, mani} {
+		if err := ioutil.WriteFile(filepath.Join(blobs, digest.FromBytes(b).Hex()), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.json"), index, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeArchive writes a tarball like those written by `docker save` to path.
+func writeArchive(t *testing.T, path string) {
+	layer := testLayer(t)
+	config := testConfig(layer)
+	mani, _ := json.Marshal([]map[string]interface{}{{
+		"Config":   "config.json",
+		"RepoTags": []string{"app:latest"},
+		"Layers":   []string{"abc/layer.tar"},
+	}})
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, b := range map[string][]byte{"manifest.json": mani, "config.json": config, "abc/layer.tar": layer} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(b))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(b)
+	}
+	tw.Close()
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPushArtifacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sous-push-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	layout := filepath.Join(dir, "layout")
+	writeOCILayout(t, layout)
+	archive := filepath.Join(dir, "image.tar")
+	writeArchive(t, archive)
+
+	reg := fake_registry.NewServer()
+	defer reg.Close()
+	c := NewClient()
+	c.BecomeFoolishlyTrusting()
+	labels := map[string]string{"com.example.version": "1.0.0"}
+
+	pushes := map[string]func(string, map[string]string, ...string) (string, error){
+		layout:  c.PushOCILayout,
+		archive: c.PushArchive,
+	}
+	for path, push := range pushes {
+		assert := assert.New(t)
+		name := reg.Host() + "/example/" + filepath.Base(path)
+		pushed, err := push(path, labels, name+":1.0.0", name+":abcdef")
+		if !assert.NoError(err, path) {
+			continue
+		}
+		md, err := c.GetImageMetadata(name+":1.0.0", "")
+		if !assert.NoError(err, path) {
+			continue
+		}
+		assert.Equal(name+"@"+md.Digest, pushed)
+		assert.Equal(map[string]string{"built.by": "test", "com.example.version": "1.0.0"}, md.Labels)
+		assert.Equal([]string{"1.0.0", "abcdef"}, reg.Tags("example/"+filepath.Base(path)))
+	}
+
+	_, err = c.PushOCILayout(filepath.Join(dir, "missing"), labels, reg.Host()+"/example/missing:1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,318 @@
+// Package fake_registry is an in-process fake of the Docker registry v2 HTTP
+// API: the catalog, tags, manifests and blobs, including monolithic blob
+// uploads and manifest deletion. It keeps its state in memory and serves
+// TLS, so that the real docker_registry client can talk to it once it trusts
+// its certificate.
+package fake_registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+)
+
+type (
+	// Server is a fake Docker registry, listening on a local port.
+	Server struct {
+		*httptest.Server
+		repos   map[string]*repository
+		uploads int
+		sync.Mutex
+	}
+
+	repository struct {
+		manifests map[string]manifest // by digest
+		tags      map[string]string   // tag -> digest
+		blobs     map[string][]byte   // by digest
+	}
+
+	manifest struct {
+		mediaType string
+		payload   []byte
+	}
+)
+
+// NewServer starts a fake registry. Close it when you're done.
+func NewServer() *Server {
+	s := &Server{repos: map[string]*repository{}}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Host returns the host and port of the registry, as used in image names.
+func (s *Server) Host() string {
+	u, _ := url.Parse(s.URL)
+	return u.Host
+}
+
+// AddImage stores a schema 2 image with the given labels in repo, tagged
+// with tag, and returns the digest of its manifest.
+func (s *Server) AddImage(repo, tag string, labels map[string]string) (string, error) {
+	config, err := json.Marshal(struct {
+		Config struct{ Labels map[string]string } `json:"config"`
+	}{Config: struct{ Labels map[string]string }{labels}})
+	if err != nil {
+		return "", err
+	}
+	layer := []byte(fmt.Sprintf("layer of %s:%s", repo, tag))
+	m, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeConfig,
+			Size:      int64(len(config)),
+			Digest:    digest.FromBytes(config),
+		},
+		Layers: []distribution.Descriptor{{
+			MediaType: schema2.MediaTypeLayer,
+			Size:      int64(len(layer)),
+			Digest:    digest.FromBytes(layer),
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+	mediaType, payload, err := m.Payload()
+	if err != nil {
+		return "", err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	r := s.repo(repo)
+	r.blobs[digest.FromBytes(config).String()] = config
+	r.blobs[digest.FromBytes(layer).String()] = layer
+	dg := digest.FromBytes(payload).String()
+	r.manifests[dg] = manifest{mediaType: mediaType, payload: payload}
+	r.tags[tag] = dg
+	return dg, nil
+}
+
+// Tags returns the tags in repo, in order.
+func (s *Server) Tags(repo string) []string {
+	s.Lock()
+	defer s.Unlock()
+	tags := []string{}
+	if r, ok := s.repos[repo]; ok {
+		for t := range r.tags {
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// HasManifest returns true if repo holds the manifest with digest dg.
+func (s *Server) HasManifest(repo, dg string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if r, ok := s.repos[repo]; ok {
+		_, ok := r.manifests[dg]
+		return ok
+	}
+	return false
+}
+
+func (s *Server) repo(name string) *repository {
+	r, ok := s.repos[name]
+	if !ok {
+		r = &repository{
+			manifests: map[string]manifest{},
+			tags:      map[string]string{},
+			blobs:     map[string][]byte{},
+		}
+		s.repos[name] = r
+	}
+	return r
+}
+
+func (s *Server) serve(w http.ResponseWriter, req *http.Request) {
+	p := req.URL.Path
+	switch {
+	case p == "/v2/" || p == "/v2":
+		writeJSON(w, http.StatusOK, struct{}{})
+	case p == "/v2/_catalog":
+		s.catalog(w, req)
+	case strings.HasSuffix(p, "/tags/list"):
+		s.tags(w, req, strings.TrimSuffix(strings.TrimPrefix(p, "/v2/"), "/tags/list"))
+	case strings.Contains(p, "/manifests/"):
+		name, ref := split(p, "/manifests/")
+		s.manifest(w, req, name, ref)
+	case strings.Contains(p, "/blobs/uploads/"):
+		name, id := split(p, "/blobs/uploads/")
+		s.upload(w, req, name, id)
+	case strings.Contains(p, "/blobs/"):
+		name, dg := split(p, "/blobs/")
+		s.blob(w, req, name, dg)
+	default:
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", p)
+	}
+}
+
+func split(path, sep string) (name, rest string) {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/v2/"), sep, 2)
+	return parts[0], parts[1]
+}
+
+func (s *Server) catalog(w http.ResponseWriter, req *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	names := []string{}
+	for n := range s.repos {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, map[string][]string{"repositories": names})
+}
+
+func (s *Server) tags(w http.ResponseWriter, req *http.Request, name string) {
+	s.Lock()
+	_, ok := s.repos[name]
+	s.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", name)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "tags": s.Tags(name)})
+}
+
+func (s *Server) manifest(w http.ResponseWriter, req *http.Request, name, ref string) {
+	s.Lock()
+	defer s.Unlock()
+	switch req.Method {
+	case "PUT":
+		payload, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		r := s.repo(name)
+		dg := digest.FromBytes(payload).String()
+		r.manifests[dg] = manifest{mediaType: req.Header.Get("Content-Type"), payload: payload}
+		if !strings.Contains(ref, ":") {
+			r.tags[ref] = dg
+		}
+		w.Header().Set("Docker-Content-Digest", dg)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	r, ok := s.repos[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", name)
+		return
+	}
+	dg := ref
+	if t, ok := r.tags[ref]; ok {
+		dg = t
+	}
+	m, ok := r.manifests[dg]
+	if !ok {
+		writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", ref)
+		return
+	}
+
+	switch req.Method {
+	case "DELETE":
+		delete(r.manifests, dg)
+		for t, d := range r.tags {
+			if d == dg {
+				delete(r.tags, t)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case "GET", "HEAD":
+		etag := `"` + dg + `"`
+		w.Header().Set("Docker-Content-Digest", dg)
+		w.Header().Set("Etag", etag)
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(m.payload)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == "GET" {
+			w.Write(m.payload)
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", req.Method)
+	}
+}
+
+func (s *Server) blob(w http.ResponseWriter, req *http.Request, name, dg string) {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.repos[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", name)
+		return
+	}
+	b, ok := r.blobs[dg]
+	if !ok {
+		writeError(w, http.StatusNotFound, "BLOB_UNKNOWN", dg)
+		return
+	}
+	w.Header().Set("Docker-Content-Digest", dg)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", fmt.Sprint(len(b)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method == "GET" {
+		w.Write(b)
+	}
+}
+
+// upload starts an upload when POSTed to, and completes it, monolithically,
+// when the blob is PUT to the location returned.
+func (s *Server) upload(w http.ResponseWriter, req *http.Request, name, id string) {
+	switch req.Method {
+	case "POST":
+		s.Lock()
+		s.uploads++
+		id := s.uploads
+		s.Unlock()
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", name, id))
+		w.Header().Set("Docker-Upload-UUID", fmt.Sprint(id))
+		w.WriteHeader(http.StatusAccepted)
+	case "PUT":
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+			return
+		}
+		dg := req.URL.Query().Get("digest")
+		if digest.FromBytes(b).String() != dg {
+			writeError(w, http.StatusBadRequest, "DIGEST_INVALID", dg)
+			return
+		}
+		s.Lock()
+		s.repo(name).blobs[dg] = b
+		s.Unlock()
+		w.Header().Set("Docker-Content-Digest", dg)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", req.Method)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error in the form the registry API specifies.
+func writeError(w http.ResponseWriter, status int, code, detail string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]string{{"code": code, "message": detail}},
+	})
+}
@@ -0,0 +1,75 @@
+package fake_registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentable/sous/util/docker_registry"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	cl := docker_registry.NewClient()
+	cl.BecomeFoolishlyTrusting()
+
+	labels := map[string]string{"com.example.label": "value"}
+	dg, err := s.AddImage("example/app", "1.0.0", labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := s.Host() + "/example/app"
+
+	repos, err := cl.Repositories(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(repos, []string{"example/app"}) {
+		t.Errorf("got repositories %v", repos)
+	}
+	tags, err := cl.AllTags(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"1.0.0"}) {
+		t.Errorf("got tags %v", tags)
+	}
+
+	md, err := cl.GetImageMetadata(name+":1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Digest != dg {
+		t.Errorf("got digest %q; want %q", md.Digest, dg)
+	}
+	if !reflect.DeepEqual(md.Labels, labels) {
+		t.Errorf("got labels %v; want %v", md.Labels, labels)
+	}
+
+	to := NewServer()
+	defer to.Close()
+	copied, err := cl.CopyImage(name+":1.0.0", to.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := to.Host() + "/example/app@" + dg; copied != want {
+		t.Errorf("got copy %q; want %q", copied, want)
+	}
+	if !to.HasManifest("example/app", dg) {
+		t.Errorf("copy has no manifest %s", dg)
+	}
+	md, err = cl.GetImageMetadata(copied, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(md.Labels, labels) {
+		t.Errorf("got labels %v on copy; want %v", md.Labels, labels)
+	}
+
+	if err := cl.DeleteImage(name + "@" + dg); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasManifest("example/app", dg) {
+		t.Errorf("manifest %s still present after deleting", dg)
+	}
+}
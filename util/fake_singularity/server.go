@@ -0,0 +1,604 @@
+// Package fake_singularity is an in-process fake of the parts of the
+// Singularity HTTP API that Sous uses: requests, deploys, deploy history,
+// scaling, deletion, running on demand, and the histories and logs of tasks. It keeps its state in memory, and every deploy
+// succeeds at once unless told otherwise, so that the real Singularity client
+// and the Sous deployer can be exercised without a Mesos cluster.
+package fake_singularity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// Server is a fake Singularity, listening on a local port.
+	Server struct {
+		*httptest.Server
+		// DeployState is the result every deploy is given, e.g. "SUCCEEDED"
+		// (the default) or "FAILED".
+		DeployState string
+		requests    map[string]*request
+		calls       []string
+		sync.Mutex
+	}
+
+	// object is the shape of a JSON object, as sent by the Singularity client.
+	object map[string]interface{}
+
+	// A Task is a task of a request, as added by AddTask.
+	Task struct {
+		Host string
+		// Active tasks are listed as active; the others as finished.
+		Active bool
+		// State and Message are given by the task's only update, e.g.
+		// "TASK_FAILED" and "Exited with status 1".
+		State, Message string
+		Stdout, Stderr string
+		// RunID, Args, Env and RunMessage are those of the request to run
+		// the task, if it was run on demand.
+		RunID      string
+		Args       []string
+		Env        map[string]string
+		RunMessage string
+	}
+
+	request struct {
+		body    object
+		deploys []*deploy // newest first
+		tasks   []*task   // newest first
+		paused  bool
+	}
+
+	task struct {
+		Task
+		id, deployID string
+		started      int64
+	}
+
+	deploy struct {
+		body      object
+		timestamp int64
+		state     string
+	}
+)
+
+// NewServer starts a fake Singularity. Close it when you're done.
+func NewServer() *Server {
+	s := &Server{
+		DeployState: "SUCCEEDED",
+		requests:    map[string]*request{},
+	}
+	r := httprouter.New()
+	r.GET("/api/requests", s.getRequests)
+	r.POST("/api/requests", s.postRequest)
+	r.DELETE("/api/requests/request/:requestId", s.deleteRequest)
+	r.PUT("/api/requests/request/:requestId/scale", s.scaleRequest)
+	r.POST("/api/requests/request/:requestId/pause", s.pauseRequest(true))
+	r.POST("/api/requests/request/:requestId/unpause", s.pauseRequest(false))
+	r.POST("/api/deploys", s.postDeploy)
+	r.GET("/api/history/request/:requestId/deploys", s.getDeploys)
+	r.GET("/api/history/request/:requestId/deploy/:deployId", s.getDeploy)
+	r.GET("/api/history/request/:requestId/tasks", s.getTasks(false))
+	r.GET("/api/history/request/:requestId/tasks/active", s.getTasks(true))
+	r.GET("/api/history/task/:taskId", s.getTask)
+	r.GET("/api/sandbox/:taskId/read", s.readTaskFile)
+	r.POST("/api/requests/request/:requestId/run", s.runRequest)
+	r.GET("/api/history/request/:requestId/run/:runId", s.getRun)
+	s.Server = httptest.NewServer(s.record(r))
+	return s
+}
+
+// RequestIDs returns the IDs of the requests Singularity holds, in order.
+func (s *Server) RequestIDs() []string {
+	s.Lock()
+	defer s.Unlock()
+	ids := []string{}
+	for id := range s.requests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Instances returns the number of instances requested by the request with the
+// given ID, and whether there is such a request.
+func (s *Server) Instances(requestID string) (int, bool) {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.requests[requestID]
+	if !ok {
+		return 0, false
+	}
+	n, _ := r.body["instances"].(float64)
+	return int(n), true
+}
+
+// Image returns the Docker image deployed by the latest deploy of the
+// request with the given ID, or the empty string if there isn't one.
+func (s *Server) Image(requestID string) string {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.requests[requestID]
+	if !ok || len(r.deploys) == 0 {
+		return ""
+	}
+	ci, _ := r.deploys[0].body["containerInfo"].(map[string]interface{})
+	docker, _ := ci["docker"].(map[string]interface{})
+	image, _ := docker["image"].(string)
+	return image
+}
+
+// Paused returns true if the request with the given ID is paused.
+func (s *Server) Paused(requestID string) bool {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.requests[requestID]
+	return ok && r.paused
+}
+
+// Deploys returns the number of deploys made to the request with the given
+// ID.
+func (s *Server) Deploys(requestID string) int {
+	s.Lock()
+	defer s.Unlock()
+	if r, ok := s.requests[requestID]; ok {
+		return len(r.deploys)
+	}
+	return 0
+}
+
+// AddTask adds a task, started now, to the request with the given ID, and
+// returns the task's ID, which is made the way Singularity makes them.
+func (s *Server) AddTask(requestID string, t Task) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	req, ok := s.requests[requestID]
+	if !ok {
+		return "", fmt.Errorf("no request %q", requestID)
+	}
+	return req.addTask(requestID, t), nil
+}
+
+// RunTaskID returns the ID of the task started by the run runID of the
+// request requestID, or the empty string if there isn't one.
+func (s *Server) RunTaskID(requestID, runID string) string {
+	s.Lock()
+	defer s.Unlock()
+	if t := s.runTask(requestID, runID); t != nil {
+		return t.id
+	}
+	return ""
+}
+
+// UpdateTask calls update to change the task with the given ID, e.g. to
+// write more output, or to finish it.
+func (s *Server) UpdateTask(taskID string, update func(*Task)) error {
+	s.Lock()
+	defer s.Unlock()
+	t := s.task(taskID)
+	if t == nil {
+		return fmt.Errorf("no task %q", taskID)
+	}
+	update(&t.Task)
+	return nil
+}
+
+// Calls returns the method and path of every call made to the server, in
+// order.
+func (s *Server) Calls() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string{}, s.calls...)
+}
+
+func (s *Server) record(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		s.calls = append(s.calls, r.Method+" "+r.URL.Path)
+		s.Unlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getRequests(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	parents := []object{}
+	for _, id := range s.sortedIDs() {
+		parents = append(parents, s.requests[id].parent())
+	}
+	writeJSON(w, http.StatusOK, parents)
+}
+
+// postRequest creates a request, or updates it if it already exists, as
+// Singularity does.
+func (s *Server) postRequest(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	body := object{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, _ := body["id"].(string)
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "request has no id")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	req, ok := s.requests[id]
+	if !ok {
+		req = &request{}
+		s.requests[id] = req
+	}
+	req.body = body
+	writeJSON(w, http.StatusOK, req.parent())
+}
+
+func (s *Server) deleteRequest(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	id := p.ByName("requestId")
+	req, ok := s.requests[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no request %q", id))
+		return
+	}
+	delete(s.requests, id)
+	writeJSON(w, http.StatusOK, req.body)
+}
+
+func (s *Server) scaleRequest(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	body := object{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	id := p.ByName("requestId")
+	req, ok := s.requests[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no request %q", id))
+		return
+	}
+	req.body["instances"] = body["instances"]
+	writeJSON(w, http.StatusOK, req.parent())
+}
+
+// pauseRequest pauses, or unpauses, a request. Pausing kills its active tasks,
+// as Singularity does by default.
+func (s *Server) pauseRequest(pause bool) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		s.Lock()
+		defer s.Unlock()
+		id := p.ByName("requestId")
+		req, ok := s.requests[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no request %q", id))
+			return
+		}
+		if req.paused == pause {
+			writeError(w, http.StatusConflict, fmt.Sprintf("request %q is already in that state", id))
+			return
+		}
+		req.paused = pause
+		if pause {
+			for _, t := range req.tasks {
+				if t.Active {
+					t.Active = false
+					t.State = "TASK_KILLED"
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, req.parent())
+	}
+}
+
+func (s *Server) postDeploy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	body := struct{ Deploy object }{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if body.Deploy == nil {
+		writeError(w, http.StatusBadRequest, "no deploy given")
+		return
+	}
+	reqID, _ := body.Deploy["requestId"].(string)
+	depID, _ := body.Deploy["id"].(string)
+	s.Lock()
+	defer s.Unlock()
+	req, ok := s.requests[reqID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("no request %q", reqID))
+		return
+	}
+	if req.paused {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Request %s is paused. Unable to deploy (it must be manually unpaused first)", reqID))
+		return
+	}
+	if req.deploy(depID) != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("request %q already has a deploy %q", reqID, depID))
+		return
+	}
+	dep := &deploy{body: body.Deploy, timestamp: time.Now().UnixNano() / 1e6, state: s.DeployState}
+	req.deploys = append([]*deploy{dep}, req.deploys...)
+	writeJSON(w, http.StatusOK, req.parent())
+}
+
+func (s *Server) getDeploys(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	count, page := 100, 1
+	if c, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && c > 0 {
+		count = c
+	}
+	if pg, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && pg > 0 {
+		page = pg
+	}
+	s.Lock()
+	defer s.Unlock()
+	id := p.ByName("requestId")
+	histories := []object{}
+	if req, ok := s.requests[id]; ok {
+		for i := (page - 1) * count; i < page*count && i < len(req.deploys); i++ {
+			h := req.deploys[i].history(id)
+			delete(h, "deploy") // Singularity omits the deploy from lists
+			histories = append(histories, h)
+		}
+	}
+	writeJSON(w, http.StatusOK, histories)
+}
+
+func (s *Server) getDeploy(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	reqID, depID := p.ByName("requestId"), p.ByName("deployId")
+	req, ok := s.requests[reqID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no request %q", reqID))
+		return
+	}
+	dep := req.deploy(depID)
+	if dep == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no deploy %q of request %q", depID, reqID))
+		return
+	}
+	writeJSON(w, http.StatusOK, dep.history(reqID))
+}
+
+// getTasks lists the active or the finished tasks of a request.
+func (s *Server) getTasks(active bool) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		s.Lock()
+		defer s.Unlock()
+		histories := []object{}
+		if req, ok := s.requests[p.ByName("requestId")]; ok {
+			for _, t := range req.tasks {
+				if t.Active == active {
+					histories = append(histories, object{"taskId": t.taskID(), "updatedAt": t.started})
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, histories)
+	}
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	t := s.task(p.ByName("taskId"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no task %q", p.ByName("taskId")))
+		return
+	}
+	writeJSON(w, http.StatusOK, object{
+		"taskUpdates": []object{{
+			"taskId":        t.taskID(),
+			"taskState":     t.State,
+			"statusMessage": t.Message,
+			"timestamp":     t.started,
+		}},
+	})
+}
+
+func (s *Server) readTaskFile(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	t := s.task(p.ByName("taskId"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no task %q", p.ByName("taskId")))
+		return
+	}
+	q := r.URL.Query()
+	var data string
+	switch q.Get("path") {
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no file %q", q.Get("path")))
+		return
+	case "stdout":
+		data = t.Stdout
+	case "stderr":
+		data = t.Stderr
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 || offset > len(data) {
+		offset = len(data)
+	}
+	end := len(data)
+	if length, err := strconv.Atoi(q.Get("length")); err == nil && offset+length < end {
+		end = offset + length
+	}
+	writeJSON(w, http.StatusOK, object{"data": data[offset:end], "offset": offset, "nextOffset": end})
+}
+
+// runRequest starts a task at once, which stays in the state TASK_STARTING
+// until it's updated.
+func (s *Server) runRequest(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	body := struct {
+		RunID           string
+		Message         string
+		CommandLineArgs []string
+		EnvOverrides    map[string]string
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	id := p.ByName("requestId")
+	req, ok := s.requests[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no request %q", id))
+		return
+	}
+	if body.RunID != "" && s.runTask(id, body.RunID) != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("run %q already exists", body.RunID))
+		return
+	}
+	req.addTask(id, Task{
+		Host:       "run-host",
+		Active:     true,
+		State:      "TASK_STARTING",
+		RunID:      body.RunID,
+		Args:       body.CommandLineArgs,
+		Env:        body.EnvOverrides,
+		RunMessage: body.Message,
+	})
+	writeJSON(w, http.StatusOK, req.parent())
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	s.Lock()
+	defer s.Unlock()
+	t := s.runTask(p.ByName("requestId"), p.ByName("runId"))
+	if t == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no run %q", p.ByName("runId")))
+		return
+	}
+	writeJSON(w, http.StatusOK, object{"taskId": t.taskID(), "runId": t.RunID, "updatedAt": t.started})
+}
+
+func (s *Server) runTask(requestID, runID string) *task {
+	if req, ok := s.requests[requestID]; ok {
+		for _, t := range req.tasks {
+			if t.RunID == runID {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) task(id string) *task {
+	for _, req := range s.requests {
+		for _, t := range req.tasks {
+			if t.id == id {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) sortedIDs() []string {
+	ids := []string{}
+	for id := range s.requests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *request) deploy(id string) *deploy {
+	for _, d := range r.deploys {
+		if d.body["id"] == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// addTask adds a task, started now, to r, whose ID is requestID.
+func (r *request) addTask(requestID string, t Task) string {
+	tk := &task{Task: t, started: time.Now().UnixNano() / 1e6}
+	if len(r.deploys) != 0 {
+		tk.deployID, _ = r.deploys[0].body["id"].(string)
+	}
+	tk.id = fmt.Sprintf("%s-%s-%d-%d-%s-rack1", requestID, tk.deployID, tk.started, len(r.tasks)+1, t.Host)
+	r.tasks = append([]*task{tk}, r.tasks...)
+	return tk.id
+}
+
+// active returns the latest successful deploy of r.
+func (r *request) active() *deploy {
+	for _, d := range r.deploys {
+		if d.state == "SUCCEEDED" {
+			return d
+		}
+	}
+	return nil
+}
+
+// parent renders r as a SingularityRequestParent.
+func (r *request) parent() object {
+	id, _ := r.body["id"].(string)
+	state := object{"requestId": id}
+	parent := object{
+		"request":            r.body,
+		"state":              "ACTIVE",
+		"requestDeployState": state,
+	}
+	if r.paused {
+		parent["state"] = "PAUSED"
+	}
+	if a := r.active(); a != nil {
+		state["activeDeploy"] = a.marker(id)
+		parent["activeDeploy"] = a.body
+	}
+	return parent
+}
+
+// taskID renders t's ID as a SingularityTaskId.
+func (t *task) taskID() object {
+	return object{
+		"id":        t.id,
+		"deployId":  t.deployID,
+		"host":      t.Host,
+		"startedAt": t.started,
+	}
+}
+
+func (d *deploy) marker(requestID string) object {
+	return object{
+		"requestId": requestID,
+		"deployId":  d.body["id"],
+		"timestamp": d.timestamp,
+	}
+}
+
+// history renders d as a SingularityDeployHistory.
+func (d *deploy) history(requestID string) object {
+	return object{
+		"deploy":       d.body,
+		"deployMarker": d.marker(requestID),
+		"deployResult": object{"deployState": d.state, "timestamp": d.timestamp},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, object{"message": message})
+}
@@ -0,0 +1,136 @@
+package fake_singularity
+
+import (
+	"testing"
+
+	sing "github.com/opentable/go-singularity"
+	"github.com/opentable/go-singularity/dtos"
+	"github.com/opentable/swaggering"
+)
+
+type dtoMap map[string]interface{}
+
+// load fills in dto from fields, so that the client sends them.
+func load(t *testing.T, dto swaggering.Fielder, fields dtoMap) swaggering.Fielder {
+	d, err := swaggering.LoadMap(dto, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func newRequest(t *testing.T, id string, instances int32) *dtos.SingularityRequest {
+	return load(t, &dtos.SingularityRequest{}, dtoMap{"Id": id, "Instances": instances}).(*dtos.SingularityRequest)
+}
+
+func deployRequest(t *testing.T, requestID, deployID string) *dtos.SingularityDeployRequest {
+	dep := load(t, &dtos.SingularityDeploy{}, dtoMap{"Id": deployID, "RequestId": requestID})
+	return load(t, &dtos.SingularityDeployRequest{}, dtoMap{"Deploy": dep}).(*dtos.SingularityDeployRequest)
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := sing.NewClient(s.URL)
+
+	if _, err := client.PostRequest(newRequest(t, "app", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := s.Instances("app"); !ok || n != 2 {
+		t.Errorf("got %d instances (exists: %t); want 2", n, ok)
+	}
+
+	if _, err := client.Deploy(deployRequest(t, "app", "dep1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Deploy(deployRequest(t, "app", "dep1")); err == nil {
+		t.Errorf("deploying the same deploy ID twice returned nil error")
+	}
+
+	parents, err := client.GetRequests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 1 || parents[0].ActiveDeploy == nil || parents[0].ActiveDeploy.Id != "dep1" {
+		t.Fatalf("got requests %+v; want app with active deploy dep1", parents)
+	}
+
+	if _, err := client.Scale("app", load(t, &dtos.SingularityScaleRequest{}, dtoMap{"Instances": int32(5)}).(*dtos.SingularityScaleRequest)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.Instances("app"); n != 5 {
+		t.Errorf("got %d instances after scaling; want 5", n)
+	}
+
+	histories, err := client.GetDeploys("app", 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || histories[0].DeployMarker.DeployId != "dep1" {
+		t.Fatalf("got deploy history %+v; want dep1", histories)
+	}
+	history, err := client.GetDeploy("app", "dep1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.DeployResult.DeployState != dtos.SingularityDeployResultDeployStateSUCCEEDED {
+		t.Errorf("got deploy state %q; want SUCCEEDED", history.DeployResult.DeployState)
+	}
+
+	if _, err := client.DeleteRequest("app", &dtos.SingularityDeleteRequestRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := s.RequestIDs(); len(ids) != 0 {
+		t.Errorf("got requests %v after deleting; want none", ids)
+	}
+}
+
+func TestServer_FailedDeploy(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.DeployState = "FAILED"
+	client := sing.NewClient(s.URL)
+
+	if _, err := client.PostRequest(newRequest(t, "app", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Deploy(deployRequest(t, "app", "dep1")); err != nil {
+		t.Fatal(err)
+	}
+	parents, err := client.GetRequests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 1 || parents[0].ActiveDeploy != nil {
+		t.Errorf("a failed deploy should not become active: %+v", parents)
+	}
+	history, err := client.GetDeploy("app", "dep1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.DeployResult.DeployState != dtos.SingularityDeployResultDeployStateFAILED {
+		t.Errorf("got deploy state %q; want FAILED", history.DeployResult.DeployState)
+	}
+}
+
+func TestServer_PausedDeploy(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := sing.NewClient(s.URL)
+
+	if _, err := client.PostRequest(newRequest(t, "app", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Pause("app", &dtos.SingularityPauseRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Deploy(deployRequest(t, "app", "dep1")); err == nil {
+		t.Errorf("deploying to a paused request returned nil error")
+	}
+	if _, err := client.Unpause("app", &dtos.SingularityUnpauseRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Deploy(deployRequest(t, "app", "dep1")); err != nil {
+		t.Errorf("deploying once unpaused: %v", err)
+	}
+}
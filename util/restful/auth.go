@@ -0,0 +1,122 @@
+package restful
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Principal is the verified identity of the client making a request.
+	Principal struct {
+		// Name is the full name of the authenticated user.
+		Name string
+		// Email is the email address of the authenticated user.
+		Email string
+		// Admin principals are allowed to perform any action.
+		Admin bool
+	}
+
+	// An Authenticator verifies the credentials presented with a request and
+	// returns the Principal they belong to.
+	Authenticator interface {
+		Authenticate(*http.Request) (*Principal, error)
+	}
+
+	// MultiAuthenticator tries each of its Authenticators in turn, returning
+	// the first Principal any of them verifies.
+	MultiAuthenticator []Authenticator
+
+	// AuthMiddleware authenticates requests before they are handed to an
+	// Exchanger. If no Authenticator is configured, every request is allowed
+	// through with a nil Principal.
+	AuthMiddleware struct {
+		Authenticator
+		*sous.LogSet
+	}
+
+	// An AuthenticationError reports that a request could not be
+	// authenticated.
+	AuthenticationError struct {
+		Reason string
+	}
+
+	// unauthenticatedExchanger answers requests that failed authentication.
+	unauthenticatedExchanger struct {
+		err error
+		w   http.ResponseWriter
+	}
+)
+
+// Authenticate implements Authenticator on MultiAuthenticator.
+func (ma MultiAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	var err error
+	for _, a := range ma {
+		var p *Principal
+		p, err = a.Authenticate(req)
+		if err == nil {
+			return p, nil
+		}
+	}
+	if err == nil {
+		err = &AuthenticationError{Reason: "no authenticators configured"}
+	}
+	return nil, err
+}
+
+// Authenticate returns the Principal for req. It returns nil, nil if
+// authentication is not configured.
+func (am *AuthMiddleware) Authenticate(req *http.Request) (*Principal, error) {
+	if am == nil || am.Authenticator == nil {
+		return nil, nil
+	}
+	p, err := am.Authenticator.Authenticate(req)
+	if err != nil {
+		if am.LogSet != nil {
+			am.LogSet.Warn.Printf("Authentication failed for %s %s: %v", req.Method, req.URL, err)
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (e *AuthenticationError) Error() string {
+	return "authentication failed: " + e.Reason
+}
+
+// BearerToken extracts the bearer token from the Authorization header of req.
+func BearerToken(req *http.Request) (string, error) {
+	h := req.Header.Get("Authorization")
+	if h == "" {
+		return "", &AuthenticationError{Reason: "no Authorization header"}
+	}
+	parts := strings.SplitN(h, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", &AuthenticationError{Reason: "Authorization header is not a bearer token"}
+	}
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return "", &AuthenticationError{Reason: "empty bearer token"}
+	}
+	return token, nil
+}
+
+// Matches returns true if name is either the Name or the Email of this
+// Principal. Emails are compared case-insensitively.
+func (p *Principal) Matches(name string) bool {
+	if p == nil || name == "" {
+		return false
+	}
+	if p.Name != "" && p.Name == name {
+		return true
+	}
+	return p.Email != "" && strings.EqualFold(p.Email, name)
+}
+
+// Exchange implements Exchanger on unauthenticatedExchanger.
+func (ux *unauthenticatedExchanger) Exchange() (interface{}, int) {
+	ux.w.Header().Set("WWW-Authenticate", `Bearer realm="sous"`)
+	return errors.Cause(ux.err).Error(), http.StatusUnauthorized
+}
@@ -0,0 +1,149 @@
+package restful
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/psyringe"
+)
+
+func testUserStore() *FileUserStore {
+	return &FileUserStore{Users: []StoredUser{
+		{
+			Principal: Principal{Name: "Jane Doe", Email: "jdoe@example.com", Admin: true},
+			Tokens:    []string{"janes-token"},
+		},
+		{
+			Principal: Principal{Name: "Joe Bloggs", Email: "jbloggs@example.com"},
+		},
+	}}
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest("GET", "/test/one", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestStaticTokenAuthenticator(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	sa := &StaticTokenAuthenticator{Users: testUserStore()}
+
+	p, err := sa.Authenticate(bearerRequest("janes-token"))
+	require.NoError(err)
+	assert.Equal("jdoe@example.com", p.Email)
+	assert.True(p.Admin)
+
+	_, err = sa.Authenticate(bearerRequest("not-a-token"))
+	assert.Error(err)
+
+	_, err = sa.Authenticate(bearerRequest(""))
+	assert.Error(err)
+}
+
+func TestSignedTokenAuthenticator(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key := []byte("sekrit")
+	now := time.Unix(1000000, 0)
+	sa := &SignedTokenAuthenticator{Key: key, Users: testUserStore(), now: func() time.Time { return now }}
+
+	token, err := SignToken(key, "jbloggs@example.com", now.Add(time.Hour))
+	require.NoError(err)
+	p, err := sa.Authenticate(bearerRequest(token))
+	require.NoError(err)
+	assert.Equal("Joe Bloggs", p.Name)
+	assert.False(p.Admin)
+
+	expired, err := SignToken(key, "jbloggs@example.com", now.Add(-time.Second))
+	require.NoError(err)
+	_, err = sa.Authenticate(bearerRequest(expired))
+	assert.Error(err)
+
+	forged, err := SignToken([]byte("guess"), "jdoe@example.com", now.Add(time.Hour))
+	require.NoError(err)
+	_, err = sa.Authenticate(bearerRequest(forged))
+	assert.Error(err)
+
+	unknown, err := SignToken(key, "nobody@example.com", now.Add(time.Hour))
+	require.NoError(err)
+	_, err = sa.Authenticate(bearerRequest(unknown))
+	assert.Error(err)
+}
+
+func TestAuthenticatedRouter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var auth Authenticator = MultiAuthenticator{&StaticTokenAuthenticator{Users: testUserStore()}}
+	gf := func() Injector { return psyringe.New(sous.SilentLogSet, func() Authenticator { return auth }) }
+	router, err := testRouteMap().BuildRouter(gf)
+	require.NoError(err)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/test/one")
+	require.NoError(err)
+	res.Body.Close()
+	assert.Equal(http.StatusUnauthorized, res.StatusCode)
+	assert.NotEqual("", res.Header.Get("WWW-Authenticate"))
+
+	req, err := http.NewRequest("GET", server.URL+"/test/one", nil)
+	require.NoError(err)
+	req.Header.Set("Authorization", "Bearer janes-token")
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(err)
+	res.Body.Close()
+	assert.Equal(http.StatusOK, res.StatusCode)
+}
+
+func TestRouterRefusesBrokenAuthenticator(t *testing.T) {
+	gf := func() Injector {
+		return psyringe.New(sous.SilentLogSet, func() (Authenticator, error) {
+			return nil, errors.New("no user file")
+		})
+	}
+	_, err := testRouteMap().BuildRouter(gf)
+	assert.Error(t, err)
+}
+
+func TestLoadUserFile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f, err := ioutil.TempFile("", "sous-users")
+	require.NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`
+Users:
+- Name: Jane Doe
+  Email: jdoe@example.com
+  Admin: true
+  Tokens: [janes-token]
+`)
+	require.NoError(err)
+	require.NoError(f.Close())
+
+	users, err := LoadUserFile(f.Name())
+	require.NoError(err)
+	p, ok := users.UserByToken("janes-token")
+	require.True(ok)
+	assert.Equal(Principal{Name: "Jane Doe", Email: "jdoe@example.com", Admin: true}, *p)
+	_, ok = users.UserByToken("janes-toke")
+	assert.False(ok)
+	_, ok = users.UserByName("JDoe@example.com")
+	assert.True(ok)
+}
@@ -42,6 +42,13 @@ type (
 		Put() Exchanger
 	}
 
+	// UnconditionalPutable tags ResourceFamilies that respond to PUT without
+	// requiring If-Match or If-None-Match, because each PUT replaces whatever
+	// was there. It's used in place of Putable.
+	UnconditionalPutable interface {
+		PutUnconditionally() Exchanger
+	}
+
 	// Deleteable tags ResourceFamilies that respond to DELETE
 	Deleteable interface {
 		Delete() Exchanger
@@ -56,31 +63,37 @@ type (
 		// The former means "there is data format that reasonably represents
 		// a transform from the current GET into a reasonable PUT"
 		// The latter means "thanks, but we'll handle the PATCH"
-		// Likewise, maybe there should be a way for a RF to override the
-		// PUT conditional behavior
 	*/
 )
 
-func (rm *RouteMap) buildMetaHandler(r *httprouter.Router, grf func() Injector) *MetaHandler {
+func (rm *RouteMap) buildMetaHandler(r *httprouter.Router, grf func() Injector) (*MetaHandler, error) {
 	ph := &StatusMiddleware{}
 	mh := &MetaHandler{
 		graphFac:      grf,
 		router:        r,
 		statusHandler: ph,
+		authHandler:   &AuthMiddleware{},
 	}
 	mh.InstallPanicHandler()
+	if err := mh.InstallAuthMiddleware(); err != nil {
+		return nil, err
+	}
 
-	return mh
+	return mh, nil
 }
 
 // BuildRouter builds a returns an http.Handler based on some constant configuration
-func (rm *RouteMap) BuildRouter(grf func() Injector) http.Handler {
+func (rm *RouteMap) BuildRouter(grf func() Injector) (http.Handler, error) {
 	r := httprouter.New()
-	mh := rm.buildMetaHandler(r, grf)
+	mh, err := rm.buildMetaHandler(r, grf)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, e := range *rm {
 		get, canGet := e.Resource.(Getable)
 		put, canPut := e.Resource.(Putable)
+		uput, canUPut := e.Resource.(UnconditionalPutable)
 		del, canDel := e.Resource.(Deleteable)
 
 		if canGet {
@@ -89,25 +102,30 @@ func (rm *RouteMap) BuildRouter(grf func() Injector) http.Handler {
 		}
 		if canPut {
 			r.Handle("PUT", e.Path, mh.PutHandling(put.Put))
+		} else if canUPut {
+			r.Handle("PUT", e.Path, mh.UnconditionalPutHandling(uput.PutUnconditionally))
 		}
 		if canDel {
 			r.Handle("DELETE", e.Path, mh.DeleteHandling(del.Delete))
 		}
 	}
 
-	return r
+	return r, nil
 }
 
 // SingleExchanger returns a single exchanger for the given exchange factory
 // and injector factory. Can be useful in testing or trickier integrations.
-func (rm *RouteMap) SingleExchanger(factory ExchangeFactory, gf func() Injector) Exchanger {
+func (rm *RouteMap) SingleExchanger(factory ExchangeFactory, gf func() Injector) (Exchanger, error) {
 	r := httprouter.New()
 	w := httptest.NewRecorder()
 	rq := httptest.NewRequest("GET", "/", nil)
 
-	mh := rm.buildMetaHandler(r, gf)
+	mh, err := rm.buildMetaHandler(r, gf)
+	if err != nil {
+		return nil, err
+	}
 
-	return mh.injectedHandler(factory, w, rq, httprouter.Params{})
+	return mh.injectedHandler(factory, w, rq, httprouter.Params{}), nil
 }
 
 // KV (Key/Value) is a convenience type for PathFor
@@ -14,6 +14,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -22,6 +23,7 @@ type (
 		router        *httprouter.Router
 		graphFac      func() Injector //XXX This is a workaround for a bug in psyringe.Clone()
 		statusHandler *StatusMiddleware
+		authHandler   *AuthMiddleware
 	}
 
 	// ResponseWriter wraps the the http.ResponseWriter interface.
@@ -114,6 +116,16 @@ func (mh *MetaHandler) PutHandling(factory ExchangeFactory) httprouter.Handle {
 	}
 }
 
+// UnconditionalPutHandling handles PUT requests that need no If-Match or
+// If-None-Match.
+func (mh *MetaHandler) UnconditionalPutHandling(factory ExchangeFactory) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		h := mh.injectedHandler(factory, w, r, p)
+		data, status := h.Exchange()
+		mh.renderData(status, w, r, data)
+	}
+}
+
 // InstallPanicHandler installs an panic handler into the router.
 func (mh *MetaHandler) InstallPanicHandler() {
 	g := mh.graphFac()
@@ -124,6 +136,14 @@ func (mh *MetaHandler) InstallPanicHandler() {
 
 }
 
+// InstallAuthMiddleware injects the configured Authenticator (if any) into
+// the auth middleware. It returns an error if the Authenticator can't be
+// built, rather than leaving requests unauthenticated.
+func (mh *MetaHandler) InstallAuthMiddleware() error {
+	g := mh.graphFac()
+	return errors.Wrap(g.Inject(mh.authHandler), "configuring authentication")
+}
+
 func (mh *MetaHandler) ExchangeGraph(w http.ResponseWriter, r *http.Request, p httprouter.Params) Injector {
 	g := mh.graphFac()
 	g.Add(&ResponseWriter{ResponseWriter: w}, r, p)
@@ -135,9 +155,15 @@ func (mh *MetaHandler) injectedHandler(factory ExchangeFactory, w http.ResponseW
 	h := factory()
 
 	exGraph := mh.ExchangeGraph(w, r, p)
-	exGraph.MustInject(h)
+	principal, err := mh.authHandler.Authenticate(r)
+	exGraph.Add(principal)
 	logger := &ExchangeLogger{}
 	exGraph.MustInject(logger)
+	if err != nil {
+		logger.Exchanger = &unauthenticatedExchanger{err: err, w: w}
+		return logger
+	}
+	exGraph.MustInject(h)
 	logger.Exchanger = h
 
 	return logger
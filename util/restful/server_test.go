@@ -86,7 +86,9 @@ type PutConditionalsSuite struct {
 
 func (t *PutConditionalsSuite) SetupTest() {
 	dif := func() Injector { return psyringe.New(sous.SilentLogSet) }
-	t.server = httptest.NewServer(testRouteMap().BuildRouter(dif))
+	router, err := testRouteMap().BuildRouter(dif)
+	t.Require().NoError(err)
+	t.server = httptest.NewServer(router)
 
 	t.client = &http.Client{}
 }
@@ -0,0 +1,116 @@
+package restful
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// StaticTokenAuthenticator authenticates requests bearing one of the
+	// static tokens assigned to a user in its UserStore.
+	StaticTokenAuthenticator struct {
+		Users UserStore
+	}
+
+	// SignedTokenAuthenticator authenticates requests bearing a token signed
+	// with Key, which names a user in its UserStore. Removing the user from
+	// the store revokes all of their signed tokens.
+	SignedTokenAuthenticator struct {
+		Key   []byte
+		Users UserStore
+		// now is used to check expiry, defaulting to time.Now.
+		now func() time.Time
+	}
+
+	// tokenClaims is the signed payload of a signed token.
+	tokenClaims struct {
+		Name    string
+		Expires int64
+	}
+)
+
+// Authenticate implements Authenticator on StaticTokenAuthenticator.
+func (sa *StaticTokenAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token, err := BearerToken(req)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := sa.Users.UserByToken(token)
+	if !ok {
+		return nil, &AuthenticationError{Reason: "unknown token"}
+	}
+	return p, nil
+}
+
+// SignToken returns a token naming the user name, which expires at expires,
+// signed with key. It is suitable for presentation to a
+// SignedTokenAuthenticator configured with the same key.
+func SignToken(key []byte, name string, expires time.Time) (string, error) {
+	payload, err := json.Marshal(tokenClaims{Name: name, Expires: expires.Unix()})
+	if err != nil {
+		return "", errors.Wrap(err, "encoding token claims")
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(sign(key, payload)), nil
+}
+
+func sign(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Authenticate implements Authenticator on SignedTokenAuthenticator.
+func (sa *SignedTokenAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token, err := BearerToken(req)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := sa.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := sa.Users.UserByName(claims.Name)
+	if !ok {
+		return nil, &AuthenticationError{Reason: "unknown user " + claims.Name}
+	}
+	return p, nil
+}
+
+func (sa *SignedTokenAuthenticator) verify(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, &AuthenticationError{Reason: "malformed signed token"}
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, &AuthenticationError{Reason: "malformed signed token payload"}
+	}
+	sig, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, &AuthenticationError{Reason: "malformed signed token signature"}
+	}
+	if len(sa.Key) == 0 || !hmac.Equal(sig, sign(sa.Key, payload)) {
+		return nil, &AuthenticationError{Reason: "bad token signature"}
+	}
+	claims := &tokenClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, &AuthenticationError{Reason: "malformed signed token claims"}
+	}
+	now := time.Now
+	if sa.now != nil {
+		now = sa.now
+	}
+	if now().Unix() >= claims.Expires {
+		return nil, &AuthenticationError{Reason: "token expired"}
+	}
+	return claims, nil
+}
@@ -0,0 +1,77 @@
+package restful
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A UserStore looks up the Principals known to a server.
+	UserStore interface {
+		// UserByName returns the user with the given Name or Email.
+		UserByName(name string) (*Principal, bool)
+		// UserByToken returns the user to whom token has been issued.
+		UserByToken(token string) (*Principal, bool)
+	}
+
+	// FileUserStore is a UserStore loaded from a YAML file, like:
+	//
+	//     Users:
+	//     - Name: Jane Doe
+	//       Email: jdoe@example.com
+	//       Admin: true
+	//       Tokens: [some-long-random-string]
+	FileUserStore struct {
+		Users []StoredUser
+	}
+
+	// A StoredUser is a single entry in a FileUserStore.
+	StoredUser struct {
+		Principal `yaml:",inline"`
+		// Tokens are the static bearer tokens issued to this user.
+		Tokens []string `yaml:",omitempty"`
+	}
+)
+
+// LoadUserFile reads a FileUserStore from the YAML file at path.
+func LoadUserFile(path string) (*FileUserStore, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading user file %q", path)
+	}
+	fus := &FileUserStore{}
+	if err := yaml.Unmarshal(b, fus); err != nil {
+		return nil, errors.Wrapf(err, "parsing user file %q", path)
+	}
+	return fus, nil
+}
+
+// UserByName implements UserStore on FileUserStore.
+func (fus *FileUserStore) UserByName(name string) (*Principal, bool) {
+	for _, u := range fus.Users {
+		if u.Matches(name) {
+			p := u.Principal
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// UserByToken implements UserStore on FileUserStore.
+func (fus *FileUserStore) UserByToken(token string) (*Principal, bool) {
+	if token == "" {
+		return nil, false
+	}
+	for _, u := range fus.Users {
+		for _, t := range u.Tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				p := u.Principal
+				return &p, true
+			}
+		}
+	}
+	return nil, false
+}